@@ -1,6 +1,8 @@
 package cart
 
 import (
+	"fmt"
+	"strings"
 	"time"
 
 	"github.com/devchuckcamp/gocommerce/money"
@@ -9,9 +11,10 @@ import (
 // Cart represents a shopping cart.
 type Cart struct {
 	ID         string
-	UserID     string    // Empty for guest carts
-	SessionID  string    // For guest carts
+	UserID     string // Empty for guest carts
+	SessionID  string // For guest carts
 	Items      []CartItem
+	SavedItems []CartItem // Saved for later; not ordered or counted in totals
 	CreatedAt  time.Time
 	UpdatedAt  time.Time
 	ExpiresAt  *time.Time
@@ -19,22 +22,66 @@ type Cart struct {
 
 // CartItem represents an item in the cart.
 type CartItem struct {
-	ID         string
-	ProductID  string
-	VariantID  *string // Optional variant
-	SKU        string
-	Name       string
-	Price      money.Money // Price at time of adding
-	Quantity   int
-	Attributes map[string]string // Selected options
-	AddedAt    time.Time
+	ID             string
+	ProductID      string
+	VariantID      *string // Optional variant
+	SKU            string
+	Name           string
+	Price          money.Money // Price at time of adding
+	Quantity       int
+	Attributes     map[string]string // Selected options
+	TaxCode        string            // Product tax code at time of adding
+	CategoryID     string            // Product category at time of adding
+	WeightGrams    int               // Unit shipping weight (variant's if selected)
+	LengthCm       int
+	WidthCm        int
+	HeightCm       int
+	AllowBackorder bool // Product accepted backorders at time of adding
+	AddedAt        time.Time
+}
+
+// MergePricePolicy chooses the price of a line found in both carts at
+// different prices.
+type MergePricePolicy string
+
+const (
+	MergePriceNewer MergePricePolicy = "newer" // Price of the most recently added line (the default)
+	MergePriceLower MergePricePolicy = "lower" // The lower of the two prices
+)
+
+// MergePolicy controls how Merge resolves conflicts between two carts.
+type MergePolicy struct {
+	Price MergePricePolicy
+	// RejectCurrencyMismatch makes Merge fail with ErrCurrencyMismatch,
+	// leaving the cart untouched, instead of skipping lines priced in
+	// another currency.
+	RejectCurrencyMismatch bool
+}
+
+// MergeAction describes what Merge did with a line from the other cart.
+type MergeAction string
+
+const (
+	MergeActionAdded         MergeAction = "added"          // Line wasn't in the cart
+	MergeActionCombined      MergeAction = "combined"       // Quantity added to a line at the same price
+	MergeActionPriceConflict MergeAction = "price_conflict" // Quantity added to a line at another price; Price chosen by policy
+	MergeActionSkipped       MergeAction = "skipped"        // Priced in another currency
+)
+
+// MergeDecision records how one line of the other cart was merged.
+type MergeDecision struct {
+	ItemID        string // Line in the merged cart; the other cart's line if skipped
+	SKU           string
+	Action        MergeAction
+	Quantity      int         // Units from the other cart
+	Price         money.Money // Resulting unit price; the other cart's price if skipped
+	PreviousPrice money.Money // Cart's unit price before a price conflict
 }
 
 // AddItem adds an item to the cart or increases quantity if it already exists.
 func (c *Cart) AddItem(item CartItem) {
 	for i, existing := range c.Items {
-		if existing.ProductID == item.ProductID && 
-		   existing.VariantID == item.VariantID {
+		if sameLine(existing, item) {
 			c.Items[i].Quantity += item.Quantity
 			c.UpdatedAt = time.Now()
 			return
@@ -72,7 +119,44 @@ func (c *Cart) UpdateItemQuantity(itemID string, quantity int) bool {
 	return false
 }
 
-// Clear removes all items from the cart.
+// SaveForLater moves an item from the cart to SavedItems. It returns false
+// if the cart has no item with that ID.
+func (c *Cart) SaveForLater(itemID string) bool {
+	item := c.FindItem(itemID)
+	if item == nil {
+		return false
+	}
+	saved := *item
+	c.RemoveItem(itemID)
+	c.SavedItems = append(c.SavedItems, saved)
+	return true
+}
+
+// MoveToCart moves a saved item back into the cart, merging it into an
+// existing line for the same product and variant. It returns false if no
+// saved item has that ID.
+func (c *Cart) MoveToCart(itemID string) bool {
+	for i, item := range c.SavedItems {
+		if item.ID == itemID {
+			c.SavedItems = append(c.SavedItems[:i], c.SavedItems[i+1:]...)
+			c.AddItem(item)
+			return true
+		}
+	}
+	return false
+}
+
+// FindSavedItem finds a saved-for-later item by ID.
+func (c *Cart) FindSavedItem(itemID string) *CartItem {
+	for i := range c.SavedItems {
+		if c.SavedItems[i].ID == itemID {
+			return &c.SavedItems[i]
+		}
+	}
+	return nil
+}
+
+// Clear removes all items from the cart. Saved items are kept.
 func (c *Cart) Clear() {
 	c.Items = []CartItem{}
 	c.UpdatedAt = time.Now()
@@ -92,21 +176,71 @@ func (c *Cart) ItemCount() int {
 	return count
 }
 
+// TotalWeightGrams returns the shipping weight of the cart: each item's unit
+// weight times its quantity. Items without a weight count as weightless.
+// Saved items are not included.
+func (c *Cart) TotalWeightGrams() int {
+	total := 0
+	for _, item := range c.Items {
+		total += item.WeightGrams * item.Quantity
+	}
+	return total
+}
+
+// TotalVolumeCm3 returns the combined volume of the cart's items in cubic
+// centimeters, for rough packaging estimates. Items missing any dimension
+// count as zero.
+func (c *Cart) TotalVolumeCm3() int {
+	total := 0
+	for _, item := range c.Items {
+		total += item.LengthCm * item.WidthCm * item.HeightCm * item.Quantity
+	}
+	return total
+}
+
 // Subtotal calculates the subtotal (before discounts/tax).
+// Items priced in a currency other than the first item's are a data error;
+// the subtotal is reported as zero in the first item's currency in that case.
 func (c *Cart) Subtotal() money.Money {
+	total, err := c.sumLines()
+	if err != nil {
+		return money.Zero(c.Items[0].Price.Currency)
+	}
+	return total
+}
+
+// sumLines adds up the line totals, failing if items are priced in more than
+// one currency. An empty cart sums to zero USD.
+func (c *Cart) sumLines() (money.Money, error) {
 	if len(c.Items) == 0 {
-		return money.Zero("USD")
+		return money.Zero("USD"), nil
 	}
 	
-	currency := c.Items[0].Price.Currency
-	total := money.Zero(currency)
-	
-	for _, item := range c.Items {
-		itemTotal := item.Price.MultiplyInt(item.Quantity)
-		total, _ = total.Add(itemTotal)
+	lines := make([]money.Money, len(c.Items))
+	for i, item := range c.Items {
+		lines[i] = item.Price.MultiplyInt(item.Quantity)
 	}
-	
-	return total
+	return money.Sum(lines...)
+}
+
+// Validate checks that every item has a SKU, a positive quantity and a
+// non-negative price, and that all items share one currency. Errors wrap
+// ErrInvalidCart.
+func (c *Cart) Validate() error {
+	for i, item := range c.Items {
+		switch {
+		case item.SKU == "":
+			return fmt.Errorf("%w: item %d has no SKU", ErrInvalidCart, i)
+		case item.Quantity <= 0:
+			return fmt.Errorf("%w: item %s quantity is %d", ErrInvalidCart, item.SKU, item.Quantity)
+		case item.Price.IsNegative():
+			return fmt.Errorf("%w: item %s price is %s", ErrInvalidCart, item.SKU, item.Price)
+		case !strings.EqualFold(item.Price.Currency, c.Items[0].Price.Currency):
+			return fmt.Errorf("%w: item %s is priced in %s, cart in %s", ErrInvalidCart,
+				item.SKU, item.Price.Currency, c.Items[0].Price.Currency)
+		}
+	}
+	return nil
 }
 
 // FindItem finds a cart item by ID.
@@ -119,21 +253,99 @@ func (c *Cart) FindItem(itemID string) *CartItem {
 	return nil
 }
 
-// Merge merges another cart into this one (useful for guest->user cart migration).
-func (c *Cart) Merge(other *Cart) {
-	for _, otherItem := range other.Items {
-		found := false
-		for i, existing := range c.Items {
-			if existing.ProductID == otherItem.ProductID && 
-			   existing.VariantID == otherItem.VariantID {
-				c.Items[i].Quantity += otherItem.Quantity
-				found = true
-				break
+// Merge merges another cart into this one (useful for guest->user cart
+// migration) and reports what it did with each of the other cart's lines.
+// Lines for the same product and variant are combined; if their prices
+// differ, policy.Price picks the price. Lines priced in a currency other
+// than the cart's are skipped, or rejected per policy.
+func (c *Cart) Merge(other *Cart, policy MergePolicy) ([]MergeDecision, error) {
+	var currency string
+	if len(c.Items) > 0 {
+		currency = c.Items[0].Price.Currency
+	} else if len(other.Items) > 0 {
+		currency = other.Items[0].Price.Currency
+	}
+
+	if policy.RejectCurrencyMismatch {
+		for _, otherItem := range other.Items {
+			if !strings.EqualFold(otherItem.Price.Currency, currency) {
+				return nil, fmt.Errorf("%w: item %s is priced in %s, cart in %s", ErrCurrencyMismatch,
+					otherItem.SKU, otherItem.Price.Currency, currency)
 			}
 		}
-		if !found {
+	}
+
+	decisions := make([]MergeDecision, 0, len(other.Items))
+	for _, otherItem := range other.Items {
+		decision := MergeDecision{
+			ItemID:   otherItem.ID,
+			SKU:      otherItem.SKU,
+			Quantity: otherItem.Quantity,
+			Price:    otherItem.Price,
+		}
+
+		existing := c.findLine(otherItem)
+		switch {
+		case !strings.EqualFold(otherItem.Price.Currency, currency):
+			decision.Action = MergeActionSkipped
+		case existing == nil:
 			c.Items = append(c.Items, otherItem)
+			decision.Action = MergeActionAdded
+		default:
+			existing.Quantity += otherItem.Quantity
+			decision.ItemID = existing.ID
+			decision.Action = MergeActionCombined
+			if !existing.Price.Equals(otherItem.Price) {
+				decision.Action = MergeActionPriceConflict
+				decision.PreviousPrice = existing.Price
+				existing.Price = policy.price(*existing, otherItem)
+			}
+			decision.Price = existing.Price
+		}
+		decisions = append(decisions, decision)
+	}
+	for _, otherItem := range other.SavedItems {
+		if c.FindSavedItem(otherItem.ID) == nil {
+			c.SavedItems = append(c.SavedItems, otherItem)
 		}
 	}
 	c.UpdatedAt = time.Now()
+	return decisions, nil
+}
+
+// findLine returns the cart line for item's product and variant, if any.
+func (c *Cart) findLine(item CartItem) *CartItem {
+	for i := range c.Items {
+		if sameLine(c.Items[i], item) {
+			return &c.Items[i]
+		}
+	}
+	return nil
+}
+
+// sameLine reports whether two items are for the same product and variant.
+func sameLine(a, b CartItem) bool {
+	if a.ProductID != b.ProductID {
+		return false
+	}
+	if a.VariantID == nil || b.VariantID == nil {
+		return a.VariantID == nil && b.VariantID == nil
+	}
+	return *a.VariantID == *b.VariantID
+}
+
+// price picks the unit price for a line found in both carts. Both prices
+// are in the same currency.
+func (p MergePolicy) price(existing, incoming CartItem) money.Money {
+	switch p.Price {
+	case MergePriceLower:
+		if incoming.Price.Amount < existing.Price.Amount {
+			return incoming.Price
+		}
+	default:
+		if incoming.AddedAt.After(existing.AddedAt) {
+			return incoming.Price
+		}
+	}
+	return existing.Price
 }
@@ -0,0 +1,129 @@
+package cart
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/devchuckcamp/gocommerce/money"
+)
+
+func TestCartSubtotal(t *testing.T) {
+	c := &Cart{Items: []CartItem{
+		{ID: "a", Price: usd(1999), Quantity: 3},
+		{ID: "b", Price: usd(250), Quantity: 1},
+	}}
+	if got := c.Subtotal(); got != usd(6247) {
+		t.Errorf("Subtotal = %s, want USD 62.47", got)
+	}
+	if got := (&Cart{}).Subtotal(); !got.IsZero() {
+		t.Errorf("empty Subtotal = %s, want zero", got)
+	}
+}
+
+func TestCartValidate(t *testing.T) {
+	valid := &Cart{Items: []CartItem{
+		{SKU: "A", Price: usd(1000), Quantity: 1},
+		{SKU: "B", Price: money.Money{Amount: 0, Currency: "usd"}, Quantity: 2},
+	}}
+	if err := valid.Validate(); err != nil {
+		t.Errorf("valid cart: %v", err)
+	}
+	if err := (&Cart{}).Validate(); err != nil {
+		t.Errorf("empty cart: %v", err)
+	}
+
+	tests := map[string]CartItem{
+		"no SKU":         {Price: usd(1000), Quantity: 1},
+		"zero quantity":  {SKU: "C", Price: usd(1000)},
+		"negative price": {SKU: "C", Price: usd(-1), Quantity: 1},
+		"other currency": {SKU: "C", Price: money.Money{Amount: 1000, Currency: "EUR"}, Quantity: 1},
+	}
+	for name, item := range tests {
+		c := &Cart{Items: []CartItem{{SKU: "A", Price: usd(1000), Quantity: 1}, item}}
+		if err := c.Validate(); !errors.Is(err, ErrInvalidCart) {
+			t.Errorf("%s: error = %v, want %v", name, err, ErrInvalidCart)
+		}
+	}
+}
+
+func TestCartMergePolicies(t *testing.T) {
+	added := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	carts := func() (*Cart, *Cart) {
+		target := &Cart{Items: []CartItem{
+			{ID: "t-a", ProductID: "A", SKU: "A", Price: usd(1000), Quantity: 1, AddedAt: added},
+			{ID: "t-b", ProductID: "B", SKU: "B", Price: usd(500), Quantity: 1, AddedAt: added},
+		}}
+		source := &Cart{Items: []CartItem{
+			{ID: "s-a", ProductID: "A", SKU: "A", Price: usd(1100), Quantity: 2, AddedAt: added.Add(time.Hour)},
+			{ID: "s-b", ProductID: "B", SKU: "B", Price: usd(500), Quantity: 1, AddedAt: added.Add(time.Hour)},
+			{ID: "s-c", ProductID: "C", SKU: "C", Price: usd(300), Quantity: 1},
+			{ID: "s-d", ProductID: "D", SKU: "D", Price: money.Money{Amount: 800, Currency: "EUR"}, Quantity: 1},
+		}}
+		return target, source
+	}
+
+	tests := []struct {
+		name      string
+		policy    MergePricePolicy
+		wantPrice money.Money
+	}{
+		{"newer", MergePriceNewer, usd(1100)},
+		{"default is newer", "", usd(1100)},
+		{"lower", MergePriceLower, usd(1000)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			target, source := carts()
+			decisions, err := target.Merge(source, MergePolicy{Price: tt.policy})
+			if err != nil {
+				t.Fatal(err)
+			}
+			want := []MergeDecision{
+				{ItemID: "t-a", SKU: "A", Action: MergeActionPriceConflict, Quantity: 2, Price: tt.wantPrice, PreviousPrice: usd(1000)},
+				{ItemID: "t-b", SKU: "B", Action: MergeActionCombined, Quantity: 1, Price: usd(500)},
+				{ItemID: "s-c", SKU: "C", Action: MergeActionAdded, Quantity: 1, Price: usd(300)},
+				{ItemID: "s-d", SKU: "D", Action: MergeActionSkipped, Quantity: 1, Price: money.Money{Amount: 800, Currency: "EUR"}},
+			}
+			if len(decisions) != len(want) {
+				t.Fatalf("decisions = %+v, want %+v", decisions, want)
+			}
+			for i := range want {
+				if decisions[i] != want[i] {
+					t.Errorf("decision %d = %+v, want %+v", i, decisions[i], want[i])
+				}
+			}
+			if len(target.Items) != 3 || target.Items[0].Quantity != 3 || target.Items[0].Price != tt.wantPrice {
+				t.Errorf("merged items = %+v", target.Items)
+			}
+		})
+	}
+
+	target, source := carts()
+	if _, err := target.Merge(source, MergePolicy{RejectCurrencyMismatch: true}); !errors.Is(err, ErrCurrencyMismatch) {
+		t.Errorf("reject mismatch: error = %v, want %v", err, ErrCurrencyMismatch)
+	}
+	if len(target.Items) != 2 || target.Items[0].Quantity != 1 {
+		t.Errorf("rejected merge changed the cart: %+v", target.Items)
+	}
+}
+
+func TestCartWeightAndVolume(t *testing.T) {
+	c := &Cart{
+		Items: []CartItem{
+			{ID: "a", Quantity: 2, WeightGrams: 350, LengthCm: 40, WidthCm: 30, HeightCm: 3},
+			{ID: "b", Quantity: 3, WeightGrams: 100},
+			{ID: "c", Quantity: 1, LengthCm: 10, WidthCm: 10, HeightCm: 10},
+		},
+		SavedItems: []CartItem{{ID: "s", Quantity: 1, WeightGrams: 5000, LengthCm: 50, WidthCm: 50, HeightCm: 50}},
+	}
+	if got := c.TotalWeightGrams(); got != 1000 {
+		t.Errorf("TotalWeightGrams = %d, want 1000", got)
+	}
+	if got := c.TotalVolumeCm3(); got != 8200 {
+		t.Errorf("TotalVolumeCm3 = %d, want 8200", got)
+	}
+	if (&Cart{}).TotalWeightGrams() != 0 || (&Cart{}).TotalVolumeCm3() != 0 {
+		t.Error("empty cart has weight or volume")
+	}
+}
@@ -3,6 +3,8 @@ package cart
 import (
 	"context"
 	"errors"
+	"fmt"
+	"strings"
 	"time"
 
 	"github.com/devchuckcamp/gocommerce/catalog"
@@ -15,6 +17,16 @@ var (
 	ErrItemNotFound     = errors.New("item not found")
 	ErrInvalidQuantity  = errors.New("invalid quantity")
 	ErrOutOfStock       = errors.New("product out of stock")
+	ErrCartAlreadyOwned = errors.New("cart belongs to another user")
+	// ErrDuplicateCart is returned by Repository.Save when a different cart
+	// already exists for the same user.
+	ErrDuplicateCart         = errors.New("cart already exists for user")
+	ErrEmptyCart             = errors.New("cart is empty")
+	ErrInvalidTaxRate        = errors.New("tax rate cannot be negative")
+	ErrInvalidCart           = errors.New("invalid cart")
+	ErrQuantityLimitExceeded = errors.New("quantity limit exceeded")
+	ErrCurrencyMismatch      = errors.New("cart currency mismatch")
+	ErrCartLimitExceeded     = errors.New("cart limit exceeded")
 )
 
 // Repository defines methods for cart persistence.
@@ -24,19 +36,64 @@ type Repository interface {
 	FindBySessionID(ctx context.Context, sessionID string) (*Cart, error)
 	Save(ctx context.Context, cart *Cart) error
 	Delete(ctx context.Context, id string) error
+	// FindExpired returns the IDs of up to limit carts whose ExpiresAt is
+	// before before, soonest expiry first. Carts without an expiry never
+	// expire.
+	FindExpired(ctx context.Context, before time.Time, limit int) ([]string, error)
 }
 
 // Service provides cart business logic.
 type Service interface {
 	GetCart(ctx context.Context, cartID string) (*Cart, error)
+	GetCartWithStock(ctx context.Context, cartID string) (*CartWithStock, error)
 	GetOrCreateCart(ctx context.Context, userID, sessionID string) (*Cart, error)
 	AddItem(ctx context.Context, cartID string, req AddItemRequest) (*Cart, error)
+	// AddItems adds every request or, if any fails validation, none.
+	AddItems(ctx context.Context, cartID string, reqs []AddItemRequest) (*Cart, error)
 	UpdateItemQuantity(ctx context.Context, cartID, itemID string, quantity int) (*Cart, error)
 	RemoveItem(ctx context.Context, cartID, itemID string) (*Cart, error)
+	// SaveForLater moves an item out of the cart into its saved items.
+	SaveForLater(ctx context.Context, cartID, itemID string) (*Cart, error)
+	// MoveToCart moves a saved item back into the cart.
+	MoveToCart(ctx context.Context, cartID, itemID string) (*Cart, error)
 	Clear(ctx context.Context, cartID string) (*Cart, error)
-	MergeCarts(ctx context.Context, sourceCartID, targetCartID string) (*Cart, error)
+	MergeCarts(ctx context.Context, sourceCartID, targetCartID string) (*Cart, []MergeDecision, error)
+	TransferCart(ctx context.Context, cartID, newUserID, newSessionID string) (*Cart, error)
+	ValidateForCheckout(ctx context.Context, cartID string) (*Cart, error)
+	// ValidateStock reports every cart item whose stock or price has changed
+	// since it was added, without modifying the cart.
+	ValidateStock(ctx context.Context, cartID string) ([]StockIssue, error)
+	// RefreshPrices updates items to their current catalog prices and
+	// reports the lines that changed.
+	RefreshPrices(ctx context.Context, cartID string) (*Cart, []PriceChange, error)
+	// PriceChanges reports the lines RefreshPrices would change, without
+	// updating the cart (e.g., so the UI can ask the customer first).
+	PriceChanges(ctx context.Context, cartID string) ([]PriceChange, error)
+	EstimateTotals(ctx context.Context, cartID string, flatTaxRate float64) (*TotalsEstimate, error)
+	// PurgeExpired deletes every cart past its expiry (e.g., from a cron job).
+	PurgeExpired(ctx context.Context) (deleted int, err error)
 }
 
+// CartHooks lets consumers inject custom rules (e.g., region restrictions,
+// B2B approval) into CartService without forking it. A Before hook vetoes
+// the operation by returning an error, which CartService returns unchanged.
+// Embed NoopHooks to implement only the hooks you need.
+type CartHooks interface {
+	// BeforeAddItem runs before item is added to cart.
+	BeforeAddItem(ctx context.Context, cart *Cart, item CartItem) error
+	// AfterAddItem runs after item has been added and the cart saved.
+	AfterAddItem(ctx context.Context, cart *Cart, item CartItem)
+	// BeforeCheckout runs when the cart is validated for checkout.
+	BeforeCheckout(ctx context.Context, cart *Cart) error
+}
+
+// NoopHooks implements CartHooks with hooks that do nothing.
+type NoopHooks struct{}
+
+func (NoopHooks) BeforeAddItem(ctx context.Context, cart *Cart, item CartItem) error { return nil }
+func (NoopHooks) AfterAddItem(ctx context.Context, cart *Cart, item CartItem)        {}
+func (NoopHooks) BeforeCheckout(ctx context.Context, cart *Cart) error               { return nil }
+
 // AddItemRequest contains data needed to add an item to cart.
 type AddItemRequest struct {
 	ProductID  string
@@ -45,6 +102,80 @@ type AddItemRequest struct {
 	Attributes map[string]string
 }
 
+// CartWithStock is a cart together with the current stock of each item.
+type CartWithStock struct {
+	Cart  *Cart
+	Items []ItemStock // In the same order as Cart.Items
+}
+
+// ItemStock annotates a cart item with current inventory.
+type ItemStock struct {
+	ItemID            string
+	SKU               string
+	StockKnown        bool // False when no inventory service is configured or the lookup failed
+	AvailableQuantity int
+	OutOfStock        bool // No units available
+	InsufficientStock bool // Fewer units available than the item's quantity
+}
+
+// StockIssueType classifies a problem found by ValidateStock.
+type StockIssueType string
+
+const (
+	StockIssueOutOfStock        StockIssueType = "out_of_stock"       // No units available
+	StockIssueInsufficientStock StockIssueType = "insufficient_stock" // Fewer units available than requested
+	StockIssuePriceChanged      StockIssueType = "price_changed"      // Current price differs from the cart price
+	StockIssueUnavailable       StockIssueType = "unavailable"        // Product or variant no longer sold
+)
+
+// StockIssue describes one problem with a cart item. An item can have more
+// than one issue (e.g., both short of stock and repriced).
+type StockIssue struct {
+	ItemID    string
+	SKU       string
+	Type      StockIssueType
+	Requested int         // Units of the SKU requested across the cart
+	Available int         // Units of the SKU in stock
+	CartPrice money.Money // Unit price in the cart
+	NewPrice  money.Money // Current unit price, for StockIssuePriceChanged
+}
+
+// PriceChange reports a cart item whose unit price differs from the catalog.
+type PriceChange struct {
+	ItemID     string
+	SKU        string
+	OldPrice   money.Money // Unit price in the cart
+	NewPrice   money.Money // Current unit price
+	Difference money.Money // NewPrice - OldPrice; zero if the currency changed
+}
+
+// TotalsEstimate is a rough cart total using a flat tax rate and no shipping,
+// discounts, or tax jurisdictions. Use the pricing service for real totals.
+type TotalsEstimate struct {
+	Subtotal     money.Money
+	EstimatedTax money.Money
+	Total        money.Money
+	TaxRate      float64 // e.g., 0.08 for 8%
+}
+
+// StockShortageError is returned by ValidateForCheckout when cart lines ask
+// for more units than are in stock. It matches ErrOutOfStock with errors.Is.
+type StockShortageError struct {
+	Shortages []inventory.Shortage
+}
+
+func (e *StockShortageError) Error() string {
+	skus := make([]string, len(e.Shortages))
+	for i, shortage := range e.Shortages {
+		skus[i] = fmt.Sprintf("%s (requested %d, available %d)", shortage.SKU, shortage.Requested, shortage.Available)
+	}
+	return "insufficient stock: " + strings.Join(skus, ", ")
+}
+
+func (e *StockShortageError) Unwrap() error {
+	return ErrOutOfStock
+}
+
 // CartService implements the Service interface.
 type CartService struct {
 	repo             Repository
@@ -52,6 +183,57 @@ type CartService struct {
 	variantRepo      catalog.VariantRepository
 	inventoryService inventory.Service
 	idGenerator      func() string
+	hooks            CartHooks
+	mergePolicy      MergePolicy
+	purgeBatchSize   int
+	maxLines         int // Zero for no limit
+	maxQuantity      int // Zero for no limit
+}
+
+// DefaultPurgeBatchSize is how many expired carts PurgeExpired deletes per
+// batch unless WithPurgeBatchSize says otherwise.
+const DefaultPurgeBatchSize = 500
+
+// Option configures optional CartService behavior.
+type Option func(*CartService)
+
+// WithHooks installs hooks that CartService invokes around cart operations.
+func WithHooks(hooks CartHooks) Option {
+	return func(s *CartService) {
+		s.hooks = hooks
+	}
+}
+
+// WithMergePolicy sets how MergeCarts resolves price and currency conflicts.
+// By default the newer price wins and lines in another currency are skipped.
+func WithMergePolicy(policy MergePolicy) Option {
+	return func(s *CartService) {
+		s.mergePolicy = policy
+	}
+}
+
+// WithPurgeBatchSize sets how many expired carts PurgeExpired looks up and
+// deletes at a time.
+func WithPurgeBatchSize(size int) Option {
+	return func(s *CartService) {
+		s.purgeBatchSize = size
+	}
+}
+
+// WithMaxLines caps the number of distinct lines in a cart. Zero, the
+// default, means no limit.
+func WithMaxLines(n int) Option {
+	return func(s *CartService) {
+		s.maxLines = n
+	}
+}
+
+// WithMaxQuantity caps the total units in a cart, as counted by
+// Cart.ItemCount. Zero, the default, means no limit.
+func WithMaxQuantity(n int) Option {
+	return func(s *CartService) {
+		s.maxQuantity = n
+	}
 }
 
 // NewCartService creates a new cart service.
@@ -61,14 +243,27 @@ func NewCartService(
 	variantRepo catalog.VariantRepository,
 	inventoryService inventory.Service,
 	idGenerator func() string,
+	opts ...Option,
 ) *CartService {
-	return &CartService{
+	s := &CartService{
 		repo:             repo,
 		productRepo:      productRepo,
 		variantRepo:      variantRepo,
 		inventoryService: inventoryService,
 		idGenerator:      idGenerator,
+		hooks:            NoopHooks{},
+		purgeBatchSize:   DefaultPurgeBatchSize,
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	if s.hooks == nil {
+		s.hooks = NoopHooks{}
+	}
+	if s.purgeBatchSize <= 0 {
+		s.purgeBatchSize = DefaultPurgeBatchSize
 	}
+	return s
 }
 
 // GetCart retrieves a cart by ID.
@@ -76,6 +271,33 @@ func (s *CartService) GetCart(ctx context.Context, cartID string) (*Cart, error)
 	return s.repo.FindByID(ctx, cartID)
 }
 
+// GetCartWithStock retrieves a cart and annotates each item with its
+// currently available stock. The cart itself is not modified.
+func (s *CartService) GetCartWithStock(ctx context.Context, cartID string) (*CartWithStock, error) {
+	cart, err := s.repo.FindByID(ctx, cartID)
+	if err != nil {
+		return nil, err
+	}
+
+	items := make([]ItemStock, len(cart.Items))
+	for i, item := range cart.Items {
+		items[i] = ItemStock{ItemID: item.ID, SKU: item.SKU}
+		if s.inventoryService == nil {
+			continue
+		}
+		available, err := s.inventoryService.GetAvailableStock(ctx, item.SKU)
+		if err != nil {
+			continue
+		}
+		items[i].StockKnown = true
+		items[i].AvailableQuantity = available
+		items[i].OutOfStock = available <= 0
+		items[i].InsufficientStock = available < item.Quantity
+	}
+
+	return &CartWithStock{Cart: cart, Items: items}, nil
+}
+
 // GetOrCreateCart gets an existing cart or creates a new one.
 func (s *CartService) GetOrCreateCart(ctx context.Context, userID, sessionID string) (*Cart, error) {
 	var cart *Cart
@@ -106,6 +328,10 @@ func (s *CartService) GetOrCreateCart(ctx context.Context, userID, sessionID str
 	}
 	
 	err = s.repo.Save(ctx, cart)
+	if errors.Is(err, ErrDuplicateCart) && userID != "" {
+		// A concurrent request created the user's cart first; use that one.
+		return s.repo.FindByUserID(ctx, userID)
+	}
 	if err != nil {
 		return nil, err
 	}
@@ -124,65 +350,163 @@ func (s *CartService) AddItem(ctx context.Context, cartID string, req AddItemReq
 		return nil, err
 	}
 	
-	// Fetch product
-	product, err := s.productRepo.FindByID(ctx, req.ProductID)
+	item, product, err := s.newItem(ctx, req)
 	if err != nil {
 		return nil, err
 	}
 	
+	if err := s.checkAdd(ctx, cart, product, item, item.Quantity); err != nil {
+		return nil, err
+	}
+
+	if err := s.hooks.BeforeAddItem(ctx, cart, item); err != nil {
+		return nil, err
+	}
+
+	cart.AddItem(item)
+
+	err = s.repo.Save(ctx, cart)
+	if err != nil {
+		return nil, err
+	}
+
+	s.hooks.AfterAddItem(ctx, cart, item)
+
+	return cart, nil
+}
+
+// AddItems adds several products to the cart as one unit (e.g., reordering
+// a past order). Every request is validated as AddItem would, counting the
+// requests before it, and the cart is saved once; if any request fails,
+// nothing is added and the error names the failing request's index.
+func (s *CartService) AddItems(ctx context.Context, cartID string, reqs []AddItemRequest) (*Cart, error) {
+	cart, err := s.repo.FindByID(ctx, cartID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Work on a copy so a failure part-way leaves the cart untouched.
+	working := *cart
+	working.Items = append([]CartItem(nil), cart.Items...)
+
+	items := make([]CartItem, 0, len(reqs))
+	requested := make(map[string]int) // Units per SKU across the batch
+	for i, req := range reqs {
+		if req.Quantity <= 0 {
+			return nil, fmt.Errorf("item %d: %w", i, ErrInvalidQuantity)
+		}
+
+		item, product, err := s.newItem(ctx, req)
+		if err != nil {
+			return nil, fmt.Errorf("item %d: %w", i, err)
+		}
+
+		requested[item.SKU] += item.Quantity
+		if err := s.checkAdd(ctx, &working, product, item, requested[item.SKU]); err != nil {
+			return nil, fmt.Errorf("item %d: %w", i, err)
+		}
+
+		if err := s.hooks.BeforeAddItem(ctx, &working, item); err != nil {
+			return nil, fmt.Errorf("item %d: %w", i, err)
+		}
+
+		working.AddItem(item)
+		items = append(items, item)
+	}
+
+	*cart = working
+
+	err = s.repo.Save(ctx, cart)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, item := range items {
+		s.hooks.AfterAddItem(ctx, cart, item)
+	}
+
+	return cart, nil
+}
+
+// newItem looks up the product (and variant) req refers to and builds the
+// cart item for it, priced as of now.
+func (s *CartService) newItem(ctx context.Context, req AddItemRequest) (CartItem, *catalog.Product, error) {
+	product, err := s.productRepo.FindByID(ctx, req.ProductID)
+	if err != nil {
+		return CartItem{}, nil, err
+	}
+
 	if !product.IsActive() {
-		return nil, errors.New("product not available")
+		return CartItem{}, nil, errors.New("product not available")
 	}
-	
-	// Check inventory if service available
+
 	var sku string
 	var price money.Money
+	var variant *catalog.Variant
 	
 	if req.VariantID != nil {
-		variant, err := s.variantRepo.FindByID(ctx, *req.VariantID)
+		variant, err = s.variantRepo.FindByID(ctx, *req.VariantID)
 		if err != nil {
-			return nil, err
+			return CartItem{}, nil, err
 		}
 		sku = variant.SKU
 		price = variant.Price
 		
 		if !variant.IsAvailable {
-			return nil, errors.New("variant not available")
+			return CartItem{}, nil, errors.New("variant not available")
 		}
 	} else {
 		sku = product.SKU
 		price = product.BasePrice
 	}
 	
-	// Check stock availability
-	if s.inventoryService != nil {
-		available, err := s.inventoryService.GetAvailableStock(ctx, sku)
-		if err == nil && available < req.Quantity {
-			return nil, ErrOutOfStock
-		}
-	}
-	
-	// Add item to cart
+	lengthCm, widthCm, heightCm := product.GetEffectiveDimensions(variant)
+
 	item := CartItem{
-		ID:         s.idGenerator(),
-		ProductID:  req.ProductID,
-		VariantID:  req.VariantID,
-		SKU:        sku,
-		Name:       product.Name,
-		Price:      price,
-		Quantity:   req.Quantity,
-		Attributes: req.Attributes,
-		AddedAt:    time.Now(),
+		ID:             s.idGenerator(),
+		ProductID:      req.ProductID,
+		VariantID:      req.VariantID,
+		SKU:            sku,
+		Name:           product.Name,
+		Price:          price,
+		Quantity:       req.Quantity,
+		Attributes:     req.Attributes,
+		TaxCode:        product.TaxCode,
+		CategoryID:     product.CategoryID,
+		WeightGrams:    product.GetEffectiveWeight(variant),
+		LengthCm:       lengthCm,
+		WidthCm:        widthCm,
+		HeightCm:       heightCm,
+		AllowBackorder: product.AllowBackorder,
+		AddedAt:        time.Now(),
 	}
-	
-	cart.AddItem(item)
-	
-	err = s.repo.Save(ctx, cart)
-	if err != nil {
-		return nil, err
+	return item, product, nil
+}
+
+// checkAdd checks that item may be added to cart: the product's quantity
+// limit, the service's cart caps, and, unless the item accepts backorders,
+// that stockQuantity units of its SKU are in stock.
+func (s *CartService) checkAdd(ctx context.Context, cart *Cart, product *catalog.Product, item CartItem, stockQuantity int) error {
+	if err := checkQuantityLimit(cart, product, "", item.Quantity); err != nil {
+		return err
 	}
 	
-	return cart, nil
+	newLines := 1
+	if cart.findLine(item) != nil {
+		newLines = 0
+	}
+	if err := s.checkCartLimits(cart, newLines, item.Quantity); err != nil {
+		return err
+	}
+
+	// Check stock availability; backorderable products may exceed it
+	if s.inventoryService != nil && !item.AllowBackorder {
+		available, err := s.inventoryService.GetAvailableStock(ctx, item.SKU)
+		if err == nil && available < stockQuantity {
+			return ErrOutOfStock
+		}
+	}
+	return nil
 }
 
 // UpdateItemQuantity updates the quantity of a cart item.
@@ -196,9 +520,22 @@ func (s *CartService) UpdateItemQuantity(ctx context.Context, cartID, itemID str
 	if item == nil {
 		return nil, ErrItemNotFound
 	}
+
+	if quantity > item.Quantity {
+		product, err := s.productRepo.FindByID(ctx, item.ProductID)
+		if err != nil {
+			return nil, err
+		}
+		if err := checkQuantityLimit(cart, product, itemID, quantity); err != nil {
+			return nil, err
+		}
+		if err := s.checkCartLimits(cart, 0, quantity-item.Quantity); err != nil {
+			return nil, err
+		}
+	}
 	
 	// Check stock if increasing quantity
-	if quantity > item.Quantity && s.inventoryService != nil {
+	if quantity > item.Quantity && s.inventoryService != nil && !item.AllowBackorder {
 		available, err := s.inventoryService.GetAvailableStock(ctx, item.SKU)
 		if err == nil && available < quantity {
 			return nil, ErrOutOfStock
@@ -234,6 +571,57 @@ func (s *CartService) RemoveItem(ctx context.Context, cartID, itemID string) (*C
 	return cart, nil
 }
 
+// SaveForLater moves an item from the cart to the cart's saved items, where
+// it no longer counts toward the subtotal or checkout.
+func (s *CartService) SaveForLater(ctx context.Context, cartID, itemID string) (*Cart, error) {
+	cart, err := s.repo.FindByID(ctx, cartID)
+	if err != nil {
+		return nil, err
+	}
+
+	if !cart.SaveForLater(itemID) {
+		return nil, ErrItemNotFound
+	}
+
+	err = s.repo.Save(ctx, cart)
+	if err != nil {
+		return nil, err
+	}
+
+	return cart, nil
+}
+
+// MoveToCart moves a saved item back into the cart. The product's quantity
+// limit and current stock are checked as if the item were being added.
+func (s *CartService) MoveToCart(ctx context.Context, cartID, itemID string) (*Cart, error) {
+	cart, err := s.repo.FindByID(ctx, cartID)
+	if err != nil {
+		return nil, err
+	}
+
+	item := cart.FindSavedItem(itemID)
+	if item == nil {
+		return nil, ErrItemNotFound
+	}
+
+	product, err := s.productRepo.FindByID(ctx, item.ProductID)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.checkAdd(ctx, cart, product, *item, item.Quantity); err != nil {
+		return nil, err
+	}
+
+	cart.MoveToCart(itemID)
+
+	err = s.repo.Save(ctx, cart)
+	if err != nil {
+		return nil, err
+	}
+
+	return cart, nil
+}
+
 // Clear removes all items from the cart.
 func (s *CartService) Clear(ctx context.Context, cartID string) (*Cart, error) {
 	cart, err := s.repo.FindByID(ctx, cartID)
@@ -251,27 +639,341 @@ func (s *CartService) Clear(ctx context.Context, cartID string) (*Cart, error) {
 	return cart, nil
 }
 
-// MergeCarts merges source cart into target cart (e.g., guest -> user cart).
-func (s *CartService) MergeCarts(ctx context.Context, sourceCartID, targetCartID string) (*Cart, error) {
+// MergeCarts merges source cart into target cart (e.g., guest -> user cart)
+// using the service's MergePolicy, and returns a decision for each source
+// line so the shopper can be told about repriced or skipped items. The
+// source cart is deleted afterwards, so skipped lines are dropped.
+func (s *CartService) MergeCarts(ctx context.Context, sourceCartID, targetCartID string) (*Cart, []MergeDecision, error) {
 	sourceCart, err := s.repo.FindByID(ctx, sourceCartID)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 	
 	targetCart, err := s.repo.FindByID(ctx, targetCartID)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 	
-	targetCart.Merge(sourceCart)
+	decisions, err := targetCart.Merge(sourceCart, s.mergePolicy)
+	if err != nil {
+		return nil, nil, err
+	}
 	
 	err = s.repo.Save(ctx, targetCart)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 	
 	// Optionally delete source cart
 	_ = s.repo.Delete(ctx, sourceCartID)
 	
-	return targetCart, nil
+	return targetCart, decisions, nil
+}
+
+// TransferCart reassigns a cart to a new owner instead of merging it
+// (e.g., a guest logs in on a new device). A cart already owned by a
+// different user cannot be transferred. Since a user has at most one cart,
+// if newUserID already has a different cart, the transferred cart is merged
+// into that one instead (see MergeCarts) and the merged cart is returned.
+func (s *CartService) TransferCart(ctx context.Context, cartID, newUserID, newSessionID string) (*Cart, error) {
+	if newUserID == "" && newSessionID == "" {
+		return nil, errors.New("userID or sessionID required")
+	}
+
+	cart, err := s.repo.FindByID(ctx, cartID)
+	if err != nil {
+		return nil, err
+	}
+
+	if cart.UserID != "" && cart.UserID != newUserID {
+		return nil, ErrCartAlreadyOwned
+	}
+
+	cart.UserID = newUserID
+	cart.SessionID = newSessionID
+	cart.UpdatedAt = time.Now()
+
+	err = s.repo.Save(ctx, cart)
+	if errors.Is(err, ErrDuplicateCart) && newUserID != "" {
+		existing, findErr := s.repo.FindByUserID(ctx, newUserID)
+		if findErr != nil {
+			return nil, findErr
+		}
+		merged, _, mergeErr := s.MergeCarts(ctx, cartID, existing.ID)
+		return merged, mergeErr
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return cart, nil
+}
+
+// ValidateForCheckout loads a cart and checks that it can proceed to checkout:
+// every line must be in stock (checked in one inventory call, failing with a
+// *StockShortageError) unless its product accepts backorders, and the
+// BeforeCheckout hook must pass. It returns the cart when checkout may
+// continue.
+func (s *CartService) ValidateForCheckout(ctx context.Context, cartID string) (*Cart, error) {
+	cart, err := s.repo.FindByID(ctx, cartID)
+	if err != nil {
+		return nil, err
+	}
+
+	if cart.IsEmpty() {
+		return nil, ErrEmptyCart
+	}
+
+	if s.inventoryService != nil {
+		var requests []inventory.StockRequest
+		for _, item := range cart.Items {
+			if !item.AllowBackorder {
+				requests = append(requests, inventory.StockRequest{SKU: item.SKU, Quantity: item.Quantity})
+			}
+		}
+		_, shortages, err := s.inventoryService.CheckAvailability(ctx, requests)
+		if err != nil {
+			return nil, err
+		}
+		if len(shortages) > 0 {
+			return nil, &StockShortageError{Shortages: shortages}
+		}
+	}
+
+	if err := s.hooks.BeforeCheckout(ctx, cart); err != nil {
+		return nil, err
+	}
+
+	return cart, nil
+}
+
+// ValidateStock checks every cart item against current inventory and catalog
+// data and returns the problems found, in cart order; an empty result means
+// the cart can be checked out as it is. Stock is checked in one inventory
+// call, with lines for the same SKU counted together, and skipped for items
+// that accept backorders or when no inventory service is configured. The
+// cart is not modified.
+func (s *CartService) ValidateStock(ctx context.Context, cartID string) ([]StockIssue, error) {
+	cart, err := s.repo.FindByID(ctx, cartID)
+	if err != nil {
+		return nil, err
+	}
+
+	requested := make(map[string]int)
+	var available map[string]int
+	if s.inventoryService != nil {
+		var requests []inventory.StockRequest
+		for _, item := range cart.Items {
+			if !item.AllowBackorder {
+				requested[item.SKU] += item.Quantity
+				requests = append(requests, inventory.StockRequest{SKU: item.SKU, Quantity: item.Quantity})
+			}
+		}
+		if len(requests) > 0 {
+			available, _, err = s.inventoryService.CheckAvailability(ctx, requests)
+			if err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	var issues []StockIssue
+	for _, item := range cart.Items {
+		issue := StockIssue{
+			ItemID:    item.ID,
+			SKU:       item.SKU,
+			Requested: item.Quantity,
+			CartPrice: item.Price,
+		}
+
+		if quantity, ok := available[item.SKU]; ok && !item.AllowBackorder {
+			issue.Requested = requested[item.SKU]
+			issue.Available = quantity
+			switch {
+			case quantity <= 0:
+				issue.Type = StockIssueOutOfStock
+				issues = append(issues, issue)
+			case quantity < requested[item.SKU]:
+				issue.Type = StockIssueInsufficientStock
+				issues = append(issues, issue)
+			}
+		}
+
+		price, ok := s.currentPrice(ctx, item)
+		switch {
+		case !ok:
+			issue.Type = StockIssueUnavailable
+			issues = append(issues, issue)
+		case !price.Equals(item.Price):
+			issue.Type = StockIssuePriceChanged
+			issue.NewPrice = price
+			issues = append(issues, issue)
+		}
+	}
+
+	return issues, nil
+}
+
+// RefreshPrices re-reads the current price of every cart item and updates
+// the items whose price changed, saving the cart if any did. Items whose
+// product or variant is no longer sold are left alone (see ValidateStock).
+func (s *CartService) RefreshPrices(ctx context.Context, cartID string) (*Cart, []PriceChange, error) {
+	cart, err := s.repo.FindByID(ctx, cartID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	changes := s.priceChanges(ctx, cart)
+	if len(changes) == 0 {
+		return cart, nil, nil
+	}
+
+	for _, change := range changes {
+		cart.FindItem(change.ItemID).Price = change.NewPrice
+	}
+	cart.UpdatedAt = time.Now()
+
+	if err := s.repo.Save(ctx, cart); err != nil {
+		return nil, nil, err
+	}
+
+	return cart, changes, nil
+}
+
+// PriceChanges reports the cart items whose current price differs from the
+// cart price, in cart order. The cart is not modified.
+func (s *CartService) PriceChanges(ctx context.Context, cartID string) ([]PriceChange, error) {
+	cart, err := s.repo.FindByID(ctx, cartID)
+	if err != nil {
+		return nil, err
+	}
+	return s.priceChanges(ctx, cart), nil
+}
+
+func (s *CartService) priceChanges(ctx context.Context, cart *Cart) []PriceChange {
+	var changes []PriceChange
+	for _, item := range cart.Items {
+		price, ok := s.currentPrice(ctx, item)
+		if !ok || price.Equals(item.Price) {
+			continue
+		}
+		difference, err := price.Subtract(item.Price)
+		if err != nil {
+			difference = money.Zero(price.Currency)
+		}
+		changes = append(changes, PriceChange{
+			ItemID:     item.ID,
+			SKU:        item.SKU,
+			OldPrice:   item.Price,
+			NewPrice:   price,
+			Difference: difference,
+		})
+	}
+	return changes
+}
+
+// checkQuantityLimit enforces product.MaxPerOrder, counting every cart line
+// for the product. The line itemID (if any) is counted as quantity rather
+// than its current quantity; with no itemID, quantity is being added.
+func checkQuantityLimit(cart *Cart, product *catalog.Product, itemID string, quantity int) error {
+	if product.MaxPerOrder == nil {
+		return nil
+	}
+
+	total := quantity
+	for _, item := range cart.Items {
+		if item.ProductID == product.ID && item.ID != itemID {
+			total += item.Quantity
+		}
+	}
+	if total > *product.MaxPerOrder {
+		return fmt.Errorf("%w: at most %d of %s per order", ErrQuantityLimitExceeded, *product.MaxPerOrder, product.Name)
+	}
+	return nil
+}
+
+// checkCartLimits enforces the service's line and quantity caps on cart
+// after adding newLines lines and addedQuantity units.
+func (s *CartService) checkCartLimits(cart *Cart, newLines, addedQuantity int) error {
+	if s.maxLines > 0 && len(cart.Items)+newLines > s.maxLines {
+		return fmt.Errorf("%w: at most %d lines per cart", ErrCartLimitExceeded, s.maxLines)
+	}
+	if s.maxQuantity > 0 && cart.ItemCount()+addedQuantity > s.maxQuantity {
+		return fmt.Errorf("%w: at most %d units per cart", ErrCartLimitExceeded, s.maxQuantity)
+	}
+	return nil
+}
+
+// currentPrice returns the price AddItem would charge for item today. It
+// reports false if the product or variant can't be found or is no longer
+// sold.
+func (s *CartService) currentPrice(ctx context.Context, item CartItem) (money.Money, bool) {
+	product, err := s.productRepo.FindByID(ctx, item.ProductID)
+	if err != nil || !product.IsActive() {
+		return money.Money{}, false
+	}
+	if item.VariantID == nil {
+		return product.BasePrice, true
+	}
+	variant, err := s.variantRepo.FindByID(ctx, *item.VariantID)
+	if err != nil || !variant.IsAvailable {
+		return money.Money{}, false
+	}
+	return variant.Price, true
+}
+
+// PurgeExpired deletes every cart whose expiry has passed, guest and user
+// carts alike, and returns how many it deleted. It works in batches of the
+// configured size so a large backlog never loads at once; carts expiring
+// while it runs are left for the next run. A failed delete stops the purge
+// and is returned with the count so far.
+func (s *CartService) PurgeExpired(ctx context.Context) (int, error) {
+	now := time.Now()
+	deleted := 0
+	for {
+		ids, err := s.repo.FindExpired(ctx, now, s.purgeBatchSize)
+		if err != nil {
+			return deleted, err
+		}
+
+		for _, id := range ids {
+			if err := s.repo.Delete(ctx, id); err != nil {
+				return deleted, err
+			}
+			deleted++
+		}
+
+		if len(ids) < s.purgeBatchSize {
+			return deleted, nil
+		}
+	}
+}
+
+// EstimateTotals returns a quick subtotal, flat-rate tax, and total for a cart
+// (e.g., early in the funnel, before an address is known). It doesn't use the
+// pricing, tax, or shipping services.
+func (s *CartService) EstimateTotals(ctx context.Context, cartID string, flatTaxRate float64) (*TotalsEstimate, error) {
+	if flatTaxRate < 0 {
+		return nil, ErrInvalidTaxRate
+	}
+
+	cart, err := s.repo.FindByID(ctx, cartID)
+	if err != nil {
+		return nil, err
+	}
+
+	subtotal, err := cart.sumLines()
+	if err != nil {
+		return nil, err
+	}
+
+	tax := subtotal.Multiply(flatTaxRate)
+	total, _ := subtotal.Add(tax)
+
+	return &TotalsEstimate{
+		Subtotal:     subtotal,
+		EstimatedTax: tax,
+		Total:        total,
+		TaxRate:      flatTaxRate,
+	}, nil
 }
@@ -0,0 +1,764 @@
+package cart
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/devchuckcamp/gocommerce/catalog"
+	"github.com/devchuckcamp/gocommerce/inventory"
+	"github.com/devchuckcamp/gocommerce/money"
+)
+
+// memoryRepo is an in-memory Repository that, like the SQL schema, allows
+// one cart per user.
+type memoryRepo struct {
+	Repository
+	carts map[string]*Cart
+}
+
+func newMemoryRepo(carts ...*Cart) *memoryRepo {
+	r := &memoryRepo{carts: make(map[string]*Cart)}
+	for _, c := range carts {
+		r.carts[c.ID] = copyCart(c)
+	}
+	return r
+}
+
+func copyCart(c *Cart) *Cart {
+	copied := *c
+	copied.Items = append([]CartItem(nil), c.Items...)
+	copied.SavedItems = append([]CartItem(nil), c.SavedItems...)
+	return &copied
+}
+
+func (r *memoryRepo) FindByID(ctx context.Context, id string) (*Cart, error) {
+	c, ok := r.carts[id]
+	if !ok {
+		return nil, ErrCartNotFound
+	}
+	return copyCart(c), nil
+}
+
+func (r *memoryRepo) FindByUserID(ctx context.Context, userID string) (*Cart, error) {
+	for _, c := range r.carts {
+		if c.UserID == userID {
+			return copyCart(c), nil
+		}
+	}
+	return nil, ErrCartNotFound
+}
+
+func (r *memoryRepo) Save(ctx context.Context, c *Cart) error {
+	for _, other := range r.carts {
+		if c.UserID != "" && other.UserID == c.UserID && other.ID != c.ID {
+			return ErrDuplicateCart
+		}
+	}
+	r.carts[c.ID] = copyCart(c)
+	return nil
+}
+
+func (r *memoryRepo) Delete(ctx context.Context, id string) error {
+	delete(r.carts, id)
+	return nil
+}
+
+func usd(cents int64) money.Money {
+	return money.Money{Amount: cents, Currency: "USD"}
+}
+
+func TestTransferCartMergesIntoExistingUserCart(t *testing.T) {
+	ctx := context.Background()
+	repo := newMemoryRepo(
+		&Cart{ID: "user-cart", UserID: "user-1", Items: []CartItem{
+			{ID: "i1", ProductID: "p1", SKU: "SKU-1", Price: usd(1000), Quantity: 1},
+		}},
+		&Cart{ID: "guest-cart", SessionID: "session-1", Items: []CartItem{
+			{ID: "i2", ProductID: "p2", SKU: "SKU-2", Price: usd(500), Quantity: 2},
+		}},
+	)
+	s := NewCartService(repo, nil, nil, nil, func() string { return "new-id" })
+
+	merged, err := s.TransferCart(ctx, "guest-cart", "user-1", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if merged.ID != "user-cart" {
+		t.Errorf("returned cart %s, want user-cart", merged.ID)
+	}
+	if len(merged.Items) != 2 {
+		t.Errorf("merged cart has %d lines, want 2", len(merged.Items))
+	}
+	if _, ok := repo.carts["guest-cart"]; ok {
+		t.Error("guest cart was not deleted")
+	}
+}
+
+func TestTransferCartToNewOwner(t *testing.T) {
+	ctx := context.Background()
+	repo := newMemoryRepo(&Cart{ID: "guest-cart", SessionID: "session-1"})
+	s := NewCartService(repo, nil, nil, nil, func() string { return "new-id" })
+
+	transferred, err := s.TransferCart(ctx, "guest-cart", "user-1", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if transferred.ID != "guest-cart" || transferred.UserID != "user-1" {
+		t.Errorf("got cart %s owned by %q, want guest-cart owned by user-1", transferred.ID, transferred.UserID)
+	}
+
+	if _, err := s.TransferCart(ctx, "guest-cart", "user-2", ""); !errors.Is(err, ErrCartAlreadyOwned) {
+		t.Errorf("transfer to another user: error = %v, want %v", err, ErrCartAlreadyOwned)
+	}
+}
+
+func TestTransferCartKeepsItemsAndValidatesInput(t *testing.T) {
+	ctx := context.Background()
+	repo := newMemoryRepo(&Cart{ID: "guest-cart", SessionID: "session-1", Items: []CartItem{
+		{ID: "i1", ProductID: "p1", SKU: "SKU-1", Price: usd(1000), Quantity: 2},
+	}})
+	s := NewCartService(repo, nil, nil, nil, func() string { return "new-id" })
+
+	if _, err := s.TransferCart(ctx, "guest-cart", "", ""); err == nil {
+		t.Error("transfer without an owner succeeded")
+	}
+	if _, err := s.TransferCart(ctx, "missing", "user-1", ""); !errors.Is(err, ErrCartNotFound) {
+		t.Errorf("missing cart: error = %v, want %v", err, ErrCartNotFound)
+	}
+
+	transferred, err := s.TransferCart(ctx, "guest-cart", "user-1", "session-2")
+	if err != nil {
+		t.Fatal(err)
+	}
+	stored := repo.carts["guest-cart"]
+	if stored.UserID != "user-1" || stored.SessionID != "session-2" {
+		t.Errorf("stored owner = %q/%q, want user-1/session-2", stored.UserID, stored.SessionID)
+	}
+	if len(transferred.Items) != 1 || transferred.Items[0].Quantity != 2 {
+		t.Errorf("transferred items = %+v, want one line of 2", transferred.Items)
+	}
+
+	if _, err := s.TransferCart(ctx, "guest-cart", "user-1", ""); err != nil {
+		t.Errorf("transfer to the current owner: %v", err)
+	}
+}
+
+var errProductNotFound = errors.New("product not found")
+
+// productRepo is an in-memory catalog.ProductRepository keyed by product ID.
+type productRepo struct {
+	catalog.ProductRepository
+	products map[string]*catalog.Product
+}
+
+func (r *productRepo) FindByID(ctx context.Context, id string) (*catalog.Product, error) {
+	p, ok := r.products[id]
+	if !ok {
+		return nil, errProductNotFound
+	}
+	return p, nil
+}
+
+// variantRepo is an in-memory catalog.VariantRepository keyed by variant ID.
+type variantRepo struct {
+	catalog.VariantRepository
+	variants map[string]*catalog.Variant
+}
+
+func (r *variantRepo) FindByID(ctx context.Context, id string) (*catalog.Variant, error) {
+	v, ok := r.variants[id]
+	if !ok {
+		return nil, errProductNotFound
+	}
+	return v, nil
+}
+
+// activeProduct returns an active product whose ID and SKU are both id.
+func activeProduct(id string, cents int64) *catalog.Product {
+	return &catalog.Product{ID: id, SKU: id, Name: id, BasePrice: usd(cents), Status: catalog.ProductStatusActive}
+}
+
+// newTestService returns a service over an empty cart "cart-1" and the given
+// products, with no inventory checks.
+func newTestService(products []*catalog.Product, opts ...Option) (*CartService, *memoryRepo) {
+	repo := newMemoryRepo(&Cart{ID: "cart-1", UserID: "user-1"})
+	catalogRepo := &productRepo{products: make(map[string]*catalog.Product)}
+	for _, p := range products {
+		catalogRepo.products[p.ID] = p
+	}
+	seq := 0
+	next := func() string {
+		seq++
+		return fmt.Sprintf("item-%d", seq)
+	}
+	return NewCartService(repo, catalogRepo, &variantRepo{}, nil, next, opts...), repo
+}
+
+// regionHooks refuses items from a blocked product and records what was added.
+type regionHooks struct {
+	NoopHooks
+	blocked string
+	added   []string
+}
+
+var errRestricted = errors.New("not sold in this region")
+
+func (h *regionHooks) BeforeAddItem(ctx context.Context, cart *Cart, item CartItem) error {
+	if item.ProductID == h.blocked {
+		return errRestricted
+	}
+	return nil
+}
+
+func (h *regionHooks) AfterAddItem(ctx context.Context, cart *Cart, item CartItem) {
+	h.added = append(h.added, item.ProductID)
+}
+
+func (h *regionHooks) BeforeCheckout(ctx context.Context, cart *Cart) error {
+	if len(cart.Items) > 1 {
+		return errRestricted
+	}
+	return nil
+}
+
+func TestCartHooks(t *testing.T) {
+	ctx := context.Background()
+	hooks := &regionHooks{blocked: "knife"}
+	s, repo := newTestService([]*catalog.Product{activeProduct("mug", 1200), activeProduct("knife", 3000), activeProduct("plate", 800)}, WithHooks(hooks))
+
+	if _, err := s.AddItem(ctx, "cart-1", AddItemRequest{ProductID: "knife", Quantity: 1}); !errors.Is(err, errRestricted) {
+		t.Errorf("blocked product: error = %v, want %v", err, errRestricted)
+	}
+	if _, err := s.AddItem(ctx, "cart-1", AddItemRequest{ProductID: "mug", Quantity: 1}); err != nil {
+		t.Fatal(err)
+	}
+	if len(repo.carts["cart-1"].Items) != 1 {
+		t.Errorf("cart has %d lines, want 1", len(repo.carts["cart-1"].Items))
+	}
+	if len(hooks.added) != 1 || hooks.added[0] != "mug" {
+		t.Errorf("AfterAddItem saw %v, want [mug]", hooks.added)
+	}
+
+	if _, err := s.ValidateForCheckout(ctx, "cart-1"); err != nil {
+		t.Errorf("checkout with one line: %v", err)
+	}
+	if _, err := s.AddItem(ctx, "cart-1", AddItemRequest{ProductID: "plate", Quantity: 1}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.ValidateForCheckout(ctx, "cart-1"); !errors.Is(err, errRestricted) {
+		t.Errorf("checkout vetoed: error = %v, want %v", err, errRestricted)
+	}
+}
+
+func TestAddItemUsesVariantWeightAndDimensions(t *testing.T) {
+	ctx := context.Background()
+	product := activeProduct("tee", 2000)
+	product.WeightGrams, product.LengthCm, product.WidthCm, product.HeightCm = 200, 30, 20, 2
+	s, _ := newTestService([]*catalog.Product{product})
+	s.variantRepo = &variantRepo{variants: map[string]*catalog.Variant{
+		"tee-xxl": {ID: "tee-xxl", ProductID: "tee", SKU: "TEE-XXL", Price: usd(2200), IsAvailable: true, WeightGrams: 350, LengthCm: 40, WidthCm: 30, HeightCm: 3},
+	}}
+
+	variantID := "tee-xxl"
+	c, err := s.AddItem(ctx, "cart-1", AddItemRequest{ProductID: "tee", VariantID: &variantID, Quantity: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.AddItem(ctx, "cart-1", AddItemRequest{ProductID: "tee", Quantity: 1}); err != nil {
+		t.Fatal(err)
+	}
+	c, _ = s.GetCart(ctx, c.ID)
+
+	xxl, plain := c.Items[0], c.Items[1]
+	if xxl.WeightGrams != 350 || xxl.LengthCm != 40 || xxl.WidthCm != 30 || xxl.HeightCm != 3 {
+		t.Errorf("variant line = %dg %dx%dx%d, want 350g 40x30x3", xxl.WeightGrams, xxl.LengthCm, xxl.WidthCm, xxl.HeightCm)
+	}
+	if plain.WeightGrams != 200 || plain.LengthCm != 30 {
+		t.Errorf("product line = %dg, %dcm long; want 200g, 30cm", plain.WeightGrams, plain.LengthCm)
+	}
+}
+
+// racingRepo simulates another request creating the user's cart between
+// GetOrCreateCart's lookup and its save.
+type racingRepo struct {
+	*memoryRepo
+	rival *Cart
+	raced bool
+}
+
+func (r *racingRepo) FindByUserID(ctx context.Context, userID string) (*Cart, error) {
+	if !r.raced {
+		r.raced = true
+		r.carts[r.rival.ID] = copyCart(r.rival)
+		return nil, ErrCartNotFound
+	}
+	return r.memoryRepo.FindByUserID(ctx, userID)
+}
+
+func TestGetOrCreateCartConcurrentCreation(t *testing.T) {
+	ctx := context.Background()
+	repo := &racingRepo{memoryRepo: newMemoryRepo(), rival: &Cart{ID: "rival-cart", UserID: "user-1"}}
+	s := NewCartService(repo, nil, nil, nil, func() string { return "new-cart" })
+
+	c, err := s.GetOrCreateCart(ctx, "user-1", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if c.ID != "rival-cart" {
+		t.Errorf("got cart %s, want rival-cart", c.ID)
+	}
+	if len(repo.carts) != 1 {
+		t.Errorf("%d carts stored, want 1", len(repo.carts))
+	}
+}
+
+// stockService returns an inventory service holding onHand units per SKU.
+func stockService(t *testing.T, onHand map[string]int) *inventory.MemoryService {
+	t.Helper()
+	repo := inventory.NewMemoryRepository()
+	for sku, qty := range onHand {
+		if err := repo.UpdateStockLevel(context.Background(), &inventory.StockLevel{
+			SKU:               sku,
+			QuantityOnHand:    qty,
+			QuantityAvailable: qty,
+		}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return inventory.NewMemoryService(repo)
+}
+
+func TestGetCartWithStock(t *testing.T) {
+	ctx := context.Background()
+	repo := newMemoryRepo(&Cart{ID: "cart-1", Items: []CartItem{
+		{ID: "plenty", SKU: "SKU-1", Price: usd(1000), Quantity: 2},
+		{ID: "short", SKU: "SKU-2", Price: usd(1000), Quantity: 3},
+		{ID: "gone", SKU: "SKU-3", Price: usd(1000), Quantity: 1},
+		{ID: "unknown", SKU: "SKU-4", Price: usd(1000), Quantity: 1},
+	}})
+	s := NewCartService(repo, nil, nil, stockService(t, map[string]int{"SKU-1": 5, "SKU-2": 1, "SKU-3": 0}), nil)
+
+	result, err := s.GetCartWithStock(ctx, "cart-1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []ItemStock{
+		{ItemID: "plenty", SKU: "SKU-1", StockKnown: true, AvailableQuantity: 5},
+		{ItemID: "short", SKU: "SKU-2", StockKnown: true, AvailableQuantity: 1, InsufficientStock: true},
+		{ItemID: "gone", SKU: "SKU-3", StockKnown: true, OutOfStock: true, InsufficientStock: true},
+		{ItemID: "unknown", SKU: "SKU-4"},
+	}
+	if len(result.Items) != len(want) {
+		t.Fatalf("got %d items, want %d", len(result.Items), len(want))
+	}
+	for i := range want {
+		if result.Items[i] != want[i] {
+			t.Errorf("item %d = %+v, want %+v", i, result.Items[i], want[i])
+		}
+	}
+	if len(result.Cart.Items) != 4 {
+		t.Errorf("cart has %d items, want 4", len(result.Cart.Items))
+	}
+}
+
+func TestEstimateTotals(t *testing.T) {
+	ctx := context.Background()
+	repo := newMemoryRepo(
+		&Cart{ID: "cart-1", Items: []CartItem{
+			{ID: "a", SKU: "A", Price: usd(1999), Quantity: 2},
+			{ID: "b", SKU: "B", Price: usd(502), Quantity: 1},
+		}},
+		&Cart{ID: "mixed", Items: []CartItem{
+			{ID: "a", SKU: "A", Price: usd(1000), Quantity: 1},
+			{ID: "b", SKU: "B", Price: money.Money{Amount: 1000, Currency: "EUR"}, Quantity: 1},
+		}},
+	)
+	s := NewCartService(repo, nil, nil, nil, nil)
+
+	estimate, err := s.EstimateTotals(ctx, "cart-1", 0.08)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if estimate.Subtotal != usd(4500) || estimate.EstimatedTax != usd(360) || estimate.Total != usd(4860) {
+		t.Errorf("estimate = %s + %s = %s, want USD 45.00 + USD 3.60 = USD 48.60",
+			estimate.Subtotal, estimate.EstimatedTax, estimate.Total)
+	}
+
+	if _, err := s.EstimateTotals(ctx, "cart-1", -0.01); !errors.Is(err, ErrInvalidTaxRate) {
+		t.Errorf("negative rate: error = %v, want %v", err, ErrInvalidTaxRate)
+	}
+	if _, err := s.EstimateTotals(ctx, "mixed", 0.08); !errors.Is(err, money.ErrCurrencyMismatch) {
+		t.Errorf("mixed currencies: error = %v, want %v", err, money.ErrCurrencyMismatch)
+	}
+}
+
+func TestValidateForCheckoutReportsShortages(t *testing.T) {
+	ctx := context.Background()
+	repo := newMemoryRepo(&Cart{ID: "cart-1", Items: []CartItem{
+		{ID: "a", SKU: "SKU-1", Price: usd(1000), Quantity: 2},
+		{ID: "b", SKU: "SKU-2", Price: usd(1000), Quantity: 3},
+		{ID: "c", SKU: "SKU-3", Price: usd(1000), Quantity: 4, AllowBackorder: true},
+	}})
+	s := NewCartService(repo, nil, nil, stockService(t, map[string]int{"SKU-1": 5, "SKU-2": 1}), nil)
+
+	_, err := s.ValidateForCheckout(ctx, "cart-1")
+	var shortageErr *StockShortageError
+	if !errors.As(err, &shortageErr) || !errors.Is(err, ErrOutOfStock) {
+		t.Fatalf("error = %v, want *StockShortageError matching %v", err, ErrOutOfStock)
+	}
+	if len(shortageErr.Shortages) != 1 || shortageErr.Shortages[0] != (inventory.Shortage{SKU: "SKU-2", Requested: 3, Available: 1}) {
+		t.Errorf("shortages = %+v, want only SKU-2", shortageErr.Shortages)
+	}
+
+	repo.carts["cart-1"].Items[1].Quantity = 1
+	if _, err := s.ValidateForCheckout(ctx, "cart-1"); err != nil {
+		t.Errorf("in-stock cart: %v", err)
+	}
+}
+
+func TestAddItemAllowsBackorders(t *testing.T) {
+	ctx := context.Background()
+	preorder := activeProduct("preorder", 1000)
+	preorder.AllowBackorder = true
+	s, repo := newTestService([]*catalog.Product{preorder, activeProduct("plain", 1000)})
+	s.inventoryService = stockService(t, map[string]int{"preorder": 0, "plain": 0})
+
+	if _, err := s.AddItem(ctx, "cart-1", AddItemRequest{ProductID: "plain", Quantity: 1}); !errors.Is(err, ErrOutOfStock) {
+		t.Errorf("plain product: error = %v, want %v", err, ErrOutOfStock)
+	}
+	if _, err := s.AddItem(ctx, "cart-1", AddItemRequest{ProductID: "preorder", Quantity: 2}); err != nil {
+		t.Fatal(err)
+	}
+	item := repo.carts["cart-1"].Items[0]
+	if !item.AllowBackorder {
+		t.Error("item doesn't record that its product accepts backorders")
+	}
+	if _, err := s.UpdateItemQuantity(ctx, "cart-1", item.ID, 5); err != nil {
+		t.Errorf("raising a backordered item's quantity: %v", err)
+	}
+	if _, err := s.ValidateForCheckout(ctx, "cart-1"); err != nil {
+		t.Errorf("checkout with backordered item: %v", err)
+	}
+}
+
+func TestValidateStock(t *testing.T) {
+	ctx := context.Background()
+	s, repo := newTestService([]*catalog.Product{
+		activeProduct("A", 1000), activeProduct("B", 1200), activeProduct("D", 1000),
+	})
+	s.inventoryService = stockService(t, map[string]int{"A": 5, "B": 1, "C": 5, "D": 0})
+	repo.carts["cart-1"].Items = []CartItem{
+		{ID: "a", ProductID: "A", SKU: "A", Price: usd(1000), Quantity: 2},
+		{ID: "b", ProductID: "B", SKU: "B", Price: usd(1000), Quantity: 3},
+		{ID: "c", ProductID: "C", SKU: "C", Price: usd(1000), Quantity: 1},
+		{ID: "d", ProductID: "D", SKU: "D", Price: usd(1000), Quantity: 1},
+	}
+
+	issues, err := s.ValidateStock(ctx, "cart-1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []StockIssue{
+		{ItemID: "b", SKU: "B", Type: StockIssueInsufficientStock, Requested: 3, Available: 1, CartPrice: usd(1000)},
+		{ItemID: "b", SKU: "B", Type: StockIssuePriceChanged, Requested: 3, Available: 1, CartPrice: usd(1000), NewPrice: usd(1200)},
+		{ItemID: "c", SKU: "C", Type: StockIssueUnavailable, Requested: 1, Available: 5, CartPrice: usd(1000)},
+		{ItemID: "d", SKU: "D", Type: StockIssueOutOfStock, Requested: 1, Available: 0, CartPrice: usd(1000)},
+	}
+	if len(issues) != len(want) {
+		t.Fatalf("issues = %+v, want %+v", issues, want)
+	}
+	for i := range want {
+		if issues[i] != want[i] {
+			t.Errorf("issue %d = %+v, want %+v", i, issues[i], want[i])
+		}
+	}
+	if repo.carts["cart-1"].Items[1].Price != usd(1000) {
+		t.Error("ValidateStock modified the cart")
+	}
+}
+
+func TestRefreshPrices(t *testing.T) {
+	ctx := context.Background()
+	s, repo := newTestService([]*catalog.Product{activeProduct("A", 1200), activeProduct("B", 1000)})
+	repo.carts["cart-1"].Items = []CartItem{
+		{ID: "a", ProductID: "A", SKU: "A", Price: usd(1000), Quantity: 1},
+		{ID: "b", ProductID: "B", SKU: "B", Price: usd(1000), Quantity: 1},
+		{ID: "c", ProductID: "C", SKU: "C", Price: usd(1000), Quantity: 1},
+	}
+
+	preview, err := s.PriceChanges(ctx, "cart-1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := PriceChange{ItemID: "a", SKU: "A", OldPrice: usd(1000), NewPrice: usd(1200), Difference: usd(200)}
+	if len(preview) != 1 || preview[0] != want {
+		t.Fatalf("PriceChanges = %+v, want [%+v]", preview, want)
+	}
+	if repo.carts["cart-1"].Items[0].Price != usd(1000) {
+		t.Error("PriceChanges modified the cart")
+	}
+
+	cart, changes, err := s.RefreshPrices(ctx, "cart-1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(changes) != 1 || changes[0] != want {
+		t.Errorf("RefreshPrices changes = %+v, want [%+v]", changes, want)
+	}
+	if cart.Items[0].Price != usd(1200) || repo.carts["cart-1"].Items[0].Price != usd(1200) {
+		t.Errorf("refreshed price = %s, saved %s; want USD 12.00", cart.Items[0].Price, repo.carts["cart-1"].Items[0].Price)
+	}
+	if repo.carts["cart-1"].Items[2].Price != usd(1000) {
+		t.Error("item with a missing product was repriced")
+	}
+
+	updated := repo.carts["cart-1"].UpdatedAt
+	if _, changes, err := s.RefreshPrices(ctx, "cart-1"); err != nil || changes != nil {
+		t.Errorf("second refresh = %+v, %v; want no changes", changes, err)
+	}
+	if !repo.carts["cart-1"].UpdatedAt.Equal(updated) {
+		t.Error("unchanged cart was saved")
+	}
+}
+
+func TestMaxPerOrderCountsEveryVariant(t *testing.T) {
+	ctx := context.Background()
+	limit := 3
+	product := activeProduct("tee", 2000)
+	product.MaxPerOrder = &limit
+	s, _ := newTestService([]*catalog.Product{product})
+	s.variantRepo = &variantRepo{variants: map[string]*catalog.Variant{
+		"tee-m": {ID: "tee-m", ProductID: "tee", SKU: "TEE-M", Price: usd(2000), IsAvailable: true},
+		"tee-l": {ID: "tee-l", ProductID: "tee", SKU: "TEE-L", Price: usd(2000), IsAvailable: true},
+	}}
+	medium, large := "tee-m", "tee-l"
+
+	if _, err := s.AddItem(ctx, "cart-1", AddItemRequest{ProductID: "tee", VariantID: &medium, Quantity: 2}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.AddItem(ctx, "cart-1", AddItemRequest{ProductID: "tee", VariantID: &large, Quantity: 2}); !errors.Is(err, ErrQuantityLimitExceeded) {
+		t.Errorf("add past the limit: error = %v, want %v", err, ErrQuantityLimitExceeded)
+	}
+	c, err := s.AddItem(ctx, "cart-1", AddItemRequest{ProductID: "tee", VariantID: &large, Quantity: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if c.ItemCount() != 3 {
+		t.Errorf("ItemCount = %d, want 3", c.ItemCount())
+	}
+
+	mediumID := c.Items[0].ID
+	if _, err := s.UpdateItemQuantity(ctx, "cart-1", mediumID, 3); !errors.Is(err, ErrQuantityLimitExceeded) {
+		t.Errorf("raise past the limit: error = %v, want %v", err, ErrQuantityLimitExceeded)
+	}
+	if _, err := s.UpdateItemQuantity(ctx, "cart-1", mediumID, 1); err != nil {
+		t.Errorf("lower quantity: %v", err)
+	}
+}
+
+func TestSaveForLaterAndMoveToCart(t *testing.T) {
+	ctx := context.Background()
+	s, repo := newTestService([]*catalog.Product{activeProduct("A", 1000), activeProduct("B", 500)})
+	s.inventoryService = stockService(t, map[string]int{"A": 1, "B": 5})
+	repo.carts["cart-1"].Items = []CartItem{
+		{ID: "a", ProductID: "A", SKU: "A", Price: usd(1000), Quantity: 2},
+		{ID: "b", ProductID: "B", SKU: "B", Price: usd(500), Quantity: 1},
+	}
+
+	c, err := s.SaveForLater(ctx, "cart-1", "a")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(c.Items) != 1 || len(c.SavedItems) != 1 || c.SavedItems[0].Quantity != 2 {
+		t.Fatalf("items = %+v, saved = %+v; want b in the cart and 2 of a saved", c.Items, c.SavedItems)
+	}
+	if c.Subtotal() != usd(500) || c.ItemCount() != 1 {
+		t.Errorf("subtotal = %s, count = %d; want USD 5.00 and 1", c.Subtotal(), c.ItemCount())
+	}
+	if _, err := s.SaveForLater(ctx, "cart-1", "a"); !errors.Is(err, ErrItemNotFound) {
+		t.Errorf("save a saved item: error = %v, want %v", err, ErrItemNotFound)
+	}
+
+	if _, err := s.MoveToCart(ctx, "cart-1", "a"); !errors.Is(err, ErrOutOfStock) {
+		t.Errorf("move back with 1 in stock: error = %v, want %v", err, ErrOutOfStock)
+	}
+	if len(repo.carts["cart-1"].SavedItems) != 1 {
+		t.Error("refused move changed the saved items")
+	}
+
+	s.inventoryService = stockService(t, map[string]int{"A": 5, "B": 5})
+	if _, err := s.AddItem(ctx, "cart-1", AddItemRequest{ProductID: "A", Quantity: 1}); err != nil {
+		t.Fatal(err)
+	}
+	c, err = s.MoveToCart(ctx, "cart-1", "a")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(c.SavedItems) != 0 || len(c.Items) != 2 || c.Items[1].Quantity != 3 {
+		t.Errorf("items = %+v, saved = %+v; want the saved units merged into the A line", c.Items, c.SavedItems)
+	}
+	if _, err := s.MoveToCart(ctx, "cart-1", "a"); !errors.Is(err, ErrItemNotFound) {
+		t.Errorf("move an unsaved item: error = %v, want %v", err, ErrItemNotFound)
+	}
+}
+
+// purgeRepo adds FindExpired to memoryRepo, recording each batch it returns,
+// and fails to delete the cart failDelete.
+type purgeRepo struct {
+	*memoryRepo
+	batches    []int
+	failDelete string
+}
+
+var errDeleteFailed = errors.New("delete failed")
+
+func (r *purgeRepo) FindExpired(ctx context.Context, before time.Time, limit int) ([]string, error) {
+	var expired []*Cart
+	for _, c := range r.carts {
+		if c.ExpiresAt != nil && c.ExpiresAt.Before(before) {
+			expired = append(expired, c)
+		}
+	}
+	sort.Slice(expired, func(i, j int) bool { return expired[i].ExpiresAt.Before(*expired[j].ExpiresAt) })
+	if len(expired) > limit {
+		expired = expired[:limit]
+	}
+	ids := make([]string, len(expired))
+	for i, c := range expired {
+		ids[i] = c.ID
+	}
+	r.batches = append(r.batches, len(ids))
+	return ids, nil
+}
+
+func (r *purgeRepo) Delete(ctx context.Context, id string) error {
+	if id == r.failDelete {
+		return errDeleteFailed
+	}
+	return r.memoryRepo.Delete(ctx, id)
+}
+
+func TestPurgeExpiredWorksInBatches(t *testing.T) {
+	ctx := context.Background()
+	expiring := func(id string, in time.Duration) *Cart {
+		at := time.Now().Add(in)
+		return &Cart{ID: id, ExpiresAt: &at}
+	}
+	carts := func() *memoryRepo {
+		return newMemoryRepo(
+			expiring("exp-1", -5*time.Hour), expiring("exp-2", -4*time.Hour), expiring("exp-3", -3*time.Hour),
+			expiring("exp-4", -2*time.Hour), expiring("exp-5", -time.Hour),
+			expiring("live", time.Hour), &Cart{ID: "forever", UserID: "user-1"},
+		)
+	}
+
+	repo := &purgeRepo{memoryRepo: carts()}
+	s := NewCartService(repo, nil, nil, nil, nil, WithPurgeBatchSize(2))
+	deleted, err := s.PurgeExpired(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if deleted != 5 {
+		t.Errorf("deleted %d carts, want 5", deleted)
+	}
+	if fmt.Sprint(repo.batches) != "[2 2 1]" {
+		t.Errorf("batches = %v, want [2 2 1]", repo.batches)
+	}
+	if len(repo.carts) != 2 || repo.carts["live"] == nil || repo.carts["forever"] == nil {
+		t.Errorf("remaining carts = %v, want live and forever", repo.carts)
+	}
+
+	repo = &purgeRepo{memoryRepo: carts(), failDelete: "exp-3"}
+	s = NewCartService(repo, nil, nil, nil, nil, WithPurgeBatchSize(2))
+	deleted, err = s.PurgeExpired(ctx)
+	if !errors.Is(err, errDeleteFailed) || deleted != 2 {
+		t.Errorf("failed delete = %d, %v; want 2, %v", deleted, err, errDeleteFailed)
+	}
+}
+
+func TestCartLineAndQuantityCaps(t *testing.T) {
+	ctx := context.Background()
+	s, _ := newTestService(
+		[]*catalog.Product{activeProduct("A", 1000), activeProduct("B", 1000), activeProduct("C", 1000)},
+		WithMaxLines(2), WithMaxQuantity(5),
+	)
+	for _, id := range []string{"A", "B"} {
+		if _, err := s.AddItem(ctx, "cart-1", AddItemRequest{ProductID: id, Quantity: 2}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if _, err := s.AddItem(ctx, "cart-1", AddItemRequest{ProductID: "C", Quantity: 1}); !errors.Is(err, ErrCartLimitExceeded) {
+		t.Errorf("third line: error = %v, want %v", err, ErrCartLimitExceeded)
+	}
+	c, err := s.AddItem(ctx, "cart-1", AddItemRequest{ProductID: "A", Quantity: 1})
+	if err != nil {
+		t.Fatalf("existing line under the quantity cap: %v", err)
+	}
+	if c.ItemCount() != 5 {
+		t.Errorf("ItemCount = %d, want 5", c.ItemCount())
+	}
+	if _, err := s.AddItem(ctx, "cart-1", AddItemRequest{ProductID: "A", Quantity: 1}); !errors.Is(err, ErrCartLimitExceeded) {
+		t.Errorf("sixth unit: error = %v, want %v", err, ErrCartLimitExceeded)
+	}
+	if _, err := s.UpdateItemQuantity(ctx, "cart-1", c.Items[1].ID, 3); !errors.Is(err, ErrCartLimitExceeded) {
+		t.Errorf("raise past the quantity cap: error = %v, want %v", err, ErrCartLimitExceeded)
+	}
+	if _, err := s.UpdateItemQuantity(ctx, "cart-1", c.Items[1].ID, 1); err != nil {
+		t.Errorf("lower quantity: %v", err)
+	}
+}
+
+func TestAddItemsIsAllOrNothing(t *testing.T) {
+	ctx := context.Background()
+	hooks := &regionHooks{blocked: "knife"}
+	s, repo := newTestService(
+		[]*catalog.Product{activeProduct("A", 1000), activeProduct("B", 500), activeProduct("knife", 3000)},
+		WithHooks(hooks),
+	)
+	s.inventoryService = stockService(t, map[string]int{"A": 3, "B": 5, "knife": 5})
+
+	failures := []struct {
+		name string
+		reqs []AddItemRequest
+		want error
+	}{
+		{"stock counted across requests", []AddItemRequest{{ProductID: "A", Quantity: 2}, {ProductID: "B", Quantity: 1}, {ProductID: "A", Quantity: 2}}, ErrOutOfStock},
+		{"missing product", []AddItemRequest{{ProductID: "B", Quantity: 1}, {ProductID: "C", Quantity: 1}}, errProductNotFound},
+		{"invalid quantity", []AddItemRequest{{ProductID: "B", Quantity: 1}, {ProductID: "A", Quantity: 0}}, ErrInvalidQuantity},
+		{"hook veto", []AddItemRequest{{ProductID: "B", Quantity: 1}, {ProductID: "knife", Quantity: 1}}, errRestricted},
+	}
+	for _, tt := range failures {
+		t.Run(tt.name, func(t *testing.T) {
+			// The failing request is always the last one in the batch.
+			_, err := s.AddItems(ctx, "cart-1", tt.reqs)
+			if !errors.Is(err, tt.want) {
+				t.Errorf("error = %v, want %v", err, tt.want)
+			} else if prefix := fmt.Sprintf("item %d: ", len(tt.reqs)-1); !strings.HasPrefix(err.Error(), prefix) {
+				t.Errorf("error = %q, want it prefixed %q", err, prefix)
+			}
+			if len(repo.carts["cart-1"].Items) != 0 || len(hooks.added) != 0 {
+				t.Errorf("failed batch left items %+v, hooks saw %v", repo.carts["cart-1"].Items, hooks.added)
+			}
+		})
+	}
+
+	c, err := s.AddItems(ctx, "cart-1", []AddItemRequest{{ProductID: "A", Quantity: 2}, {ProductID: "B", Quantity: 1}, {ProductID: "A", Quantity: 1}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(c.Items) != 2 || c.Items[0].Quantity != 3 || c.Items[1].Quantity != 1 {
+		t.Errorf("items = %+v, want 3 of A and 1 of B", c.Items)
+	}
+	if len(repo.carts["cart-1"].Items) != 2 {
+		t.Error("batch was not saved")
+	}
+	if fmt.Sprint(hooks.added) != "[A B A]" {
+		t.Errorf("AfterAddItem saw %v, want [A B A]", hooks.added)
+	}
+}
@@ -1,6 +1,7 @@
 package catalog
 
 import (
+	"strings"
 	"time"
 
 	"github.com/devchuckcamp/gocommerce/money"
@@ -8,18 +9,25 @@ import (
 
 // Product represents a product in the catalog.
 type Product struct {
-	ID          string
-	SKU         string
-	Name        string
-	Description string
-	BrandID     string
-	CategoryID  string
-	BasePrice   money.Money
-	Status      ProductStatus
-	Images      []string
-	Attributes  map[string]string // e.g., "material": "cotton"
-	CreatedAt   time.Time
-	UpdatedAt   time.Time
+	ID             string
+	SKU            string
+	Name           string
+	Description    string
+	BrandID        string
+	CategoryID     string
+	BasePrice      money.Money
+	Status         ProductStatus
+	Images         []string
+	Attributes     map[string]string // e.g., "material": "cotton"
+	TaxCode        string            // Optional product tax code (e.g., "clothing", "food")
+	WeightGrams    int               // Shipping weight of one unit
+	LengthCm       int
+	WidthCm        int
+	HeightCm       int
+	AllowBackorder bool // Can be ordered while out of stock
+	MaxPerOrder    *int // Optional cap on units in one cart, across variants
+	CreatedAt      time.Time
+	UpdatedAt      time.Time
 }
 
 type ProductStatus string
@@ -40,6 +48,10 @@ type Variant struct {
 	Attributes  map[string]string // e.g., "size": "L", "color": "blue"
 	Images      []string
 	IsAvailable bool
+	WeightGrams int // Overrides the product weight when non-zero
+	LengthCm    int // Dimensions override the product's when any is non-zero
+	WidthCm     int
+	HeightCm    int
 	CreatedAt   time.Time
 	UpdatedAt   time.Time
 }
@@ -82,3 +94,52 @@ func (p *Product) GetEffectivePrice(variant *Variant) money.Money {
 	}
 	return p.BasePrice
 }
+
+// GetEffectiveWeight returns the variant weight if set, otherwise the product weight.
+func (p *Product) GetEffectiveWeight(variant *Variant) int {
+	if variant != nil && variant.WeightGrams > 0 {
+		return variant.WeightGrams
+	}
+	return p.WeightGrams
+}
+
+// GetEffectiveDimensions returns the variant dimensions if any are set,
+// otherwise the product dimensions. Dimensions are never mixed between the two.
+func (p *Product) GetEffectiveDimensions(variant *Variant) (lengthCm, widthCm, heightCm int) {
+	if variant != nil && (variant.LengthCm > 0 || variant.WidthCm > 0 || variant.HeightCm > 0) {
+		return variant.LengthCm, variant.WidthCm, variant.HeightCm
+	}
+	return p.LengthCm, p.WidthCm, p.HeightCm
+}
+
+// DefaultStrategy selects how DefaultVariant picks a variant.
+type DefaultStrategy string
+
+const (
+	DefaultStrategyFirstAvailable DefaultStrategy = "first_available"
+	DefaultStrategyLowestPrice    DefaultStrategy = "lowest_price"
+)
+
+// DefaultVariant picks the variant to preselect on a product page, skipping
+// unavailable ones. LowestPrice keeps the earliest variant on ties and only
+// compares prices in the currency of the first available variant. It returns
+// nil if no variant is available.
+func DefaultVariant(variants []*Variant, strategy DefaultStrategy) *Variant {
+	var chosen *Variant
+	for _, variant := range variants {
+		if variant == nil || !variant.IsAvailable {
+			continue
+		}
+		if chosen == nil {
+			chosen = variant
+			if strategy != DefaultStrategyLowestPrice {
+				break
+			}
+			continue
+		}
+		if strings.EqualFold(variant.Price.Currency, chosen.Price.Currency) && variant.Price.Amount < chosen.Price.Amount {
+			chosen = variant
+		}
+	}
+	return chosen
+}
@@ -0,0 +1,66 @@
+package catalog
+
+import (
+	"testing"
+
+	"github.com/devchuckcamp/gocommerce/money"
+)
+
+func TestEffectiveWeightAndDimensions(t *testing.T) {
+	product := &Product{WeightGrams: 500, LengthCm: 20, WidthCm: 15, HeightCm: 10}
+
+	tests := []struct {
+		name       string
+		variant    *Variant
+		wantWeight int
+		wantDims   [3]int
+	}{
+		{"no variant", nil, 500, [3]int{20, 15, 10}},
+		{"variant without overrides", &Variant{}, 500, [3]int{20, 15, 10}},
+		{"variant weight", &Variant{WeightGrams: 900}, 900, [3]int{20, 15, 10}},
+		{"variant dimensions are not mixed", &Variant{LengthCm: 40}, 500, [3]int{40, 0, 0}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := product.GetEffectiveWeight(tt.variant); got != tt.wantWeight {
+				t.Errorf("weight = %d, want %d", got, tt.wantWeight)
+			}
+			l, w, h := product.GetEffectiveDimensions(tt.variant)
+			if got := [3]int{l, w, h}; got != tt.wantDims {
+				t.Errorf("dimensions = %v, want %v", got, tt.wantDims)
+			}
+		})
+	}
+}
+
+func TestDefaultVariant(t *testing.T) {
+	variant := func(id string, cents int64, currency string, available bool) *Variant {
+		return &Variant{ID: id, Price: money.Money{Amount: cents, Currency: currency}, IsAvailable: available}
+	}
+	variants := []*Variant{
+		variant("sold-out", 500, "USD", false),
+		variant("small", 2000, "USD", true),
+		nil,
+		variant("euro", 100, "EUR", true),
+		variant("medium", 1500, "USD", true),
+		variant("large", 1500, "USD", true),
+	}
+
+	tests := []struct {
+		strategy DefaultStrategy
+		want     string
+	}{
+		{DefaultStrategyFirstAvailable, "small"},
+		{DefaultStrategyLowestPrice, "medium"},
+	}
+	for _, tt := range tests {
+		got := DefaultVariant(variants, tt.strategy)
+		if got == nil || got.ID != tt.want {
+			t.Errorf("%s = %v, want %s", tt.strategy, got, tt.want)
+		}
+	}
+
+	if got := DefaultVariant([]*Variant{variant("sold-out", 500, "USD", false)}, DefaultStrategyLowestPrice); got != nil {
+		t.Errorf("nothing available = %s, want nil", got.ID)
+	}
+}
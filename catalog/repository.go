@@ -2,6 +2,7 @@ package catalog
 
 import (
 	"context"
+	"time"
 )
 
 // ProductRepository defines methods for product persistence.
@@ -10,7 +11,13 @@ type ProductRepository interface {
 	FindBySKU(ctx context.Context, sku string) (*Product, error)
 	FindByCategory(ctx context.Context, categoryID string, filter ProductFilter) ([]*Product, error)
 	FindByBrand(ctx context.Context, brandID string, filter ProductFilter) ([]*Product, error)
-	Search(ctx context.Context, query string, filter ProductFilter) ([]*Product, error)
+	// Search ranks products matching query by relevance. Filter fields narrow
+	// the candidates before ranking, so TotalCount and paging reflect them;
+	// SortBy is ignored in favor of relevance.
+	Search(ctx context.Context, query string, filter ProductFilter) (*SearchResult, error)
+	// FindUpdatedSince returns products modified after since, oldest change first,
+	// for incremental sync (e.g., to a search index). A limit <= 0 means no limit.
+	FindUpdatedSince(ctx context.Context, since time.Time, limit int) ([]*Product, error)
 	Save(ctx context.Context, product *Product) error
 	Delete(ctx context.Context, id string) error
 }
@@ -57,3 +64,17 @@ type ProductFilter struct {
 	Offset       int
 	SortBy       string // e.g., "price_asc", "name", "created_at_desc"
 }
+
+// SearchResult is one page of search hits, most relevant first.
+type SearchResult struct {
+	Hits       []SearchHit
+	TotalCount int // Matches across all pages
+	Limit      int
+	Offset     int
+}
+
+// SearchHit is a matching product and its relevance score (higher is better).
+type SearchHit struct {
+	Product *Product
+	Score   float64
+}
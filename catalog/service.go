@@ -0,0 +1,65 @@
+package catalog
+
+import (
+	"context"
+	"time"
+)
+
+// Service provides catalog business logic.
+type Service interface {
+	ArchiveBrand(ctx context.Context, brandID string, cascade bool) (*Brand, error)
+}
+
+// CatalogService implements the Service interface.
+type CatalogService struct {
+	productRepo ProductRepository
+	brandRepo   BrandRepository
+}
+
+// NewCatalogService creates a new catalog service.
+func NewCatalogService(productRepo ProductRepository, brandRepo BrandRepository) *CatalogService {
+	return &CatalogService{
+		productRepo: productRepo,
+		brandRepo:   brandRepo,
+	}
+}
+
+// ArchiveBrand deactivates a brand instead of deleting it, so its products
+// keep a valid BrandID. With cascade, the brand's products are also marked
+// discontinued; otherwise they are left unchanged. The brand is saved first,
+// so if a product fails to save the brand stays archived and ArchiveBrand can
+// be retried.
+func (s *CatalogService) ArchiveBrand(ctx context.Context, brandID string, cascade bool) (*Brand, error) {
+	brand, err := s.brandRepo.FindByID(ctx, brandID)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	brand.IsActive = false
+	brand.UpdatedAt = now
+	if err := s.brandRepo.Save(ctx, brand); err != nil {
+		return nil, err
+	}
+
+	if !cascade {
+		return brand, nil
+	}
+
+	products, err := s.productRepo.FindByBrand(ctx, brandID, ProductFilter{})
+	if err != nil {
+		return nil, err
+	}
+	for _, product := range products {
+		if product.Status == ProductStatusDiscontinued {
+			continue
+		}
+		product.Status = ProductStatusDiscontinued
+		product.UpdatedAt = now
+		if err := s.productRepo.Save(ctx, product); err != nil {
+			return nil, err
+		}
+	}
+
+	return brand, nil
+}
@@ -0,0 +1,104 @@
+package catalog
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+var errNotFound = errors.New("not found")
+
+// productRepo is an in-memory ProductRepository covering what
+// CatalogService uses.
+type productRepo struct {
+	ProductRepository
+	products map[string]*Product
+	saves    int
+}
+
+func (r *productRepo) FindByBrand(ctx context.Context, brandID string, filter ProductFilter) ([]*Product, error) {
+	var result []*Product
+	for _, p := range r.products {
+		if p.BrandID == brandID {
+			copied := *p
+			result = append(result, &copied)
+		}
+	}
+	return result, nil
+}
+
+func (r *productRepo) Save(ctx context.Context, p *Product) error {
+	r.saves++
+	copied := *p
+	r.products[p.ID] = &copied
+	return nil
+}
+
+// brandRepo is an in-memory BrandRepository covering what CatalogService uses.
+type brandRepo struct {
+	BrandRepository
+	brands map[string]*Brand
+}
+
+func (r *brandRepo) FindByID(ctx context.Context, id string) (*Brand, error) {
+	b, ok := r.brands[id]
+	if !ok {
+		return nil, errNotFound
+	}
+	copied := *b
+	return &copied, nil
+}
+
+func (r *brandRepo) Save(ctx context.Context, b *Brand) error {
+	copied := *b
+	r.brands[b.ID] = &copied
+	return nil
+}
+
+func TestArchiveBrand(t *testing.T) {
+	ctx := context.Background()
+	newRepos := func() (*productRepo, *brandRepo) {
+		products := &productRepo{products: map[string]*Product{
+			"p1": {ID: "p1", BrandID: "acme", Status: ProductStatusActive},
+			"p2": {ID: "p2", BrandID: "acme", Status: ProductStatusDiscontinued},
+			"p3": {ID: "p3", BrandID: "globex", Status: ProductStatusActive},
+		}}
+		brands := &brandRepo{brands: map[string]*Brand{"acme": {ID: "acme", IsActive: true}}}
+		return products, brands
+	}
+
+	t.Run("without cascade", func(t *testing.T) {
+		products, brands := newRepos()
+		brand, err := NewCatalogService(products, brands).ArchiveBrand(ctx, "acme", false)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if brand.IsActive || brands.brands["acme"].IsActive {
+			t.Error("brand still active")
+		}
+		if products.products["p1"].Status != ProductStatusActive || products.saves != 0 {
+			t.Errorf("products changed: p1 %s, %d saves", products.products["p1"].Status, products.saves)
+		}
+	})
+
+	t.Run("with cascade", func(t *testing.T) {
+		products, brands := newRepos()
+		if _, err := NewCatalogService(products, brands).ArchiveBrand(ctx, "acme", true); err != nil {
+			t.Fatal(err)
+		}
+		if products.products["p1"].Status != ProductStatusDiscontinued {
+			t.Errorf("p1 status = %s, want discontinued", products.products["p1"].Status)
+		}
+		if products.products["p3"].Status != ProductStatusActive {
+			t.Errorf("other brand's product status = %s, want active", products.products["p3"].Status)
+		}
+		if products.saves != 1 {
+			t.Errorf("saved %d products, want only the active one", products.saves)
+		}
+	})
+
+	products, brands := newRepos()
+	if _, err := NewCatalogService(products, brands).ArchiveBrand(ctx, "missing", true); !errors.Is(err, errNotFound) {
+		t.Errorf("missing brand: error = %v, want %v", err, errNotFound)
+	}
+}
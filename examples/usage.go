@@ -237,11 +237,17 @@ func ExampleMergeGuestCart(
 	}
 
 	// Merge guest cart into user cart
-	mergedCart, err := cartService.MergeCarts(ctx, guestCart.ID, userCart.ID)
+	mergedCart, decisions, err := cartService.MergeCarts(ctx, guestCart.ID, userCart.ID)
 	if err != nil {
 		return fmt.Errorf("failed to merge carts: %w", err)
 	}
 
+	for _, decision := range decisions {
+		if decision.Action == cart.MergeActionSkipped {
+			fmt.Printf("Skipped %s: priced in %s\n", decision.SKU, decision.Price.Currency)
+		}
+	}
+
 	fmt.Printf("Merged guest cart into user cart. Total items: %d\n",
 		mergedCart.ItemCount())
 
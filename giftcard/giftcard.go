@@ -0,0 +1,55 @@
+package giftcard
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/devchuckcamp/gocommerce/money"
+)
+
+var (
+	ErrGiftCardNotFound    = errors.New("gift card not found")
+	ErrGiftCardInactive    = errors.New("gift card is inactive")
+	ErrInsufficientBalance = errors.New("insufficient gift card balance")
+	ErrInvalidAmount       = errors.New("invalid redemption amount")
+)
+
+// GiftCard represents a stored-value card that can be used as a tender.
+type GiftCard struct {
+	ID             string
+	Code           string      // Code entered by the customer
+	InitialBalance money.Money // Value when issued
+	Balance        money.Money // Remaining value
+	IsActive       bool
+	CreatedAt      time.Time
+	UpdatedAt      time.Time
+}
+
+// CanRedeem checks if amount can be redeemed from the card.
+func (g *GiftCard) CanRedeem(amount money.Money) error {
+	if !g.IsActive {
+		return ErrGiftCardInactive
+	}
+	if !amount.IsPositive() {
+		return ErrInvalidAmount
+	}
+	isLess, err := g.Balance.LessThan(amount)
+	if err != nil {
+		return err
+	}
+	if isLess {
+		return ErrInsufficientBalance
+	}
+	return nil
+}
+
+// Repository defines methods for gift card persistence.
+type Repository interface {
+	FindByCode(ctx context.Context, code string) (*GiftCard, error)
+	Save(ctx context.Context, card *GiftCard) error
+	// Debit atomically subtracts amount from the card balance and returns the
+	// updated card. Implementations must refuse (ErrInsufficientBalance) rather
+	// than let the balance go negative, e.g. with a conditional UPDATE.
+	Debit(ctx context.Context, code string, amount money.Money) (*GiftCard, error)
+}
@@ -0,0 +1,56 @@
+package giftcard
+
+import (
+	"context"
+
+	"github.com/devchuckcamp/gocommerce/money"
+)
+
+// Service provides gift card business logic.
+type Service interface {
+	GetBalance(ctx context.Context, code string) (money.Money, error)
+	Redeem(ctx context.Context, code string, amount money.Money) (money.Money, error)
+}
+
+// GiftCardService implements the Service interface.
+type GiftCardService struct {
+	repo Repository
+}
+
+// NewGiftCardService creates a new gift card service.
+func NewGiftCardService(repo Repository) *GiftCardService {
+	return &GiftCardService{
+		repo: repo,
+	}
+}
+
+// GetBalance returns the remaining balance of a gift card.
+func (s *GiftCardService) GetBalance(ctx context.Context, code string) (money.Money, error) {
+	card, err := s.repo.FindByCode(ctx, code)
+	if err != nil {
+		return money.Money{}, err
+	}
+	return card.Balance, nil
+}
+
+// Redeem deducts amount from the gift card and returns the remaining balance.
+// Use it to cover part of an order total, charging the rest to another tender.
+func (s *GiftCardService) Redeem(ctx context.Context, code string, amount money.Money) (money.Money, error) {
+	card, err := s.repo.FindByCode(ctx, code)
+	if err != nil {
+		return money.Money{}, err
+	}
+
+	if err := card.CanRedeem(amount); err != nil {
+		return money.Money{}, err
+	}
+
+	// The balance check above is advisory; Debit re-checks atomically so two
+	// concurrent redemptions can't overdraw the card.
+	updated, err := s.repo.Debit(ctx, code, amount)
+	if err != nil {
+		return money.Money{}, err
+	}
+
+	return updated.Balance, nil
+}
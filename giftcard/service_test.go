@@ -0,0 +1,88 @@
+package giftcard
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/devchuckcamp/gocommerce/money"
+)
+
+// memoryRepo is an in-memory Repository.
+type memoryRepo struct {
+	cards map[string]*GiftCard
+}
+
+func (r *memoryRepo) FindByCode(ctx context.Context, code string) (*GiftCard, error) {
+	card, ok := r.cards[code]
+	if !ok {
+		return nil, ErrGiftCardNotFound
+	}
+	copied := *card
+	return &copied, nil
+}
+
+func (r *memoryRepo) Save(ctx context.Context, card *GiftCard) error {
+	copied := *card
+	r.cards[card.Code] = &copied
+	return nil
+}
+
+func (r *memoryRepo) Debit(ctx context.Context, code string, amount money.Money) (*GiftCard, error) {
+	card, ok := r.cards[code]
+	if !ok {
+		return nil, ErrGiftCardNotFound
+	}
+	if card.Balance.Amount < amount.Amount {
+		return nil, ErrInsufficientBalance
+	}
+	card.Balance.Amount -= amount.Amount
+	copied := *card
+	return &copied, nil
+}
+
+func usd(cents int64) money.Money {
+	return money.Money{Amount: cents, Currency: "USD"}
+}
+
+func TestRedeem(t *testing.T) {
+	ctx := context.Background()
+	repo := &memoryRepo{cards: map[string]*GiftCard{
+		"GIFT-50": {Code: "GIFT-50", InitialBalance: usd(5000), Balance: usd(5000), IsActive: true},
+		"OLD":     {Code: "OLD", Balance: usd(5000)},
+	}}
+	s := NewGiftCardService(repo)
+
+	remaining, err := s.Redeem(ctx, "GIFT-50", usd(3000))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if remaining.Amount != 2000 {
+		t.Errorf("remaining = %s, want USD 20.00", remaining)
+	}
+	if balance, _ := s.GetBalance(ctx, "GIFT-50"); balance.Amount != 2000 {
+		t.Errorf("balance = %s, want USD 20.00", balance)
+	}
+
+	tests := []struct {
+		name   string
+		code   string
+		amount money.Money
+		want   error
+	}{
+		{"more than the balance", "GIFT-50", usd(2001), ErrInsufficientBalance},
+		{"zero amount", "GIFT-50", usd(0), ErrInvalidAmount},
+		{"inactive card", "OLD", usd(100), ErrGiftCardInactive},
+		{"unknown code", "NOPE", usd(100), ErrGiftCardNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := s.Redeem(ctx, tt.code, tt.amount); !errors.Is(err, tt.want) {
+				t.Errorf("error = %v, want %v", err, tt.want)
+			}
+		})
+	}
+	if balance, _ := s.GetBalance(ctx, "GIFT-50"); balance.Amount != 2000 {
+		t.Errorf("balance after failed redemptions = %s, want USD 20.00", balance)
+	}
+}
@@ -3,32 +3,85 @@ package inventory
 import (
 	"context"
 	"errors"
+	"time"
 )
 
 var (
-	ErrInsufficientStock = errors.New("insufficient stock")
-	ErrInvalidSKU        = errors.New("invalid SKU")
-	ErrReservationFailed = errors.New("reservation failed")
+	ErrInsufficientStock    = errors.New("insufficient stock")
+	ErrInvalidSKU           = errors.New("invalid SKU")
+	ErrReservationFailed    = errors.New("reservation failed")
+	ErrReservationNotActive = errors.New("no active reservation to extend")
 )
 
 // Service defines the inventory service interface.
 type Service interface {
+	// GetAvailableStock sums the available stock of sku across warehouses.
 	GetAvailableStock(ctx context.Context, sku string) (int, error)
+	GetAvailableStockByWarehouse(ctx context.Context, sku, warehouseID string) (int, error)
 	GetReservedStock(ctx context.Context, sku string) (int, error)
+	// Reserve holds quantity units of sku for referenceID, drawing from
+	// warehouses in the order chosen by a WarehouseStrategy. Repeating it
+	// with the same referenceID and sku sets the reservation rather than
+	// adding to it, so retries don't reserve twice.
 	Reserve(ctx context.Context, sku string, quantity int, referenceID string) error
+	// ReserveFromWarehouse is Reserve pinned to a single warehouse.
+	ReserveFromWarehouse(ctx context.Context, sku, warehouseID string, quantity int, referenceID string) error
+	// ReserveAllowingBackorder is Reserve for products that accept
+	// backorders: units beyond what is available are reserved anyway,
+	// leaving available stock negative. It returns how many of the reserved
+	// units are backordered.
+	ReserveAllowingBackorder(ctx context.Context, sku string, quantity int, referenceID string) (backordered int, err error)
+	// Release returns referenceID's reserved stock of sku to the available
+	// pool; a quantity of zero releases all of it. An empty sku releases every
+	// active reservation referenceID holds (e.g., when its order is canceled).
 	Release(ctx context.Context, sku string, quantity int, referenceID string) error
 	Commit(ctx context.Context, referenceID string) error
 	AdjustStock(ctx context.Context, sku string, quantity int, reason string) error
+	// AdjustStockBatch applies all adjustments or none of them (e.g., receiving
+	// a purchase order) and returns how many were applied.
+	AdjustStockBatch(ctx context.Context, adjustments []StockAdjustment) (int, error)
+	ExtendReservation(ctx context.Context, referenceID string, ttl time.Duration) error
+	// ListReservations returns every reservation held by referenceID, in any status.
+	ListReservations(ctx context.Context, referenceID string) ([]*Reservation, error)
+	// GetReservedStockByReference sums referenceID's active reservations per SKU.
+	GetReservedStockByReference(ctx context.Context, referenceID string) (map[string]int, error)
+	// CheckAvailability checks many SKUs in one call (e.g., every cart line
+	// before checkout). It returns the available quantity per SKU and a
+	// shortage for each SKU with less available than requested.
+	CheckAvailability(ctx context.Context, items []StockRequest) (map[string]int, []Shortage, error)
+	// ReleaseExpired returns the stock of every expired active reservation
+	// to the available pool and marks it expired. It is safe to run
+	// repeatedly (e.g., from a cron job).
+	ReleaseExpired(ctx context.Context) (released int, err error)
+	// GetAdjustmentHistory returns the adjustments recorded for sku from
+	// from (inclusive) to to (exclusive), oldest first. A zero time leaves
+	// that end of the range open.
+	GetAdjustmentHistory(ctx context.Context, sku string, from, to time.Time) ([]*StockAdjustment, error)
 }
 
-// StockLevel represents inventory stock information.
+// StockRequest is a quantity of a SKU to check availability for.
+type StockRequest struct {
+	SKU      string
+	Quantity int
+}
+
+// Shortage reports a SKU with less stock available than was requested.
+type Shortage struct {
+	SKU       string
+	Requested int
+	Available int
+}
+
+// StockLevel represents inventory stock information for a SKU in one
+// warehouse.
 type StockLevel struct {
-	SKU              string
-	QuantityOnHand   int
-	QuantityReserved int
+	SKU               string
+	WarehouseID       string // Empty for the default warehouse
+	QuantityOnHand    int
+	QuantityReserved  int
 	QuantityAvailable int
-	ReorderPoint     int
-	ReorderQuantity  int
+	ReorderPoint      int
+	ReorderQuantity   int
 }
 
 // IsInStock returns true if the SKU has available stock.
@@ -45,7 +98,9 @@ func (s *StockLevel) NeedsReorder() bool {
 type Reservation struct {
 	ID          string
 	SKU         string
+	WarehouseID string // Warehouse the stock is held in
 	Quantity    int
+	Backordered int    // Units of Quantity not covered by stock when reserved
 	ReferenceID string // Order ID, cart ID, etc.
 	Status      ReservationStatus
 	ExpiresAt   int64 // Unix timestamp
@@ -63,21 +118,45 @@ const (
 
 // Repository defines methods for inventory persistence.
 type Repository interface {
+	// GetStockLevel returns the stock level of sku in the default warehouse.
 	GetStockLevel(ctx context.Context, sku string) (*StockLevel, error)
+	GetWarehouseStockLevel(ctx context.Context, sku, warehouseID string) (*StockLevel, error)
+	// ListStockLevels returns the stock level of sku in every warehouse,
+	// ordered by WarehouseID.
+	ListStockLevels(ctx context.Context, sku string) ([]*StockLevel, error)
+	// UpdateStockLevel saves level under its SKU and WarehouseID.
 	UpdateStockLevel(ctx context.Context, level *StockLevel) error
 	GetReservation(ctx context.Context, id string) (*Reservation, error)
 	GetReservationsByReference(ctx context.Context, referenceID string) ([]*Reservation, error)
 	SaveReservation(ctx context.Context, reservation *Reservation) error
 	DeleteReservation(ctx context.Context, id string) error
 	GetExpiredReservations(ctx context.Context) ([]*Reservation, error)
+	SaveAdjustment(ctx context.Context, adjustment *StockAdjustment) error
+	// GetAdjustments returns the adjustments for sku created from from
+	// (inclusive) to to (exclusive), oldest first. A zero time leaves that
+	// end of the range open.
+	GetAdjustments(ctx context.Context, sku string, from, to time.Time) ([]*StockAdjustment, error)
 }
 
 // StockAdjustment represents a stock level change.
 type StockAdjustment struct {
-	ID         string
-	SKU        string
-	Quantity   int    // Positive for increase, negative for decrease
-	Reason     string // e.g., "restock", "damage", "correction"
-	ReferenceID string
-	CreatedAt  int64
+	ID               string
+	SKU              string
+	WarehouseID      string // Empty for the default warehouse
+	Quantity         int    // Change in on-hand stock: positive for increase, negative for decrease
+	ReservedQuantity int    // Change in reserved stock, e.g., negative when a reservation is released
+	Reason           string // e.g., "restock", "damage", "correction"
+	ReferenceID      string
+	CreatedAt        int64
 }
+
+// Reasons recorded by the service itself. Commit entries decrease both on-hand
+// and reserved stock; release and expiry entries have a Quantity of zero, since
+// on-hand stock is unchanged, and a negative ReservedQuantity for the units
+// returned to the available pool. Summing Quantity over a SKU's history
+// therefore reconciles to its on-hand stock.
+const (
+	AdjustmentReasonCommit  = "commit"
+	AdjustmentReasonRelease = "release"
+	AdjustmentReasonExpired = "reservation_expired"
+)
@@ -0,0 +1,860 @@
+package inventory
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+)
+
+var (
+	ErrReservationNotFound = errors.New("reservation not found")
+)
+
+// DefaultReservationTTL is how long a reservation holds stock before it expires.
+const DefaultReservationTTL = 15 * time.Minute
+
+// MemoryService implements the Service interface on top of a Repository.
+//
+// Every stock mutation runs under a single mutex, so reading the available
+// quantity and reserving against it happen as one compare-and-decrement.
+// Two goroutines can never both observe the last unit and both reserve it.
+type MemoryService struct {
+	repo     Repository
+	ttl      time.Duration
+	strategy WarehouseStrategy
+	mu       sync.Mutex
+}
+
+// Option configures optional MemoryService behavior.
+type Option func(*MemoryService)
+
+// WithWarehouseStrategy sets how Reserve chooses warehouses. The default is
+// MostStockStrategy.
+func WithWarehouseStrategy(strategy WarehouseStrategy) Option {
+	return func(s *MemoryService) {
+		s.strategy = strategy
+	}
+}
+
+// NewMemoryService creates a new inventory service backed by repo.
+func NewMemoryService(repo Repository, opts ...Option) *MemoryService {
+	s := &MemoryService{
+		repo:     repo,
+		ttl:      DefaultReservationTTL,
+		strategy: MostStockStrategy{},
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
+}
+
+// GetAvailableStock returns the quantity that can still be reserved, summed
+// across warehouses.
+func (s *MemoryService) GetAvailableStock(ctx context.Context, sku string) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	levels, err := s.stockLevels(ctx, sku)
+	if err != nil {
+		return 0, err
+	}
+	available, _ := sumLevels(levels)
+	return available, nil
+}
+
+// GetAvailableStockByWarehouse returns the quantity of sku that can still be
+// reserved in one warehouse.
+func (s *MemoryService) GetAvailableStockByWarehouse(ctx context.Context, sku, warehouseID string) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	level, err := s.repo.GetWarehouseStockLevel(ctx, sku, warehouseID)
+	if err != nil {
+		return 0, err
+	}
+	return level.QuantityAvailable, nil
+}
+
+// GetReservedStock returns the quantity currently held by active reservations,
+// summed across warehouses.
+func (s *MemoryService) GetReservedStock(ctx context.Context, sku string) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	levels, err := s.stockLevels(ctx, sku)
+	if err != nil {
+		return 0, err
+	}
+	_, reserved := sumLevels(levels)
+	return reserved, nil
+}
+
+// Reserve holds quantity units of sku for referenceID.
+// It fails with ErrInsufficientStock rather than reserving more than is available.
+//
+// Stock is drawn from warehouses in the order the WarehouseStrategy ranks
+// them, so a reservation may be split across several warehouses (one
+// Reservation each) when no single warehouse has enough.
+//
+// Reserve is idempotent per referenceID and sku: it sets the reservation to
+// quantity rather than adding to it, so a retried call holds no extra stock.
+// Calling it again with a different quantity reserves or frees only the
+// difference, freeing from the least preferred warehouse first. Either way
+// the reservation's expiry is renewed.
+func (s *MemoryService) Reserve(ctx context.Context, sku string, quantity int, referenceID string) error {
+	_, err := s.reserve(ctx, sku, quantity, referenceID, s.strategy, false)
+	return err
+}
+
+// ReserveFromWarehouse holds quantity units of sku in warehouseID for
+// referenceID. It behaves like Reserve restricted to that warehouse, and is
+// idempotent per referenceID, sku, and warehouse.
+func (s *MemoryService) ReserveFromWarehouse(ctx context.Context, sku, warehouseID string, quantity int, referenceID string) error {
+	_, err := s.reserve(ctx, sku, quantity, referenceID, singleWarehouse(warehouseID), false)
+	return err
+}
+
+// ReserveAllowingBackorder holds quantity units of sku for referenceID like
+// Reserve, but never fails for lack of stock: whatever the warehouses can't
+// cover is reserved in the most preferred one, taking its available quantity
+// below zero. It returns how many units of the reservation are backordered.
+// Shrinking the reservation frees backordered units first.
+func (s *MemoryService) ReserveAllowingBackorder(ctx context.Context, sku string, quantity int, referenceID string) (int, error) {
+	return s.reserve(ctx, sku, quantity, referenceID, s.strategy, true)
+}
+
+func (s *MemoryService) reserve(ctx context.Context, sku string, quantity int, referenceID string, strategy WarehouseStrategy, allowBackorder bool) (int, error) {
+	if quantity <= 0 || referenceID == "" {
+		return 0, ErrReservationFailed
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	levels, err := s.stockLevels(ctx, sku)
+	if err != nil {
+		return 0, err
+	}
+
+	reservations, err := s.repo.GetReservationsByReference(ctx, referenceID)
+	if err != nil {
+		return 0, err
+	}
+	active := make(map[string]*Reservation)
+	for _, reservation := range reservations {
+		if reservation.Status == ReservationStatusActive && reservation.SKU == sku {
+			active[reservation.WarehouseID] = reservation
+		}
+	}
+
+	// Rank as if referenceID held nothing yet, so a repeated call sees the
+	// warehouses in the same order as the first one did.
+	adjustHeld := func(sign int) {
+		for _, level := range levels {
+			if reservation, ok := active[level.WarehouseID]; ok {
+				level.QuantityAvailable += sign * reservation.Quantity
+			}
+		}
+	}
+	adjustHeld(1)
+	ranked := strategy.Rank(sku, levels)
+	adjustHeld(-1)
+	if len(ranked) == 0 {
+		return 0, ErrInvalidSKU
+	}
+
+	held := 0
+	var current []*Reservation
+	for _, level := range ranked {
+		if reservation, ok := active[level.WarehouseID]; ok {
+			current = append(current, reservation)
+			held += reservation.Quantity
+		}
+	}
+
+	if quantity < held {
+		// Free from the least preferred warehouse first.
+		for i, j := 0, len(current)-1; i < j; i, j = i+1, j-1 {
+			current[i], current[j] = current[j], current[i]
+		}
+		if err := s.releaseFrom(ctx, current, held-quantity); err != nil {
+			return 0, err
+		}
+	}
+
+	if need := quantity - held; need > 0 {
+		available, _ := sumLevels(ranked)
+		if available < need && !allowBackorder {
+			return 0, ErrInsufficientStock
+		}
+
+		for _, level := range ranked {
+			take := level.QuantityAvailable
+			if take > need {
+				take = need
+			}
+			if take <= 0 {
+				continue
+			}
+
+			level.QuantityReserved += take
+			refreshAvailable(level)
+			if err := s.repo.UpdateStockLevel(ctx, level); err != nil {
+				return 0, err
+			}
+
+			hold(active, level, referenceID).Quantity += take
+
+			need -= take
+			if need == 0 {
+				break
+			}
+		}
+
+		if need > 0 {
+			// Backorder the rest from the most preferred warehouse.
+			level := ranked[0]
+			level.QuantityReserved += need
+			refreshAvailable(level)
+			if err := s.repo.UpdateStockLevel(ctx, level); err != nil {
+				return 0, err
+			}
+
+			reservation := hold(active, level, referenceID)
+			reservation.Quantity += need
+			reservation.Backordered += need
+		}
+	}
+
+	expiresAt := time.Now().Add(s.ttl).Unix()
+	backordered := 0
+	for _, level := range ranked {
+		reservation, ok := active[level.WarehouseID]
+		if !ok || reservation.Status != ReservationStatusActive {
+			continue
+		}
+		reservation.ExpiresAt = expiresAt
+		if err := s.repo.SaveReservation(ctx, reservation); err != nil {
+			return 0, err
+		}
+		backordered += reservation.Backordered
+	}
+	return backordered, nil
+}
+
+// hold returns referenceID's active reservation in level's warehouse,
+// starting an empty one if there is none.
+func hold(active map[string]*Reservation, level *StockLevel, referenceID string) *Reservation {
+	reservation, ok := active[level.WarehouseID]
+	if !ok {
+		reservation = &Reservation{
+			ID:          reservationID(referenceID, level.SKU, level.WarehouseID),
+			SKU:         level.SKU,
+			WarehouseID: level.WarehouseID,
+			ReferenceID: referenceID,
+			Status:      ReservationStatusActive,
+		}
+		active[level.WarehouseID] = reservation
+	}
+	return reservation
+}
+
+// Release returns reserved stock for referenceID to the available pool.
+// An empty sku releases every active reservation held by referenceID, and a
+// quantity of zero releases the whole reservation rather than part of it.
+// A partial release applies per SKU, however many warehouses hold it.
+func (s *MemoryService) Release(ctx context.Context, sku string, quantity int, referenceID string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	reservations, err := s.repo.GetReservationsByReference(ctx, referenceID)
+	if err != nil {
+		return err
+	}
+
+	bySKU := make(map[string][]*Reservation)
+	var order []string
+	for _, reservation := range reservations {
+		if reservation.Status != ReservationStatusActive {
+			continue
+		}
+		if sku != "" && reservation.SKU != sku {
+			continue
+		}
+		if _, ok := bySKU[reservation.SKU]; !ok {
+			order = append(order, reservation.SKU)
+		}
+		bySKU[reservation.SKU] = append(bySKU[reservation.SKU], reservation)
+	}
+
+	for _, reservedSKU := range order {
+		if err := s.releaseFrom(ctx, bySKU[reservedSKU], quantity); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// releaseFrom frees up to quantity units (all of them if quantity is zero)
+// from reservations in order, returning the stock to each reservation's
+// warehouse. Reservations left empty are marked released.
+func (s *MemoryService) releaseFrom(ctx context.Context, reservations []*Reservation, quantity int) error {
+	remaining := quantity
+	for _, reservation := range reservations {
+		released := reservation.Quantity
+		if quantity > 0 {
+			if remaining == 0 {
+				break
+			}
+			if remaining < released {
+				released = remaining
+			}
+			remaining -= released
+		}
+
+		level, err := s.repo.GetWarehouseStockLevel(ctx, reservation.SKU, reservation.WarehouseID)
+		if err != nil {
+			return err
+		}
+		level.QuantityReserved -= released
+		refreshAvailable(level)
+		if err := s.repo.UpdateStockLevel(ctx, level); err != nil {
+			return err
+		}
+
+		// Backordered units are the first to go.
+		reservation.Quantity -= released
+		reservation.Backordered -= released
+		if reservation.Backordered < 0 {
+			reservation.Backordered = 0
+		}
+		if reservation.Quantity == 0 {
+			reservation.Status = ReservationStatusReleased
+		}
+		if err := s.repo.SaveReservation(ctx, reservation); err != nil {
+			return err
+		}
+		if err := s.recordAdjustment(ctx, reservation, 0, -released, AdjustmentReasonRelease); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Commit converts the active reservations of referenceID into stock decrements.
+func (s *MemoryService) Commit(ctx context.Context, referenceID string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	reservations, err := s.repo.GetReservationsByReference(ctx, referenceID)
+	if err != nil {
+		return err
+	}
+
+	for _, reservation := range reservations {
+		if reservation.Status != ReservationStatusActive {
+			continue
+		}
+
+		level, err := s.repo.GetWarehouseStockLevel(ctx, reservation.SKU, reservation.WarehouseID)
+		if err != nil {
+			return err
+		}
+		level.QuantityOnHand -= reservation.Quantity
+		level.QuantityReserved -= reservation.Quantity
+		refreshAvailable(level)
+		if err := s.repo.UpdateStockLevel(ctx, level); err != nil {
+			return err
+		}
+
+		reservation.Status = ReservationStatusCommitted
+		if err := s.repo.SaveReservation(ctx, reservation); err != nil {
+			return err
+		}
+		if err := s.recordAdjustment(ctx, reservation, -reservation.Quantity, -reservation.Quantity, AdjustmentReasonCommit); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// ExtendReservation pushes the expiry of every active reservation held by
+// referenceID to at least ttl from now (e.g., while a customer is on the
+// payment page). Reservations that have already expired, been committed, or
+// been released are left alone; if none can be extended, it returns
+// ErrReservationNotActive.
+func (s *MemoryService) ExtendReservation(ctx context.Context, referenceID string, ttl time.Duration) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	reservations, err := s.repo.GetReservationsByReference(ctx, referenceID)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	expiresAt := now.Add(ttl).Unix()
+	extended := 0
+	for _, reservation := range reservations {
+		if reservation.Status != ReservationStatusActive || reservation.ExpiresAt <= now.Unix() {
+			continue
+		}
+		if expiresAt > reservation.ExpiresAt {
+			reservation.ExpiresAt = expiresAt
+			if err := s.repo.SaveReservation(ctx, reservation); err != nil {
+				return err
+			}
+		}
+		extended++
+	}
+
+	if extended == 0 {
+		return ErrReservationNotActive
+	}
+	return nil
+}
+
+// ReleaseExpired releases every active reservation past its expiry and
+// marks it ReservationStatusExpired, returning how many were released.
+// Reservations already released, committed, or expired are left alone, so
+// repeated calls are harmless.
+func (s *MemoryService) ReleaseExpired(ctx context.Context) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	expired, err := s.repo.GetExpiredReservations(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	released := 0
+	for _, reservation := range expired {
+		if reservation.Status != ReservationStatusActive {
+			continue
+		}
+
+		level, err := s.repo.GetWarehouseStockLevel(ctx, reservation.SKU, reservation.WarehouseID)
+		if err != nil {
+			return released, err
+		}
+		level.QuantityReserved -= reservation.Quantity
+		refreshAvailable(level)
+		if err := s.repo.UpdateStockLevel(ctx, level); err != nil {
+			return released, err
+		}
+
+		reservation.Status = ReservationStatusExpired
+		if err := s.repo.SaveReservation(ctx, reservation); err != nil {
+			return released, err
+		}
+		if err := s.recordAdjustment(ctx, reservation, 0, -reservation.Quantity, AdjustmentReasonExpired); err != nil {
+			return released, err
+		}
+		released++
+	}
+	return released, nil
+}
+
+// GetAdjustmentHistory returns the adjustments recorded for sku in the time
+// range, oldest first. This includes the commits, releases, and expiries the
+// service records itself.
+func (s *MemoryService) GetAdjustmentHistory(ctx context.Context, sku string, from, to time.Time) ([]*StockAdjustment, error) {
+	if sku == "" {
+		return nil, ErrInvalidSKU
+	}
+	return s.repo.GetAdjustments(ctx, sku, from, to)
+}
+
+// ListReservations returns every reservation held by referenceID, ordered by ID.
+func (s *MemoryService) ListReservations(ctx context.Context, referenceID string) ([]*Reservation, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.repo.GetReservationsByReference(ctx, referenceID)
+}
+
+// GetReservedStockByReference sums referenceID's active reservations per SKU.
+// SKUs with nothing actively reserved are omitted.
+func (s *MemoryService) GetReservedStockByReference(ctx context.Context, referenceID string) (map[string]int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	reservations, err := s.repo.GetReservationsByReference(ctx, referenceID)
+	if err != nil {
+		return nil, err
+	}
+
+	reserved := make(map[string]int)
+	for _, reservation := range reservations {
+		if reservation.Status == ReservationStatusActive {
+			reserved[reservation.SKU] += reservation.Quantity
+		}
+	}
+	return reserved, nil
+}
+
+// CheckAvailability reads every SKU under one lock, so the result is a
+// consistent snapshot. Requests for the same SKU are summed, stock is summed
+// across warehouses, and unknown SKUs count as having nothing available.
+// Shortages follow the order in which each SKU first appears in items.
+func (s *MemoryService) CheckAvailability(ctx context.Context, items []StockRequest) (map[string]int, []Shortage, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	requested := make(map[string]int)
+	var order []string
+	for _, item := range items {
+		if item.SKU == "" {
+			return nil, nil, ErrInvalidSKU
+		}
+		if _, ok := requested[item.SKU]; !ok {
+			order = append(order, item.SKU)
+		}
+		requested[item.SKU] += item.Quantity
+	}
+
+	available := make(map[string]int, len(order))
+	var shortages []Shortage
+	for _, sku := range order {
+		levels, err := s.repo.ListStockLevels(ctx, sku)
+		if err != nil {
+			return nil, nil, err
+		}
+		quantity, _ := sumLevels(levels)
+		available[sku] = quantity
+		if quantity < requested[sku] {
+			shortages = append(shortages, Shortage{
+				SKU:       sku,
+				Requested: requested[sku],
+				Available: quantity,
+			})
+		}
+	}
+	return available, shortages, nil
+}
+
+// AdjustStock changes the on-hand quantity of sku in the default warehouse by
+// quantity (negative to decrease).
+// Unknown SKUs are created on their first positive adjustment.
+func (s *MemoryService) AdjustStock(ctx context.Context, sku string, quantity int, reason string) error {
+	_, err := s.AdjustStockBatch(ctx, []StockAdjustment{{
+		SKU:      sku,
+		Quantity: quantity,
+		Reason:   reason,
+	}})
+	return err
+}
+
+// AdjustStockBatch applies adjustments in order as one unit: every resulting
+// level is checked before anything is written, so if any adjustment would fail
+// (e.g., ErrInsufficientStock) none are applied and it returns 0. Several
+// adjustments may target the same SKU. Adjustments apply to their
+// WarehouseID, the default warehouse if empty. Each applied adjustment is
+// recorded.
+func (s *MemoryService) AdjustStockBatch(ctx context.Context, adjustments []StockAdjustment) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	levels := make(map[levelKey]*StockLevel)
+	var order []levelKey
+	for _, adjustment := range adjustments {
+		if adjustment.SKU == "" {
+			return 0, ErrInvalidSKU
+		}
+
+		key := levelKey{sku: adjustment.SKU, warehouseID: adjustment.WarehouseID}
+		level, ok := levels[key]
+		if !ok {
+			var err error
+			level, err = s.repo.GetWarehouseStockLevel(ctx, adjustment.SKU, adjustment.WarehouseID)
+			if errors.Is(err, ErrInvalidSKU) && adjustment.Quantity >= 0 {
+				level = &StockLevel{SKU: adjustment.SKU, WarehouseID: adjustment.WarehouseID}
+			} else if err != nil {
+				return 0, err
+			}
+			levels[key] = level
+			order = append(order, key)
+		}
+
+		// On-hand stock can never drop below what is already promised.
+		// Restocking always succeeds, even while backorders exceed stock.
+		if adjustment.Quantity < 0 && level.QuantityOnHand+adjustment.Quantity < level.QuantityReserved {
+			return 0, ErrInsufficientStock
+		}
+		level.QuantityOnHand += adjustment.Quantity
+		refreshAvailable(level)
+	}
+
+	for _, key := range order {
+		if err := s.repo.UpdateStockLevel(ctx, levels[key]); err != nil {
+			return 0, err
+		}
+	}
+
+	now := time.Now().Unix()
+	for _, adjustment := range adjustments {
+		adjustment := adjustment
+		if adjustment.CreatedAt == 0 {
+			adjustment.CreatedAt = now
+		}
+		if err := s.repo.SaveAdjustment(ctx, &adjustment); err != nil {
+			return len(adjustments), err
+		}
+	}
+	return len(adjustments), nil
+}
+
+// refreshAvailable recomputes the derived available quantity.
+func refreshAvailable(level *StockLevel) {
+	level.QuantityAvailable = level.QuantityOnHand - level.QuantityReserved
+}
+
+// recordAdjustment adds a history entry for a change the service made to
+// reservation's stock: onHand and reserved are the changes in on-hand and
+// reserved quantity.
+func (s *MemoryService) recordAdjustment(ctx context.Context, reservation *Reservation, onHand, reserved int, reason string) error {
+	return s.repo.SaveAdjustment(ctx, &StockAdjustment{
+		SKU:              reservation.SKU,
+		WarehouseID:      reservation.WarehouseID,
+		Quantity:         onHand,
+		ReservedQuantity: reserved,
+		Reason:           reason,
+		ReferenceID:      reservation.ReferenceID,
+		CreatedAt:        time.Now().Unix(),
+	})
+}
+
+// stockLevels returns the levels of sku in every warehouse, or
+// ErrInvalidSKU if no warehouse stocks it.
+func (s *MemoryService) stockLevels(ctx context.Context, sku string) ([]*StockLevel, error) {
+	levels, err := s.repo.ListStockLevels(ctx, sku)
+	if err != nil {
+		return nil, err
+	}
+	if len(levels) == 0 {
+		return nil, ErrInvalidSKU
+	}
+	return levels, nil
+}
+
+// sumLevels totals the available and reserved quantities of levels.
+func sumLevels(levels []*StockLevel) (available, reserved int) {
+	for _, level := range levels {
+		available += level.QuantityAvailable
+		reserved += level.QuantityReserved
+	}
+	return available, reserved
+}
+
+// reservationID derives a stable reservation ID from its reference, SKU, and
+// warehouse. The default warehouse keeps the plain "reference:sku" form.
+func reservationID(referenceID, sku, warehouseID string) string {
+	if warehouseID == "" {
+		return referenceID + ":" + sku
+	}
+	return referenceID + ":" + sku + "@" + warehouseID
+}
+
+// singleWarehouse is a WarehouseStrategy that only draws from one warehouse.
+type singleWarehouse string
+
+func (w singleWarehouse) Rank(sku string, levels []*StockLevel) []*StockLevel {
+	for _, level := range levels {
+		if level.WarehouseID == string(w) {
+			return []*StockLevel{level}
+		}
+	}
+	return nil
+}
+
+// levelKey identifies a SKU's stock level in one warehouse.
+type levelKey struct {
+	sku         string
+	warehouseID string
+}
+
+// MemoryRepository implements Repository using in-memory storage.
+// It stores copies, so callers can't mutate state without going through it.
+type MemoryRepository struct {
+	levels       map[levelKey]StockLevel
+	reservations map[string]Reservation
+	adjustments  []StockAdjustment
+	mu           sync.RWMutex
+}
+
+// NewMemoryRepository creates an empty in-memory inventory repository.
+func NewMemoryRepository() *MemoryRepository {
+	return &MemoryRepository{
+		levels:       make(map[levelKey]StockLevel),
+		reservations: make(map[string]Reservation),
+	}
+}
+
+// GetStockLevel returns the stock level for a SKU in the default warehouse.
+func (r *MemoryRepository) GetStockLevel(ctx context.Context, sku string) (*StockLevel, error) {
+	return r.GetWarehouseStockLevel(ctx, sku, "")
+}
+
+// GetWarehouseStockLevel returns the stock level for a SKU in one warehouse.
+func (r *MemoryRepository) GetWarehouseStockLevel(ctx context.Context, sku, warehouseID string) (*StockLevel, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	level, ok := r.levels[levelKey{sku: sku, warehouseID: warehouseID}]
+	if !ok {
+		return nil, ErrInvalidSKU
+	}
+	return &level, nil
+}
+
+// ListStockLevels returns the stock level for a SKU in every warehouse,
+// ordered by WarehouseID. It returns an empty slice for an unknown SKU.
+func (r *MemoryRepository) ListStockLevels(ctx context.Context, sku string) ([]*StockLevel, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	result := make([]*StockLevel, 0)
+	for key, level := range r.levels {
+		if key.sku == sku {
+			level := level
+			result = append(result, &level)
+		}
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].WarehouseID < result[j].WarehouseID
+	})
+	return result, nil
+}
+
+// UpdateStockLevel creates or replaces the stock level for level.SKU in
+// level.WarehouseID.
+func (r *MemoryRepository) UpdateStockLevel(ctx context.Context, level *StockLevel) error {
+	if level == nil || level.SKU == "" {
+		return ErrInvalidSKU
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.levels[levelKey{sku: level.SKU, warehouseID: level.WarehouseID}] = *level
+	return nil
+}
+
+// GetReservation returns a reservation by ID.
+func (r *MemoryRepository) GetReservation(ctx context.Context, id string) (*Reservation, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	reservation, ok := r.reservations[id]
+	if !ok {
+		return nil, ErrReservationNotFound
+	}
+	return &reservation, nil
+}
+
+// GetReservationsByReference returns all reservations for a reference, ordered by ID.
+func (r *MemoryRepository) GetReservationsByReference(ctx context.Context, referenceID string) ([]*Reservation, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	result := make([]*Reservation, 0)
+	for _, reservation := range r.reservations {
+		if reservation.ReferenceID == referenceID {
+			reservation := reservation
+			result = append(result, &reservation)
+		}
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].ID < result[j].ID
+	})
+	return result, nil
+}
+
+// SaveReservation creates or replaces a reservation.
+func (r *MemoryRepository) SaveReservation(ctx context.Context, reservation *Reservation) error {
+	if reservation == nil || reservation.ID == "" {
+		return ErrReservationFailed
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.reservations[reservation.ID] = *reservation
+	return nil
+}
+
+// DeleteReservation removes a reservation.
+func (r *MemoryRepository) DeleteReservation(ctx context.Context, id string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	delete(r.reservations, id)
+	return nil
+}
+
+// GetExpiredReservations returns active reservations whose expiry has passed, ordered by ID.
+func (r *MemoryRepository) GetExpiredReservations(ctx context.Context) ([]*Reservation, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	now := time.Now().Unix()
+	result := make([]*Reservation, 0)
+	for _, reservation := range r.reservations {
+		if reservation.Status == ReservationStatusActive && reservation.ExpiresAt <= now {
+			reservation := reservation
+			result = append(result, &reservation)
+		}
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].ID < result[j].ID
+	})
+	return result, nil
+}
+
+// SaveAdjustment appends an adjustment to the stock history, assigning an ID
+// if it has none.
+func (r *MemoryRepository) SaveAdjustment(ctx context.Context, adjustment *StockAdjustment) error {
+	if adjustment == nil || adjustment.SKU == "" {
+		return ErrInvalidSKU
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if adjustment.ID == "" {
+		adjustment.ID = fmt.Sprintf("adj-%d", len(r.adjustments)+1)
+	}
+	r.adjustments = append(r.adjustments, *adjustment)
+	return nil
+}
+
+// GetAdjustments returns the adjustments for sku created in [from, to),
+// oldest first. Zero times leave that end of the range open.
+func (r *MemoryRepository) GetAdjustments(ctx context.Context, sku string, from, to time.Time) ([]*StockAdjustment, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	result := make([]*StockAdjustment, 0)
+	for _, adjustment := range r.adjustments {
+		if adjustment.SKU != sku {
+			continue
+		}
+		if !from.IsZero() && adjustment.CreatedAt < from.Unix() {
+			continue
+		}
+		if !to.IsZero() && adjustment.CreatedAt >= to.Unix() {
+			continue
+		}
+		adjustment := adjustment
+		result = append(result, &adjustment)
+	}
+	sort.SliceStable(result, func(i, j int) bool {
+		return result[i].CreatedAt < result[j].CreatedAt
+	})
+	return result, nil
+}
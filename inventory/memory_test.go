@@ -0,0 +1,424 @@
+package inventory
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+)
+
+// newTestService returns a service over a repository holding the given
+// on-hand quantities, keyed by SKU, in the default warehouse.
+func newTestService(t *testing.T, onHand map[string]int, opts ...Option) (*MemoryService, *MemoryRepository) {
+	t.Helper()
+	repo := NewMemoryRepository()
+	for sku, qty := range onHand {
+		if err := repo.UpdateStockLevel(context.Background(), &StockLevel{
+			SKU:               sku,
+			QuantityOnHand:    qty,
+			QuantityAvailable: qty,
+		}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return NewMemoryService(repo, opts...), repo
+}
+
+func TestAdjustmentHistoryReconcilesToOnHand(t *testing.T) {
+	ctx := context.Background()
+	s, repo := newTestService(t, nil)
+
+	if err := s.AdjustStock(ctx, "SKU-1", 10, "restock"); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Reserve(ctx, "SKU-1", 4, "cart-1"); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Release(ctx, "SKU-1", 0, "cart-1"); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Reserve(ctx, "SKU-1", 3, "order-1"); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Commit(ctx, "order-1"); err != nil {
+		t.Fatal(err)
+	}
+	s.ttl = -time.Second
+	if err := s.Reserve(ctx, "SKU-1", 2, "cart-2"); err != nil {
+		t.Fatal(err)
+	}
+	if n, err := s.ReleaseExpired(ctx); err != nil || n != 1 {
+		t.Fatalf("ReleaseExpired = %d, %v; want 1, nil", n, err)
+	}
+	if err := s.AdjustStock(ctx, "SKU-1", -1, "damage"); err != nil {
+		t.Fatal(err)
+	}
+
+	history, err := s.GetAdjustmentHistory(ctx, "SKU-1", time.Time{}, time.Time{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []struct {
+		reason           string
+		quantity         int
+		reservedQuantity int
+	}{
+		{"restock", 10, 0},
+		{AdjustmentReasonRelease, 0, -4},
+		{AdjustmentReasonCommit, -3, -3},
+		{AdjustmentReasonExpired, 0, -2},
+		{"damage", -1, 0},
+	}
+	if len(history) != len(want) {
+		t.Fatalf("history has %d entries, want %d", len(history), len(want))
+	}
+	onHand := 0
+	for i, entry := range history {
+		if entry.Reason != want[i].reason || entry.Quantity != want[i].quantity || entry.ReservedQuantity != want[i].reservedQuantity {
+			t.Errorf("entry %d = %s %d/%d, want %s %d/%d", i,
+				entry.Reason, entry.Quantity, entry.ReservedQuantity,
+				want[i].reason, want[i].quantity, want[i].reservedQuantity)
+		}
+		onHand += entry.Quantity
+	}
+
+	level, err := repo.GetStockLevel(ctx, "SKU-1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if level.QuantityOnHand != 6 || level.QuantityReserved != 0 {
+		t.Errorf("level = %d on hand, %d reserved; want 6, 0", level.QuantityOnHand, level.QuantityReserved)
+	}
+	if onHand != level.QuantityOnHand {
+		t.Errorf("history sums to %d, on hand is %d", onHand, level.QuantityOnHand)
+	}
+}
+
+func TestConcurrentReservationsDoNotOversell(t *testing.T) {
+	ctx := context.Background()
+	s, repo := newTestService(t, map[string]int{"SKU-1": 10})
+
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	succeeded := 0
+	for i := 0; i < 50; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			err := s.Reserve(ctx, "SKU-1", 1, fmt.Sprintf("cart-%d", i))
+			if err != nil && !errors.Is(err, ErrInsufficientStock) {
+				t.Errorf("cart-%d: %v", i, err)
+			}
+			if err == nil {
+				mu.Lock()
+				succeeded++
+				mu.Unlock()
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	if succeeded != 10 {
+		t.Errorf("%d reservations succeeded, want 10", succeeded)
+	}
+	level, err := repo.GetStockLevel(ctx, "SKU-1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if level.QuantityReserved != 10 || level.QuantityAvailable != 0 {
+		t.Errorf("level = %d reserved, %d available; want 10, 0", level.QuantityReserved, level.QuantityAvailable)
+	}
+}
+
+func TestExtendReservation(t *testing.T) {
+	ctx := context.Background()
+	s, _ := newTestService(t, map[string]int{"SKU-1": 10, "SKU-2": 10})
+
+	if err := s.Reserve(ctx, "SKU-1", 2, "checkout-1"); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Reserve(ctx, "SKU-2", 1, "checkout-1"); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.ExtendReservation(ctx, "checkout-1", 2*DefaultReservationTTL); err != nil {
+		t.Fatal(err)
+	}
+	reservations, err := s.ListReservations(ctx, "checkout-1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	minExpiry := time.Now().Add(2*DefaultReservationTTL - time.Minute).Unix()
+	for _, r := range reservations {
+		if r.ExpiresAt < minExpiry {
+			t.Errorf("%s expires at %d, want at least %d", r.SKU, r.ExpiresAt, minExpiry)
+		}
+	}
+
+	// A shorter ttl never pulls an expiry in.
+	before := reservations[0].ExpiresAt
+	if err := s.ExtendReservation(ctx, "checkout-1", time.Minute); err != nil {
+		t.Fatal(err)
+	}
+	reservations, _ = s.ListReservations(ctx, "checkout-1")
+	if reservations[0].ExpiresAt != before {
+		t.Errorf("expiry moved from %d to %d", before, reservations[0].ExpiresAt)
+	}
+
+	if err := s.Commit(ctx, "checkout-1"); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.ExtendReservation(ctx, "checkout-1", time.Hour); !errors.Is(err, ErrReservationNotActive) {
+		t.Errorf("committed: error = %v, want %v", err, ErrReservationNotActive)
+	}
+
+	s.ttl = -time.Second
+	if err := s.Reserve(ctx, "SKU-1", 1, "checkout-2"); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.ExtendReservation(ctx, "checkout-2", time.Hour); !errors.Is(err, ErrReservationNotActive) {
+		t.Errorf("expired: error = %v, want %v", err, ErrReservationNotActive)
+	}
+}
+
+func TestListReservationsAndReservedStockByReference(t *testing.T) {
+	ctx := context.Background()
+	s, _ := newTestService(t, map[string]int{"SKU-1": 10, "SKU-2": 10})
+
+	for _, r := range []struct {
+		sku, ref string
+		qty      int
+	}{
+		{"SKU-1", "order-1", 3},
+		{"SKU-2", "order-1", 2},
+		{"SKU-1", "order-2", 4},
+	} {
+		if err := s.Reserve(ctx, r.sku, r.qty, r.ref); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := s.Release(ctx, "SKU-2", 0, "order-1"); err != nil {
+		t.Fatal(err)
+	}
+
+	reservations, err := s.ListReservations(ctx, "order-1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(reservations) != 2 {
+		t.Fatalf("order-1 has %d reservations, want 2", len(reservations))
+	}
+	for i := 1; i < len(reservations); i++ {
+		if reservations[i-1].ID > reservations[i].ID {
+			t.Errorf("reservations not ordered by ID: %s before %s", reservations[i-1].ID, reservations[i].ID)
+		}
+	}
+
+	reserved, err := s.GetReservedStockByReference(ctx, "order-1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(reserved) != 1 || reserved["SKU-1"] != 3 {
+		t.Errorf("order-1 reserved = %v, want SKU-1: 3", reserved)
+	}
+	if reserved, _ := s.GetReservedStockByReference(ctx, "unknown"); len(reserved) != 0 {
+		t.Errorf("unknown reference reserved = %v, want none", reserved)
+	}
+}
+
+func TestAdjustStockBatchIsAllOrNothing(t *testing.T) {
+	ctx := context.Background()
+	s, repo := newTestService(t, map[string]int{"SKU-1": 10, "SKU-2": 10})
+
+	n, err := s.AdjustStockBatch(ctx, []StockAdjustment{
+		{SKU: "SKU-1", Quantity: 5, Reason: "restock"},
+		{SKU: "SKU-2", Quantity: -4, Reason: "damage"},
+		{SKU: "SKU-2", Quantity: -7, Reason: "damage"},
+	})
+	if n != 0 || !errors.Is(err, ErrInsufficientStock) {
+		t.Fatalf("AdjustStockBatch = %d, %v; want 0, %v", n, err, ErrInsufficientStock)
+	}
+	for _, sku := range []string{"SKU-1", "SKU-2"} {
+		level, _ := repo.GetStockLevel(ctx, sku)
+		if level.QuantityOnHand != 10 {
+			t.Errorf("%s on hand = %d, want 10", sku, level.QuantityOnHand)
+		}
+		if history, _ := s.GetAdjustmentHistory(ctx, sku, time.Time{}, time.Time{}); len(history) != 0 {
+			t.Errorf("%s has %d history entries, want 0", sku, len(history))
+		}
+	}
+
+	n, err = s.AdjustStockBatch(ctx, []StockAdjustment{
+		{SKU: "SKU-1", Quantity: 5, Reason: "restock"},
+		{SKU: "SKU-2", Quantity: -4, Reason: "damage"},
+		{SKU: "SKU-NEW", Quantity: 3, Reason: "restock"},
+	})
+	if n != 3 || err != nil {
+		t.Fatalf("AdjustStockBatch = %d, %v; want 3, nil", n, err)
+	}
+	for sku, want := range map[string]int{"SKU-1": 15, "SKU-2": 6, "SKU-NEW": 3} {
+		level, err := repo.GetStockLevel(ctx, sku)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if level.QuantityOnHand != want || level.QuantityAvailable != want {
+			t.Errorf("%s = %d on hand, %d available; want %d", sku, level.QuantityOnHand, level.QuantityAvailable, want)
+		}
+	}
+
+	// Stock promised to a reservation can't be written off.
+	if err := s.Reserve(ctx, "SKU-NEW", 2, "order-1"); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.AdjustStock(ctx, "SKU-NEW", -2, "damage"); !errors.Is(err, ErrInsufficientStock) {
+		t.Errorf("writing off reserved stock: error = %v, want %v", err, ErrInsufficientStock)
+	}
+}
+
+func TestCheckAvailability(t *testing.T) {
+	ctx := context.Background()
+	s, _ := newTestService(t, map[string]int{"A": 5, "B": 2})
+
+	available, shortages, err := s.CheckAvailability(ctx, []StockRequest{
+		{SKU: "B", Quantity: 2},
+		{SKU: "A", Quantity: 3},
+		{SKU: "C", Quantity: 1},
+		{SKU: "B", Quantity: 1},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if available["A"] != 5 || available["B"] != 2 || available["C"] != 0 {
+		t.Errorf("available = %v, want A 5, B 2, C 0", available)
+	}
+	want := []Shortage{{SKU: "B", Requested: 3, Available: 2}, {SKU: "C", Requested: 1, Available: 0}}
+	if len(shortages) != len(want) {
+		t.Fatalf("shortages = %+v, want %+v", shortages, want)
+	}
+	for i := range want {
+		if shortages[i] != want[i] {
+			t.Errorf("shortage %d = %+v, want %+v", i, shortages[i], want[i])
+		}
+	}
+
+	if _, _, err := s.CheckAvailability(ctx, []StockRequest{{Quantity: 1}}); !errors.Is(err, ErrInvalidSKU) {
+		t.Errorf("empty SKU: error = %v, want %v", err, ErrInvalidSKU)
+	}
+}
+
+func TestReleaseExpired(t *testing.T) {
+	ctx := context.Background()
+	s, _ := newTestService(t, map[string]int{"SKU-1": 10})
+
+	if err := s.Reserve(ctx, "SKU-1", 2, "live"); err != nil {
+		t.Fatal(err)
+	}
+	s.ttl = -time.Second
+	if err := s.Reserve(ctx, "SKU-1", 3, "stale"); err != nil {
+		t.Fatal(err)
+	}
+
+	released, err := s.ReleaseExpired(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if released != 1 {
+		t.Errorf("released %d reservations, want 1", released)
+	}
+	if got, _ := s.GetAvailableStock(ctx, "SKU-1"); got != 8 {
+		t.Errorf("available = %d, want 8", got)
+	}
+	stale, err := s.ListReservations(ctx, "stale")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(stale) != 1 || stale[0].Status != ReservationStatusExpired {
+		t.Errorf("stale reservations = %+v, want one expired", stale)
+	}
+
+	if released, err := s.ReleaseExpired(ctx); err != nil || released != 0 {
+		t.Errorf("second sweep released %d, %v; want 0", released, err)
+	}
+	if got, _ := s.GetAvailableStock(ctx, "SKU-1"); got != 8 {
+		t.Errorf("available after second sweep = %d, want 8", got)
+	}
+}
+
+func TestReserveIsIdempotentPerReference(t *testing.T) {
+	ctx := context.Background()
+	s, repo := newTestService(t, map[string]int{"SKU-1": 10})
+
+	steps := []struct {
+		quantity     int
+		wantReserved int
+	}{
+		{3, 3},
+		{3, 3}, // retry
+		{5, 5}, // grows by the difference
+		{2, 2}, // shrinks by the difference
+	}
+	for _, step := range steps {
+		if err := s.Reserve(ctx, "SKU-1", step.quantity, "order-1"); err != nil {
+			t.Fatal(err)
+		}
+		if got, _ := s.GetReservedStock(ctx, "SKU-1"); got != step.wantReserved {
+			t.Errorf("after reserving %d: reserved = %d, want %d", step.quantity, got, step.wantReserved)
+		}
+	}
+	if err := s.Reserve(ctx, "SKU-1", 11, "order-1"); !errors.Is(err, ErrInsufficientStock) {
+		t.Errorf("over stock: error = %v, want %v", err, ErrInsufficientStock)
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := s.Reserve(ctx, "SKU-1", 4, "order-2"); err != nil {
+				t.Error(err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	level, err := repo.GetStockLevel(ctx, "SKU-1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if level.QuantityReserved != 6 || level.QuantityAvailable != 4 {
+		t.Errorf("level = %d reserved, %d available; want 6, 4", level.QuantityReserved, level.QuantityAvailable)
+	}
+}
+
+func TestReserveAllowingBackorder(t *testing.T) {
+	ctx := context.Background()
+	s, _ := newTestService(t, map[string]int{"SKU-1": 3})
+
+	short, err := s.ReserveAllowingBackorder(ctx, "SKU-1", 5, "order-1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if short != 2 {
+		t.Errorf("backordered = %d, want 2", short)
+	}
+	if got, _ := s.GetAvailableStock(ctx, "SKU-1"); got != -2 {
+		t.Errorf("available = %d, want -2", got)
+	}
+
+	// Shrinking frees backordered units first.
+	if short, err = s.ReserveAllowingBackorder(ctx, "SKU-1", 4, "order-1"); err != nil || short != 1 {
+		t.Errorf("after shrinking: backordered = %d, %v; want 1", short, err)
+	}
+	reservations, err := s.ListReservations(ctx, "order-1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(reservations) != 1 || reservations[0].Quantity != 4 || reservations[0].Backordered != 1 {
+		t.Errorf("reservations = %+v, want 4 units with 1 backordered", reservations)
+	}
+
+	if err := s.Reserve(ctx, "SKU-1", 1, "order-2"); !errors.Is(err, ErrInsufficientStock) {
+		t.Errorf("plain Reserve while backordered: error = %v, want %v", err, ErrInsufficientStock)
+	}
+}
@@ -0,0 +1,56 @@
+package inventory
+
+import "sort"
+
+// WarehouseStrategy decides which warehouses a reservation draws from first.
+type WarehouseStrategy interface {
+	// Rank orders the warehouses holding sku by preference. Reserve fills
+	// from the first level until it runs out, then moves to the next.
+	// Levels left out of the result are never drawn from.
+	Rank(sku string, levels []*StockLevel) []*StockLevel
+}
+
+// MostStockStrategy prefers the warehouse with the most stock available,
+// which keeps a reservation in as few warehouses as possible. It is the
+// default strategy.
+type MostStockStrategy struct{}
+
+// Rank orders levels by available quantity, largest first, breaking ties by
+// WarehouseID.
+func (MostStockStrategy) Rank(sku string, levels []*StockLevel) []*StockLevel {
+	ranked := append([]*StockLevel(nil), levels...)
+	sort.SliceStable(ranked, func(i, j int) bool {
+		if ranked[i].QuantityAvailable != ranked[j].QuantityAvailable {
+			return ranked[i].QuantityAvailable > ranked[j].QuantityAvailable
+		}
+		return ranked[i].WarehouseID < ranked[j].WarehouseID
+	})
+	return ranked
+}
+
+// PreferenceStrategy draws from warehouses in a fixed order, e.g., nearest
+// to the customer first. Warehouses not listed follow, most stock first.
+type PreferenceStrategy struct {
+	WarehouseIDs []string
+}
+
+// Rank orders levels by their position in WarehouseIDs.
+func (p PreferenceStrategy) Rank(sku string, levels []*StockLevel) []*StockLevel {
+	position := make(map[string]int, len(p.WarehouseIDs))
+	for i, id := range p.WarehouseIDs {
+		if _, ok := position[id]; !ok {
+			position[id] = i
+		}
+	}
+
+	ranked := MostStockStrategy{}.Rank(sku, levels)
+	sort.SliceStable(ranked, func(i, j int) bool {
+		pi, iok := position[ranked[i].WarehouseID]
+		pj, jok := position[ranked[j].WarehouseID]
+		if iok && jok {
+			return pi < pj
+		}
+		return iok && !jok
+	})
+	return ranked
+}
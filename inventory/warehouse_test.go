@@ -0,0 +1,96 @@
+package inventory
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+// newWarehouseService returns a service over one SKU stocked in several
+// warehouses, keyed by warehouse ID.
+func newWarehouseService(t *testing.T, sku string, onHand map[string]int, opts ...Option) *MemoryService {
+	t.Helper()
+	repo := NewMemoryRepository()
+	for warehouseID, qty := range onHand {
+		if err := repo.UpdateStockLevel(context.Background(), &StockLevel{
+			SKU:               sku,
+			WarehouseID:       warehouseID,
+			QuantityOnHand:    qty,
+			QuantityAvailable: qty,
+		}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return NewMemoryService(repo, opts...)
+}
+
+func wantAvailable(t *testing.T, s *MemoryService, sku string, want map[string]int) {
+	t.Helper()
+	for warehouseID, qty := range want {
+		got, err := s.GetAvailableStockByWarehouse(context.Background(), sku, warehouseID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got != qty {
+			t.Errorf("%s available in %s = %d, want %d", sku, warehouseID, got, qty)
+		}
+	}
+}
+
+func TestReserveAcrossWarehouses(t *testing.T) {
+	ctx := context.Background()
+	s := newWarehouseService(t, "SKU-1", map[string]int{"east": 3, "west": 6})
+
+	// Most stock first: all of r1 fits in west, r2 then prefers east and
+	// spills into west.
+	if err := s.Reserve(ctx, "SKU-1", 4, "r1"); err != nil {
+		t.Fatal(err)
+	}
+	wantAvailable(t, s, "SKU-1", map[string]int{"east": 3, "west": 2})
+	if err := s.Reserve(ctx, "SKU-1", 4, "r2"); err != nil {
+		t.Fatal(err)
+	}
+	wantAvailable(t, s, "SKU-1", map[string]int{"east": 0, "west": 1})
+	if reservations, _ := s.ListReservations(ctx, "r2"); len(reservations) != 2 {
+		t.Errorf("r2 has %d reservations, want one per warehouse", len(reservations))
+	}
+
+	if err := s.Reserve(ctx, "SKU-1", 2, "r3"); !errors.Is(err, ErrInsufficientStock) {
+		t.Errorf("over total stock: error = %v, want %v", err, ErrInsufficientStock)
+	}
+
+	if err := s.Release(ctx, "SKU-1", 0, "r2"); err != nil {
+		t.Fatal(err)
+	}
+	wantAvailable(t, s, "SKU-1", map[string]int{"east": 3, "west": 2})
+}
+
+func TestReserveWithPreferenceStrategy(t *testing.T) {
+	ctx := context.Background()
+	s := newWarehouseService(t, "SKU-1", map[string]int{"east": 3, "west": 6, "north": 1},
+		WithWarehouseStrategy(PreferenceStrategy{WarehouseIDs: []string{"north", "east"}}))
+
+	if err := s.Reserve(ctx, "SKU-1", 5, "r1"); err != nil {
+		t.Fatal(err)
+	}
+	wantAvailable(t, s, "SKU-1", map[string]int{"north": 0, "east": 0, "west": 5})
+
+	// Shrinking frees the least preferred warehouse first.
+	if err := s.Reserve(ctx, "SKU-1", 3, "r1"); err != nil {
+		t.Fatal(err)
+	}
+	wantAvailable(t, s, "SKU-1", map[string]int{"north": 0, "east": 1, "west": 6})
+}
+
+func TestReserveFromWarehouse(t *testing.T) {
+	ctx := context.Background()
+	s := newWarehouseService(t, "SKU-1", map[string]int{"east": 3, "west": 6})
+
+	if err := s.ReserveFromWarehouse(ctx, "SKU-1", "east", 4, "r1"); !errors.Is(err, ErrInsufficientStock) {
+		t.Errorf("more than east holds: error = %v, want %v", err, ErrInsufficientStock)
+	}
+	if err := s.ReserveFromWarehouse(ctx, "SKU-1", "east", 2, "r1"); err != nil {
+		t.Fatal(err)
+	}
+	wantAvailable(t, s, "SKU-1", map[string]int{"east": 1, "west": 6})
+}
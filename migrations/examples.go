@@ -486,4 +486,381 @@ var PostgreSQLExampleMigrations = []Migration{
 			return nil
 		},
 	},
+	{
+		Version: "012",
+		Name:    "index_products_updated_at",
+		Up: func(ctx context.Context, exec Executor) error {
+			return exec.Exec(ctx, `
+				CREATE INDEX IF NOT EXISTS idx_products_updated_at ON products(updated_at, id);
+			`)
+		},
+		Down: func(ctx context.Context, exec Executor) error {
+			return exec.Exec(ctx, "DROP INDEX IF EXISTS idx_products_updated_at")
+		},
+	},
+	{
+		Version: "013",
+		Name:    "add_tax_codes",
+		Up: func(ctx context.Context, exec Executor) error {
+			return exec.Exec(ctx, `
+				ALTER TABLE products
+					ADD COLUMN IF NOT EXISTS tax_code VARCHAR(50);
+				ALTER TABLE cart_items
+					ADD COLUMN IF NOT EXISTS tax_code VARCHAR(50);
+			`)
+		},
+		Down: func(ctx context.Context, exec Executor) error {
+			// Best-effort rollback; keep columns.
+			return nil
+		},
+	},
+	{
+		Version: "014",
+		Name:    "add_order_cancellation_reason",
+		Up: func(ctx context.Context, exec Executor) error {
+			return exec.Exec(ctx, `
+				ALTER TABLE orders
+					ADD COLUMN IF NOT EXISTS cancellation_reason VARCHAR(50),
+					ADD COLUMN IF NOT EXISTS cancellation_note TEXT;
+				CREATE INDEX IF NOT EXISTS idx_orders_cancellation_reason ON orders(cancellation_reason);
+			`)
+		},
+		Down: func(ctx context.Context, exec Executor) error {
+			// Best-effort rollback; keep columns.
+			return nil
+		},
+	},
+	{
+		Version: "015",
+		Name:    "add_shipping_dimensions",
+		Up: func(ctx context.Context, exec Executor) error {
+			return exec.Exec(ctx, `
+				ALTER TABLE products
+					ADD COLUMN IF NOT EXISTS weight_grams INTEGER NOT NULL DEFAULT 0,
+					ADD COLUMN IF NOT EXISTS length_cm INTEGER NOT NULL DEFAULT 0,
+					ADD COLUMN IF NOT EXISTS width_cm INTEGER NOT NULL DEFAULT 0,
+					ADD COLUMN IF NOT EXISTS height_cm INTEGER NOT NULL DEFAULT 0;
+				ALTER TABLE variants
+					ADD COLUMN IF NOT EXISTS weight_grams INTEGER NOT NULL DEFAULT 0,
+					ADD COLUMN IF NOT EXISTS length_cm INTEGER NOT NULL DEFAULT 0,
+					ADD COLUMN IF NOT EXISTS width_cm INTEGER NOT NULL DEFAULT 0,
+					ADD COLUMN IF NOT EXISTS height_cm INTEGER NOT NULL DEFAULT 0;
+				ALTER TABLE cart_items
+					ADD COLUMN IF NOT EXISTS weight_grams INTEGER NOT NULL DEFAULT 0,
+					ADD COLUMN IF NOT EXISTS length_cm INTEGER NOT NULL DEFAULT 0,
+					ADD COLUMN IF NOT EXISTS width_cm INTEGER NOT NULL DEFAULT 0,
+					ADD COLUMN IF NOT EXISTS height_cm INTEGER NOT NULL DEFAULT 0;
+			`)
+		},
+		Down: func(ctx context.Context, exec Executor) error {
+			// Best-effort rollback; keep columns.
+			return nil
+		},
+	},
+	{
+		Version: "016",
+		Name:    "create_tax_rates_table",
+		Up: func(ctx context.Context, exec Executor) error {
+			return exec.Exec(ctx, `
+				CREATE TABLE IF NOT EXISTS tax_rates (
+					id VARCHAR(255) PRIMARY KEY,
+					name VARCHAR(255) NOT NULL,
+					rate NUMERIC(10,6) NOT NULL,
+					country VARCHAR(2) NOT NULL DEFAULT '',
+					state VARCHAR(100) NOT NULL DEFAULT '',
+					city VARCHAR(100) NOT NULL DEFAULT '',
+					postal_code VARCHAR(20) NOT NULL DEFAULT '',
+					tax_type VARCHAR(50) NOT NULL,
+					is_compound BOOLEAN NOT NULL DEFAULT false,
+					priority INTEGER NOT NULL DEFAULT 0,
+					created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+					updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+				);
+				CREATE INDEX IF NOT EXISTS idx_tax_rates_location ON tax_rates(country, state, city, postal_code);
+			`)
+		},
+		Down: func(ctx context.Context, exec Executor) error {
+			return exec.Exec(ctx, "DROP TABLE IF EXISTS tax_rates CASCADE")
+		},
+	},
+	{
+		Version: "017",
+		Name:    "add_order_metadata",
+		Up: func(ctx context.Context, exec Executor) error {
+			return exec.Exec(ctx, `
+				ALTER TABLE orders
+					ADD COLUMN IF NOT EXISTS metadata JSONB;
+			`)
+		},
+		Down: func(ctx context.Context, exec Executor) error {
+			// Best-effort rollback; keep columns.
+			return nil
+		},
+	},
+	{
+		Version: "018",
+		Name:    "unique_cart_per_user",
+		Up: func(ctx context.Context, exec Executor) error {
+			// Users with several carts keep the most recently updated one;
+			// the items of the others are moved into it before they are
+			// deleted, so the unique index can be built.
+			return exec.Exec(ctx, `
+				UPDATE cart_items
+				SET cart_id = ranked.keep_id
+				FROM (
+					SELECT id, FIRST_VALUE(id) OVER (
+						PARTITION BY user_id ORDER BY updated_at DESC, id DESC
+					) AS keep_id
+					FROM carts
+					WHERE user_id IS NOT NULL
+				) AS ranked
+				WHERE cart_items.cart_id = ranked.id AND ranked.id <> ranked.keep_id;
+				
+				DELETE FROM carts
+				USING (
+					SELECT id, FIRST_VALUE(id) OVER (
+						PARTITION BY user_id ORDER BY updated_at DESC, id DESC
+					) AS keep_id
+					FROM carts
+					WHERE user_id IS NOT NULL
+				) AS ranked
+				WHERE carts.id = ranked.id AND ranked.id <> ranked.keep_id;
+				
+				CREATE UNIQUE INDEX IF NOT EXISTS idx_carts_user_id_unique
+					ON carts(user_id) WHERE user_id IS NOT NULL;
+			`)
+		},
+		Down: func(ctx context.Context, exec Executor) error {
+			return exec.Exec(ctx, "DROP INDEX IF EXISTS idx_carts_user_id_unique")
+		},
+	},
+	{
+		Version: "019",
+		Name:    "add_order_currency_conversion",
+		Up: func(ctx context.Context, exec Executor) error {
+			return exec.Exec(ctx, `
+				ALTER TABLE orders
+					ADD COLUMN IF NOT EXISTS original_total_amount BIGINT,
+					ADD COLUMN IF NOT EXISTS original_total_currency VARCHAR(3),
+					ADD COLUMN IF NOT EXISTS exchange_rate NUMERIC(20,10);
+			`)
+		},
+		Down: func(ctx context.Context, exec Executor) error {
+			// Best-effort rollback; keep columns.
+			return nil
+		},
+	},
+	{
+		Version: "020",
+		Name:    "add_promotion_priority",
+		Up: func(ctx context.Context, exec Executor) error {
+			return exec.Exec(ctx, `
+				ALTER TABLE promotions
+					ADD COLUMN IF NOT EXISTS priority INTEGER NOT NULL DEFAULT 0;
+			`)
+		},
+		Down: func(ctx context.Context, exec Executor) error {
+			// Best-effort rollback; keep columns.
+			return nil
+		},
+	},
+	{
+		Version: "021",
+		Name:    "add_promotion_tiers",
+		Up: func(ctx context.Context, exec Executor) error {
+			return exec.Exec(ctx, `
+				ALTER TABLE promotions
+					ADD COLUMN IF NOT EXISTS tiers JSONB;
+			`)
+		},
+		Down: func(ctx context.Context, exec Executor) error {
+			// Best-effort rollback; keep columns.
+			return nil
+		},
+	},
+	{
+		Version: "022",
+		Name:    "add_promotion_buy_x_get_y",
+		Up: func(ctx context.Context, exec Executor) error {
+			return exec.Exec(ctx, `
+				ALTER TABLE promotions
+					ADD COLUMN IF NOT EXISTS buy_quantity INTEGER NOT NULL DEFAULT 0,
+					ADD COLUMN IF NOT EXISTS get_quantity INTEGER NOT NULL DEFAULT 0,
+					ADD COLUMN IF NOT EXISTS get_percent_off DECIMAL(5,4) NOT NULL DEFAULT 0;
+			`)
+		},
+		Down: func(ctx context.Context, exec Executor) error {
+			// Best-effort rollback; keep columns.
+			return nil
+		},
+	},
+	{
+		Version: "023",
+		Name:    "add_promotion_per_user_limit",
+		Up: func(ctx context.Context, exec Executor) error {
+			return exec.Exec(ctx, `
+				ALTER TABLE promotions
+					ADD COLUMN IF NOT EXISTS per_user_limit INTEGER NOT NULL DEFAULT 0;
+				
+				CREATE TABLE IF NOT EXISTS promotion_redemptions (
+					id SERIAL PRIMARY KEY,
+					promotion_code VARCHAR(255) NOT NULL,
+					user_id VARCHAR(255) NOT NULL,
+					created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+				);
+				
+				CREATE INDEX IF NOT EXISTS idx_promotion_redemptions_code_user
+					ON promotion_redemptions(promotion_code, user_id);
+			`)
+		},
+		Down: func(ctx context.Context, exec Executor) error {
+			return exec.Exec(ctx, `DROP TABLE IF EXISTS promotion_redemptions`)
+		},
+	},
+	{
+		Version: "024",
+		Name:    "add_cart_item_category",
+		Up: func(ctx context.Context, exec Executor) error {
+			return exec.Exec(ctx, `
+				ALTER TABLE cart_items
+					ADD COLUMN IF NOT EXISTS category_id VARCHAR(255);
+			`)
+		},
+		Down: func(ctx context.Context, exec Executor) error {
+			// Best-effort rollback; keep columns.
+			return nil
+		},
+	},
+	{
+		Version: "025",
+		Name:    "add_promotion_exclusive",
+		Up: func(ctx context.Context, exec Executor) error {
+			return exec.Exec(ctx, `
+				ALTER TABLE promotions
+					ADD COLUMN IF NOT EXISTS exclusive BOOLEAN NOT NULL DEFAULT false;
+			`)
+		},
+		Down: func(ctx context.Context, exec Executor) error {
+			// Best-effort rollback; keep columns.
+			return nil
+		},
+	},
+	{
+		Version: "026",
+		Name:    "add_cart_item_dimensions",
+		Up: func(ctx context.Context, exec Executor) error {
+			return exec.Exec(ctx, `
+				ALTER TABLE cart_items
+					ADD COLUMN IF NOT EXISTS weight_grams INTEGER NOT NULL DEFAULT 0,
+					ADD COLUMN IF NOT EXISTS length_cm INTEGER NOT NULL DEFAULT 0,
+					ADD COLUMN IF NOT EXISTS width_cm INTEGER NOT NULL DEFAULT 0,
+					ADD COLUMN IF NOT EXISTS height_cm INTEGER NOT NULL DEFAULT 0;
+			`)
+		},
+		Down: func(ctx context.Context, exec Executor) error {
+			// Best-effort rollback; keep columns.
+			return nil
+		},
+	},
+	{
+		Version: "027",
+		Name:    "add_order_applied_promotions",
+		Up: func(ctx context.Context, exec Executor) error {
+			return exec.Exec(ctx, `
+				ALTER TABLE orders
+					ADD COLUMN IF NOT EXISTS applied_promotions JSONB;
+			`)
+		},
+		Down: func(ctx context.Context, exec Executor) error {
+			// Best-effort rollback; keep columns.
+			return nil
+		},
+	},
+	{
+		Version: "028",
+		Name:    "add_tax_rate_tax_code",
+		Up: func(ctx context.Context, exec Executor) error {
+			return exec.Exec(ctx, `
+				ALTER TABLE tax_rates
+					ADD COLUMN IF NOT EXISTS tax_code VARCHAR(50) NOT NULL DEFAULT '';
+			`)
+		},
+		Down: func(ctx context.Context, exec Executor) error {
+			// Best-effort rollback; keep columns.
+			return nil
+		},
+	},
+	{
+		Version: "029",
+		Name:    "add_order_inventory_reservation",
+		Up: func(ctx context.Context, exec Executor) error {
+			return exec.Exec(ctx, `
+				ALTER TABLE orders
+					ADD COLUMN IF NOT EXISTS reservation_id VARCHAR(255),
+					ADD COLUMN IF NOT EXISTS inventory_commit_failed BOOLEAN NOT NULL DEFAULT false;
+			`)
+		},
+		Down: func(ctx context.Context, exec Executor) error {
+			// Best-effort rollback; keep columns.
+			return nil
+		},
+	},
+	{
+		Version: "030",
+		Name:    "add_backorders",
+		Up: func(ctx context.Context, exec Executor) error {
+			return exec.Exec(ctx, `
+				ALTER TABLE products
+					ADD COLUMN IF NOT EXISTS allow_backorder BOOLEAN NOT NULL DEFAULT false;
+				ALTER TABLE cart_items
+					ADD COLUMN IF NOT EXISTS allow_backorder BOOLEAN NOT NULL DEFAULT false;
+				ALTER TABLE order_items
+					ADD COLUMN IF NOT EXISTS backordered BOOLEAN NOT NULL DEFAULT false;
+			`)
+		},
+		Down: func(ctx context.Context, exec Executor) error {
+			// Best-effort rollback; keep columns.
+			return nil
+		},
+	},
+	{
+		Version: "031",
+		Name:    "add_product_max_per_order",
+		Up: func(ctx context.Context, exec Executor) error {
+			return exec.Exec(ctx, `
+				ALTER TABLE products
+					ADD COLUMN IF NOT EXISTS max_per_order INTEGER;
+			`)
+		},
+		Down: func(ctx context.Context, exec Executor) error {
+			// Best-effort rollback; keep columns.
+			return nil
+		},
+	},
+	{
+		Version: "032",
+		Name:    "add_cart_saved_for_later",
+		Up: func(ctx context.Context, exec Executor) error {
+			return exec.Exec(ctx, `
+				ALTER TABLE cart_items
+					ADD COLUMN IF NOT EXISTS saved_for_later BOOLEAN NOT NULL DEFAULT false;
+			`)
+		},
+		Down: func(ctx context.Context, exec Executor) error {
+			// Best-effort rollback; keep columns.
+			return nil
+		},
+	},
+	{
+		Version: "033",
+		Name:    "add_cart_expiry_index",
+		Up: func(ctx context.Context, exec Executor) error {
+			return exec.Exec(ctx, `
+				CREATE INDEX IF NOT EXISTS idx_carts_expires_at ON carts(expires_at) WHERE expires_at IS NOT NULL;
+			`)
+		},
+		Down: func(ctx context.Context, exec Executor) error {
+			return exec.Exec(ctx, "DROP INDEX IF EXISTS idx_carts_expires_at")
+		},
+	},
 }
@@ -65,9 +65,10 @@ type Repository interface {
 
 // Manager orchestrates migration execution.
 type Manager struct {
-	repo       Repository
-	executor   Executor
-	migrations []Migration
+	repo             Repository
+	executor         Executor
+	migrations       []Migration
+	validationPolicy ValidationPolicy
 }
 
 // NewManager creates a new migration manager.
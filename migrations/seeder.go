@@ -56,6 +56,39 @@ func (s *Seeder) RunSingle(ctx context.Context, seedName string) error {
 	return fmt.Errorf("seed not found: %s", seedName)
 }
 
+// RunMany executes the named seeds in registration order, so dependencies
+// registered earlier (e.g., brands before products) run first regardless of
+// the order names are given in. Unknown names are reported before any seed runs.
+func (s *Seeder) RunMany(ctx context.Context, names ...string) error {
+	selected := make(map[string]bool, len(names))
+	for _, name := range names {
+		selected[name] = true
+	}
+
+	for _, name := range names {
+		found := false
+		for _, seed := range s.seeds {
+			if seed.Name == name {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return fmt.Errorf("seed not found: %s", name)
+		}
+	}
+
+	for _, seed := range s.seeds {
+		if !selected[seed.Name] {
+			continue
+		}
+		if err := s.runSeed(ctx, seed); err != nil {
+			return fmt.Errorf("seed '%s' failed: %w", seed.Name, err)
+		}
+	}
+	return nil
+}
+
 // runSeed executes a single seed within a transaction.
 func (s *Seeder) runSeed(ctx context.Context, seed Seed) error {
 	// Start transaction
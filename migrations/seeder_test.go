@@ -0,0 +1,61 @@
+package migrations
+
+import (
+	"context"
+	"testing"
+)
+
+// recordingExecutor is an Executor that records the queries it runs.
+type recordingExecutor struct {
+	queries []string
+}
+
+func (e *recordingExecutor) Exec(ctx context.Context, query string, args ...interface{}) error {
+	e.queries = append(e.queries, query)
+	return nil
+}
+
+func (e *recordingExecutor) Query(ctx context.Context, query string, args ...interface{}) ([]map[string]interface{}, error) {
+	return nil, nil
+}
+
+func (e *recordingExecutor) Begin(ctx context.Context) (Executor, error) { return e, nil }
+func (e *recordingExecutor) Commit(ctx context.Context) error            { return nil }
+func (e *recordingExecutor) Rollback(ctx context.Context) error          { return nil }
+
+func execSeed(name string) Seed {
+	return Seed{
+		Name: name,
+		Run: func(ctx context.Context, exec Executor) error {
+			return exec.Exec(ctx, name)
+		},
+	}
+}
+
+func TestSeederRunMany(t *testing.T) {
+	ctx := context.Background()
+	exec := &recordingExecutor{}
+	s := NewSeeder(exec)
+	s.RegisterMultiple([]Seed{execSeed("brands"), execSeed("categories"), execSeed("products")})
+
+	if err := s.RunMany(ctx, "products", "brands"); err != nil {
+		t.Fatal(err)
+	}
+	want := []string{"brands", "products"}
+	if len(exec.queries) != len(want) {
+		t.Fatalf("ran %v, want %v", exec.queries, want)
+	}
+	for i := range want {
+		if exec.queries[i] != want[i] {
+			t.Errorf("seed %d = %s, want %s", i, exec.queries[i], want[i])
+		}
+	}
+
+	exec.queries = nil
+	if err := s.RunMany(ctx, "brands", "reviews"); err == nil {
+		t.Error("unknown seed name accepted")
+	}
+	if len(exec.queries) != 0 {
+		t.Errorf("ran %v before reporting the unknown name", exec.queries)
+	}
+}
@@ -0,0 +1,138 @@
+package migrations
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// ProblemKind classifies a problem found by Manager.Validate.
+type ProblemKind string
+
+const (
+	ProblemDuplicateVersion ProblemKind = "duplicate_version"
+	ProblemOutOfOrder       ProblemKind = "out_of_order"
+	ProblemGap              ProblemKind = "gap"
+	ProblemMissingDown      ProblemKind = "missing_down"
+)
+
+// ValidationProblem describes one problem with the registered migrations.
+type ValidationProblem struct {
+	Kind    ProblemKind
+	Version string
+	Message string
+}
+
+// ValidationError lists every problem Validate treats as an error.
+type ValidationError struct {
+	Problems []ValidationProblem
+}
+
+func (e *ValidationError) Error() string {
+	messages := make([]string, len(e.Problems))
+	for i, problem := range e.Problems {
+		messages[i] = problem.Message
+	}
+	return "invalid migrations: " + strings.Join(messages, "; ")
+}
+
+// ValidationPolicy selects which kinds of problems Validate tolerates.
+// Tolerated problems are still listed by Problems, so they can be logged as
+// warnings. Duplicate versions are always errors.
+type ValidationPolicy struct {
+	AllowOutOfOrder  bool // Registration order differs from version order
+	AllowGaps        bool // Numeric versions skip a number (e.g., 003 after 001)
+	AllowMissingDown bool // Migrations that can't be rolled back
+}
+
+// SetValidationPolicy sets the policy used by Validate. By default every
+// problem is an error.
+func (m *Manager) SetValidationPolicy(policy ValidationPolicy) {
+	m.validationPolicy = policy
+}
+
+// Validate checks the registered migrations and returns a *ValidationError
+// listing the problems the validation policy doesn't allow, or nil.
+func (m *Manager) Validate() error {
+	var errs []ValidationProblem
+	for _, problem := range m.Problems() {
+		switch {
+		case problem.Kind == ProblemOutOfOrder && m.validationPolicy.AllowOutOfOrder,
+			problem.Kind == ProblemGap && m.validationPolicy.AllowGaps,
+			problem.Kind == ProblemMissingDown && m.validationPolicy.AllowMissingDown:
+			continue
+		}
+		errs = append(errs, problem)
+	}
+
+	if len(errs) > 0 {
+		return &ValidationError{Problems: errs}
+	}
+	return nil
+}
+
+// Problems returns every problem with the registered migrations, regardless
+// of the validation policy: duplicate versions, migrations registered after
+// a later version, gaps between purely numeric versions, and missing Down
+// functions.
+func (m *Manager) Problems() []ValidationProblem {
+	var problems []ValidationProblem
+
+	seen := make(map[string]bool, len(m.migrations))
+	for i, migration := range m.migrations {
+		if seen[migration.Version] {
+			problems = append(problems, ValidationProblem{
+				Kind:    ProblemDuplicateVersion,
+				Version: migration.Version,
+				Message: fmt.Sprintf("migration version %s registered more than once", migration.Version),
+			})
+		}
+		seen[migration.Version] = true
+
+		if i > 0 && migration.Version < m.migrations[i-1].Version {
+			problems = append(problems, ValidationProblem{
+				Kind:    ProblemOutOfOrder,
+				Version: migration.Version,
+				Message: fmt.Sprintf("migration %s registered after %s", migration.Version, m.migrations[i-1].Version),
+			})
+		}
+
+		if migration.Down == nil {
+			problems = append(problems, ValidationProblem{
+				Kind:    ProblemMissingDown,
+				Version: migration.Version,
+				Message: fmt.Sprintf("migration %s has no Down function", migration.Version),
+			})
+		}
+	}
+
+	return append(problems, m.gaps()...)
+}
+
+// gaps reports missing numbers when every version is a plain integer
+// (e.g., "001", "002"). Timestamp and semantic versions are not expected to
+// be contiguous and are skipped.
+func (m *Manager) gaps() []ValidationProblem {
+	numbers := make([]int, 0, len(m.migrations))
+	for _, migration := range m.migrations {
+		n, err := strconv.Atoi(migration.Version)
+		if err != nil {
+			return nil
+		}
+		numbers = append(numbers, n)
+	}
+	sort.Ints(numbers)
+
+	var problems []ValidationProblem
+	for i := 1; i < len(numbers); i++ {
+		if numbers[i]-numbers[i-1] > 1 {
+			problems = append(problems, ValidationProblem{
+				Kind:    ProblemGap,
+				Version: strconv.Itoa(numbers[i]),
+				Message: fmt.Sprintf("migration versions jump from %d to %d", numbers[i-1], numbers[i]),
+			})
+		}
+	}
+	return problems
+}
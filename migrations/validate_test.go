@@ -0,0 +1,71 @@
+package migrations
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func noop(ctx context.Context, exec Executor) error { return nil }
+
+func migration(version string, reversible bool) Migration {
+	m := Migration{Version: version, Name: "migration " + version, Up: noop}
+	if reversible {
+		m.Down = noop
+	}
+	return m
+}
+
+func TestManagerValidate(t *testing.T) {
+	tests := []struct {
+		name       string
+		migrations []Migration
+		policy     ValidationPolicy
+		wantKinds  []ProblemKind
+	}{
+		{"clean", []Migration{migration("001", true), migration("002", true)}, ValidationPolicy{}, nil},
+		{"out of order", []Migration{migration("002", true), migration("001", true)}, ValidationPolicy{}, []ProblemKind{ProblemOutOfOrder}},
+		{"gap", []Migration{migration("001", true), migration("003", true)}, ValidationPolicy{}, []ProblemKind{ProblemGap}},
+		{"timestamps are not gapped", []Migration{migration("20240101_001", true), migration("20240315_001", true)}, ValidationPolicy{}, nil},
+		{"missing down", []Migration{migration("001", false)}, ValidationPolicy{}, []ProblemKind{ProblemMissingDown}},
+		{"tolerated", []Migration{migration("003", false), migration("001", true)},
+			ValidationPolicy{AllowOutOfOrder: true, AllowGaps: true, AllowMissingDown: true}, nil},
+		{"duplicate always an error", []Migration{migration("001", true), migration("001", true)},
+			ValidationPolicy{AllowOutOfOrder: true, AllowGaps: true, AllowMissingDown: true}, []ProblemKind{ProblemDuplicateVersion}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := NewManager(nil, nil)
+			// Appended directly: Register refuses duplicate versions.
+			m.migrations = tt.migrations
+			m.SetValidationPolicy(tt.policy)
+
+			err := m.Validate()
+			if tt.wantKinds == nil {
+				if err != nil {
+					t.Fatalf("Validate = %v, want nil", err)
+				}
+				return
+			}
+			var verr *ValidationError
+			if !errors.As(err, &verr) {
+				t.Fatalf("Validate = %v, want *ValidationError", err)
+			}
+			if len(verr.Problems) != len(tt.wantKinds) {
+				t.Fatalf("problems = %+v, want kinds %v", verr.Problems, tt.wantKinds)
+			}
+			for i, problem := range verr.Problems {
+				if problem.Kind != tt.wantKinds[i] {
+					t.Errorf("problem %d = %s, want %s", i, problem.Kind, tt.wantKinds[i])
+				}
+			}
+		})
+	}
+
+	m := NewManager(nil, nil)
+	m.migrations = []Migration{migration("003", false), migration("001", true)}
+	m.SetValidationPolicy(ValidationPolicy{AllowOutOfOrder: true, AllowGaps: true, AllowMissingDown: true})
+	if got := len(m.Problems()); got != 3 {
+		t.Errorf("Problems lists %d tolerated problems, want 3", got)
+	}
+}
@@ -0,0 +1,100 @@
+package money
+
+import (
+	"math"
+	"strings"
+)
+
+// DefaultExponent is the number of minor-unit decimal places assumed for
+// currencies missing from the exponent table (100 minor units per major unit).
+const DefaultExponent = 2
+
+// currencyExponents lists ISO 4217 currencies whose minor unit is not 1/100.
+// Every other currency uses DefaultExponent.
+var currencyExponents = map[string]int{
+	// No minor unit
+	"BIF": 0, "CLP": 0, "DJF": 0, "GNF": 0, "ISK": 0, "JPY": 0, "KMF": 0,
+	"KRW": 0, "PYG": 0, "RWF": 0, "UGX": 0, "UYI": 0, "VND": 0, "VUV": 0,
+	"XAF": 0, "XOF": 0, "XPF": 0,
+	// Thousandths
+	"BHD": 3, "IQD": 3, "JOD": 3, "KWD": 3, "LYD": 3, "OMR": 3, "TND": 3,
+	// Ten-thousandths
+	"CLF": 4, "UYW": 4,
+}
+
+// Exponent returns the number of decimal places of currency's minor unit
+// (e.g., 2 for USD, 0 for JPY, 3 for KWD). Unknown currencies return
+// DefaultExponent.
+func Exponent(currency string) int {
+	if exponent, ok := currencyExponents[strings.ToUpper(currency)]; ok {
+		return exponent
+	}
+	return DefaultExponent
+}
+
+// SetExponent overrides the number of minor-unit decimal places used for
+// currency by NewFromFloat, ToFloat, String, and Parse (e.g., to treat ISK
+// amounts in aurar). The currency must be one New accepts, and a negative
+// exponent returns ErrInvalidExponent. It is not safe to call concurrently
+// with other money functions; configure it at startup.
+func SetExponent(currency string, exponent int) error {
+	currency, err := normalizeCurrency(currency)
+	if err != nil {
+		return err
+	}
+	if exponent < 0 {
+		return ErrInvalidExponent
+	}
+	currencyExponents[currency] = exponent
+	return nil
+}
+
+// minorPerMajor returns how many minor units make up one major unit of currency.
+func minorPerMajor(currency string) int64 {
+	return int64(math.Pow10(Exponent(currency)))
+}
+
+// isoCurrencies is the set of active ISO 4217 alphabetic currency codes,
+// including fund and precious-metal codes.
+var isoCurrencies = map[string]struct{}{
+	"AED": {}, "AFN": {}, "ALL": {}, "AMD": {}, "ANG": {}, "AOA": {}, "ARS": {}, "AUD": {},
+	"AWG": {}, "AZN": {}, "BAM": {}, "BBD": {}, "BDT": {}, "BGN": {}, "BHD": {}, "BIF": {},
+	"BMD": {}, "BND": {}, "BOB": {}, "BOV": {}, "BRL": {}, "BSD": {}, "BTN": {}, "BWP": {},
+	"BYN": {}, "BZD": {}, "CAD": {}, "CDF": {}, "CHE": {}, "CHF": {}, "CHW": {}, "CLF": {},
+	"CLP": {}, "CNY": {}, "COP": {}, "COU": {}, "CRC": {}, "CUC": {}, "CUP": {}, "CVE": {},
+	"CZK": {}, "DJF": {}, "DKK": {}, "DOP": {}, "DZD": {}, "EGP": {}, "ERN": {}, "ETB": {},
+	"EUR": {}, "FJD": {}, "FKP": {}, "GBP": {}, "GEL": {}, "GHS": {}, "GIP": {}, "GMD": {},
+	"GNF": {}, "GTQ": {}, "GYD": {}, "HKD": {}, "HNL": {}, "HTG": {}, "HUF": {}, "IDR": {},
+	"ILS": {}, "INR": {}, "IQD": {}, "IRR": {}, "ISK": {}, "JMD": {}, "JOD": {}, "JPY": {},
+	"KES": {}, "KGS": {}, "KHR": {}, "KMF": {}, "KPW": {}, "KRW": {}, "KWD": {}, "KYD": {},
+	"KZT": {}, "LAK": {}, "LBP": {}, "LKR": {}, "LRD": {}, "LSL": {}, "LYD": {}, "MAD": {},
+	"MDL": {}, "MGA": {}, "MKD": {}, "MMK": {}, "MNT": {}, "MOP": {}, "MRU": {}, "MUR": {},
+	"MVR": {}, "MWK": {}, "MXN": {}, "MXV": {}, "MYR": {}, "MZN": {}, "NAD": {}, "NGN": {},
+	"NIO": {}, "NOK": {}, "NPR": {}, "NZD": {}, "OMR": {}, "PAB": {}, "PEN": {}, "PGK": {},
+	"PHP": {}, "PKR": {}, "PLN": {}, "PYG": {}, "QAR": {}, "RON": {}, "RSD": {}, "RUB": {},
+	"RWF": {}, "SAR": {}, "SBD": {}, "SCR": {}, "SDG": {}, "SEK": {}, "SGD": {}, "SHP": {},
+	"SLE": {}, "SLL": {}, "SOS": {}, "SRD": {}, "SSP": {}, "STN": {}, "SVC": {}, "SYP": {},
+	"SZL": {}, "THB": {}, "TJS": {}, "TMT": {}, "TND": {}, "TOP": {}, "TRY": {}, "TTD": {},
+	"TWD": {}, "TZS": {}, "UAH": {}, "UGX": {}, "USD": {}, "USN": {}, "UYI": {}, "UYU": {},
+	"UYW": {}, "UZS": {}, "VED": {}, "VES": {}, "VND": {}, "VUV": {}, "WST": {}, "XAF": {},
+	"XAG": {}, "XAU": {}, "XBA": {}, "XBB": {}, "XBC": {}, "XBD": {}, "XCD": {}, "XDR": {},
+	"XOF": {}, "XPD": {}, "XPF": {}, "XPT": {}, "XSU": {}, "XTS": {}, "XUA": {}, "XXX": {},
+	"YER": {}, "ZAR": {}, "ZMW": {}, "ZWL": {},
+}
+
+// IsValidCurrency reports whether code is an ISO 4217 currency code. Codes
+// are matched case-insensitively, so "usd" is valid.
+func IsValidCurrency(code string) bool {
+	_, ok := isoCurrencies[strings.ToUpper(code)]
+	return ok
+}
+
+// normalizeCurrency upper-cases code and returns ErrInvalidCurrency if it
+// isn't an ISO 4217 code.
+func normalizeCurrency(code string) (string, error) {
+	code = strings.ToUpper(code)
+	if _, ok := isoCurrencies[code]; !ok {
+		return "", ErrInvalidCurrency
+	}
+	return code, nil
+}
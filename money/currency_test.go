@@ -0,0 +1,98 @@
+package money
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestMinorUnitExponents(t *testing.T) {
+	tests := []struct {
+		amount     float64
+		currency   string
+		wantMinor  int64
+		wantString string
+	}{
+		{19.99, "USD", 1999, "USD 19.99"},
+		{1000, "JPY", 1000, "JPY 1000"},
+		{1000.4, "JPY", 1000, "JPY 1000"},
+		{19.999, "KWD", 19999, "KWD 19.999"},
+		{-0.5, "BHD", -500, "BHD -0.500"},
+		{-0.05, "EUR", -5, "EUR -0.05"},
+	}
+	for _, tt := range tests {
+		m, err := NewFromFloat(tt.amount, tt.currency)
+		if err != nil {
+			t.Fatalf("NewFromFloat(%v, %s): %v", tt.amount, tt.currency, err)
+		}
+		if m.Amount != tt.wantMinor {
+			t.Errorf("NewFromFloat(%v, %s) = %d minor units, want %d", tt.amount, tt.currency, m.Amount, tt.wantMinor)
+		}
+		if got := m.String(); got != tt.wantString {
+			t.Errorf("String() = %q, want %q", got, tt.wantString)
+		}
+	}
+
+	if got := (Money{Amount: 19999, Currency: "KWD"}).ToFloat(); got != 19.999 {
+		t.Errorf("KWD ToFloat = %v, want 19.999", got)
+	}
+	if got := (Money{Amount: 1000, Currency: "JPY"}).ToFloat(); got != 1000 {
+		t.Errorf("JPY ToFloat = %v, want 1000", got)
+	}
+}
+
+func TestNewValidatesCurrency(t *testing.T) {
+	for _, code := range []string{"USD", "usd", "Eur", "jpy"} {
+		m, err := New(100, code)
+		if err != nil {
+			t.Errorf("New(100, %q): %v", code, err)
+			continue
+		}
+		if m.Currency != strings.ToUpper(code) {
+			t.Errorf("New(100, %q) currency = %q, want %q", code, m.Currency, strings.ToUpper(code))
+		}
+		if _, err := NewFromFloat(1, code); err != nil {
+			t.Errorf("NewFromFloat(1, %q): %v", code, err)
+		}
+	}
+	for _, code := range []string{"", "US", "USDX", "XYZ", "$"} {
+		if _, err := New(100, code); !errors.Is(err, ErrInvalidCurrency) {
+			t.Errorf("New(100, %q): error = %v, want %v", code, err, ErrInvalidCurrency)
+		}
+		if _, err := NewFromFloat(1, code); !errors.Is(err, ErrInvalidCurrency) {
+			t.Errorf("NewFromFloat(1, %q): error = %v, want %v", code, err, ErrInvalidCurrency)
+		}
+	}
+}
+
+func TestSetExponent(t *testing.T) {
+	defer SetExponent("ISK", Exponent("ISK"))
+
+	if err := SetExponent("isk", 2); err != nil {
+		t.Fatal(err)
+	}
+	if got := Exponent("ISK"); got != 2 {
+		t.Fatalf("Exponent(ISK) = %d, want 2", got)
+	}
+	m, err := NewFromFloat(12.34, "ISK")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if m.Amount != 1234 || m.String() != "ISK 12.34" {
+		t.Errorf("NewFromFloat(12.34, ISK) = %d (%s), want 1234 minor units", m.Amount, m)
+	}
+	parsed, err := Parse("ISK 12.34")
+	if err != nil || parsed.Amount != 1234 {
+		t.Errorf("Parse(ISK 12.34) = %v, %v; want 1234 minor units", parsed, err)
+	}
+
+	if err := SetExponent("ISK", -1); !errors.Is(err, ErrInvalidExponent) {
+		t.Errorf("negative exponent: error = %v, want %v", err, ErrInvalidExponent)
+	}
+	if got := Exponent("ISK"); got != 2 {
+		t.Errorf("Exponent(ISK) after rejected update = %d, want 2", got)
+	}
+	if err := SetExponent("XYZ", 2); !errors.Is(err, ErrInvalidCurrency) {
+		t.Errorf("unknown currency: error = %v, want %v", err, ErrInvalidCurrency)
+	}
+}
@@ -0,0 +1,44 @@
+package money
+
+import "encoding/json"
+
+// moneyJSON is the wire format of Money.
+type moneyJSON struct {
+	Amount    int64  `json:"amount"`
+	Currency  string `json:"currency"`
+	Formatted string `json:"formatted,omitempty"`
+}
+
+// MarshalJSON encodes m as {"amount":4999,"currency":"USD","formatted":"USD 49.99"}.
+// A zero Money without a currency (e.g., an unset optional amount) encodes as null.
+func (m Money) MarshalJSON() ([]byte, error) {
+	if m.Currency == "" && m.Amount == 0 {
+		return []byte("null"), nil
+	}
+	return json.Marshal(moneyJSON{
+		Amount:    m.Amount,
+		Currency:  m.Currency,
+		Formatted: m.String(),
+	})
+}
+
+// UnmarshalJSON decodes the amount and currency written by MarshalJSON;
+// "formatted" is ignored. The currency is upper-cased; it returns
+// ErrInvalidCurrency if the currency is missing or isn't ISO 4217. null
+// leaves m unchanged.
+func (m *Money) UnmarshalJSON(data []byte) error {
+	if string(data) == "null" {
+		return nil
+	}
+	var v moneyJSON
+	if err := json.Unmarshal(data, &v); err != nil {
+		return err
+	}
+	currency, err := normalizeCurrency(v.Currency)
+	if err != nil {
+		return err
+	}
+	m.Amount = v.Amount
+	m.Currency = currency
+	return nil
+}
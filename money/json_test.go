@@ -0,0 +1,65 @@
+package money
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+)
+
+func TestMoneyJSONRoundTrip(t *testing.T) {
+	m := Money{Amount: 4999, Currency: "USD"}
+	data, err := json.Marshal(m)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := `{"amount":4999,"currency":"USD","formatted":"USD 49.99"}`; string(data) != want {
+		t.Errorf("Marshal = %s, want %s", data, want)
+	}
+
+	var got Money
+	if err := json.Unmarshal(data, &got); err != nil {
+		t.Fatal(err)
+	}
+	if got != m {
+		t.Errorf("Unmarshal = %+v, want %+v", got, m)
+	}
+}
+
+func TestMoneyUnmarshalJSON(t *testing.T) {
+	var m Money
+	if err := json.Unmarshal([]byte(`{"amount":100,"currency":"eur"}`), &m); err != nil {
+		t.Fatal(err)
+	}
+	if want := (Money{Amount: 100, Currency: "EUR"}); m != want {
+		t.Errorf("got %+v, want %+v", m, want)
+	}
+
+	for _, data := range []string{`{"amount":100,"currency":"XYZ"}`, `{"amount":100}`} {
+		var m Money
+		if err := json.Unmarshal([]byte(data), &m); !errors.Is(err, ErrInvalidCurrency) {
+			t.Errorf("Unmarshal(%s) error = %v, want %v", data, err, ErrInvalidCurrency)
+		}
+	}
+}
+
+func TestMoneyJSONInStruct(t *testing.T) {
+	type line struct {
+		Price Money `json:"price"`
+	}
+	data, err := json.Marshal(line{Price: Money{Amount: -1000, Currency: "JPY"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := `{"price":{"amount":-1000,"currency":"JPY","formatted":"JPY -1000"}}`; string(data) != want {
+		t.Errorf("Marshal = %s, want %s", data, want)
+	}
+
+	// formatted is output only; amount and currency are authoritative.
+	var got line
+	if err := json.Unmarshal([]byte(`{"price":{"amount":250,"currency":"USD","formatted":"USD 99.99"}}`), &got); err != nil {
+		t.Fatal(err)
+	}
+	if want := (Money{Amount: 250, Currency: "USD"}); got.Price != want {
+		t.Errorf("Unmarshal = %+v, want %+v", got.Price, want)
+	}
+}
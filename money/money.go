@@ -3,6 +3,10 @@ package money
 import (
 	"errors"
 	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
 )
 
 // Money represents a monetary value with currency.
@@ -13,15 +17,24 @@ type Money struct {
 }
 
 var (
-	ErrCurrencyMismatch = errors.New("currency mismatch")
-	ErrNegativeAmount   = errors.New("amount cannot be negative")
-	ErrInvalidCurrency  = errors.New("invalid currency code")
+	ErrCurrencyMismatch   = errors.New("currency mismatch")
+	ErrNegativeAmount     = errors.New("amount cannot be negative")
+	ErrInvalidCurrency    = errors.New("invalid currency code")
+	ErrAllocationMismatch = errors.New("allocation needs one cap per ratio")
+	ErrDivisionByZero     = errors.New("division by zero")
+	ErrInvalidRange       = errors.New("lower bound exceeds upper bound")
+	ErrAmountOutOfRange   = errors.New("amount out of range")
+	ErrInvalidExponent    = errors.New("exponent cannot be negative")
+	ErrInsufficientTender = errors.New("tendered amount is less than amount due")
 )
 
-// New creates a new Money value.
+// New creates a new Money value. The currency must be an ISO 4217 code; it
+// is stored upper-cased, so "usd" becomes "USD". Unknown codes return
+// ErrInvalidCurrency.
 func New(amount int64, currency string) (Money, error) {
-	if currency == "" {
-		return Money{}, ErrInvalidCurrency
+	currency, err := normalizeCurrency(currency)
+	if err != nil {
+		return Money{}, err
 	}
 	return Money{
 		Amount:   amount,
@@ -29,18 +42,72 @@ func New(amount int64, currency string) (Money, error) {
 	}, nil
 }
 
-// NewFromFloat creates Money from a float (e.g., 19.99 USD).
-// Note: Use with caution due to floating point precision.
+// NewFromFloat creates Money from a float in major units (e.g., 19.99 USD).
+// The amount is rounded half away from zero to the currency's minor unit (see
+// Exponent) using its shortest decimal representation, so 19.99 USD becomes
+// 1999 (not 1998), 1.005 USD becomes 101, and 19.999 KWD becomes 19999.
+// NaN, infinities, and amounts too large for int64 minor units return
+// ErrAmountOutOfRange. The currency is validated and normalized as in New.
 func NewFromFloat(amount float64, currency string) (Money, error) {
-	if currency == "" {
-		return Money{}, ErrInvalidCurrency
+	currency, err := normalizeCurrency(currency)
+	if err != nil {
+		return Money{}, err
+	}
+	minor, err := floatToMinor(amount, Exponent(currency))
+	if err != nil {
+		return Money{}, err
 	}
 	return Money{
-		Amount:   int64(amount * 100),
+		Amount:   minor,
 		Currency: currency,
 	}, nil
 }
 
+// floatToMinor converts amount to minor units with the given number of
+// decimal places. Rounding is done on the decimal digits of the float's
+// shortest representation rather than on amount*10^exponent, which avoids
+// binary representation errors (0.29*100 == 28.999999999999996).
+func floatToMinor(amount float64, exponent int) (int64, error) {
+	if math.IsNaN(amount) || math.IsInf(amount, 0) ||
+		math.Abs(amount)*math.Pow10(exponent) >= math.MaxInt64 {
+		return 0, ErrAmountOutOfRange
+	}
+
+	negative := amount < 0
+	digits := strconv.FormatFloat(math.Abs(amount), 'f', -1, 64)
+
+	whole, frac, _ := strings.Cut(digits, ".")
+	for len(frac) <= exponent {
+		frac += "0"
+	}
+
+	minor, err := strconv.ParseInt(whole+frac[:exponent], 10, 64)
+	if errors.Is(err, strconv.ErrRange) {
+		return 0, ErrAmountOutOfRange
+	}
+	if err != nil {
+		return 0, err
+	}
+	if frac[exponent] >= '5' {
+		minor++
+	}
+	if negative {
+		minor = -minor
+	}
+	return minor, nil
+}
+
+// Normalize returns m with its currency code upper-cased (e.g., for amounts
+// built as struct literals from data sources that store "usd").
+func (m Money) Normalize() Money {
+	return Money{Amount: m.Amount, Currency: strings.ToUpper(m.Currency)}
+}
+
+// sameCurrency reports whether a and b name the same currency, ignoring case.
+func sameCurrency(a, b string) bool {
+	return strings.EqualFold(a, b)
+}
+
 // Zero returns zero money in the given currency.
 func Zero(currency string) Money {
 	return Money{Amount: 0, Currency: currency}
@@ -48,7 +115,7 @@ func Zero(currency string) Money {
 
 // Add adds two Money values. Returns error if currencies differ.
 func (m Money) Add(other Money) (Money, error) {
-	if m.Currency != other.Currency {
+	if !sameCurrency(m.Currency, other.Currency) {
 		return Money{}, ErrCurrencyMismatch
 	}
 	return Money{
@@ -57,9 +124,32 @@ func (m Money) Add(other Money) (Money, error) {
 	}, nil
 }
 
+// AddMany adds all others to m. It stops at and returns the first
+// currency mismatch encountered.
+func (m Money) AddMany(others ...Money) (Money, error) {
+	total := m
+	for _, other := range others {
+		if !sameCurrency(other.Currency, total.Currency) {
+			return Money{}, ErrCurrencyMismatch
+		}
+		total.Amount += other.Amount
+	}
+	return total, nil
+}
+
+// Sum adds values together, taking the currency from the first element.
+// It returns Zero("") when values is empty and ErrCurrencyMismatch if any
+// element's currency differs from the first.
+func Sum(values ...Money) (Money, error) {
+	if len(values) == 0 {
+		return Zero(""), nil
+	}
+	return values[0].AddMany(values[1:]...)
+}
+
 // Subtract subtracts other from m. Returns error if currencies differ.
 func (m Money) Subtract(other Money) (Money, error) {
-	if m.Currency != other.Currency {
+	if !sameCurrency(m.Currency, other.Currency) {
 		return Money{}, ErrCurrencyMismatch
 	}
 	return Money{
@@ -68,14 +158,76 @@ func (m Money) Subtract(other Money) (Money, error) {
 	}, nil
 }
 
-// Multiply multiplies the money by a factor.
+// Change returns the change due when tendered is paid against m (e.g., at a
+// point of sale). It returns ErrCurrencyMismatch if currencies differ and
+// ErrInsufficientTender if tendered is less than m.
+func (m Money) Change(tendered Money) (Money, error) {
+	if !sameCurrency(m.Currency, tendered.Currency) {
+		return Money{}, ErrCurrencyMismatch
+	}
+	if tendered.Amount < m.Amount {
+		return Money{}, ErrInsufficientTender
+	}
+	return Money{
+		Amount:   tendered.Amount - m.Amount,
+		Currency: m.Currency,
+	}, nil
+}
+
+// RoundingMode selects how fractional minor units are rounded.
+type RoundingMode int
+
+const (
+	// RoundHalfEven rounds to the nearest minor unit, ties to even (banker's rounding).
+	RoundHalfEven RoundingMode = iota
+	// RoundHalfUp rounds to the nearest minor unit, ties away from zero.
+	RoundHalfUp
+	// RoundDown truncates toward zero.
+	RoundDown
+	// RoundUp rounds away from zero.
+	RoundUp
+)
+
+// DefaultRoundingMode is the rounding mode used by Multiply.
+var DefaultRoundingMode = RoundHalfEven
+
+// Multiply multiplies the money by a factor, rounding with DefaultRoundingMode.
 func (m Money) Multiply(factor float64) Money {
+	return m.MultiplyWithRounding(factor, DefaultRoundingMode)
+}
+
+// MultiplyWithRounding multiplies the money by a factor, rounding the result
+// to a whole minor unit with mode.
+func (m Money) MultiplyWithRounding(factor float64, mode RoundingMode) Money {
 	return Money{
-		Amount:   int64(float64(m.Amount) * factor),
+		Amount:   roundMinor(float64(m.Amount)*factor, mode),
 		Currency: m.Currency,
 	}
 }
 
+// roundMinor rounds x minor units to an integer with mode.
+func roundMinor(x float64, mode RoundingMode) int64 {
+	// Strip binary noise (e.g., 25 * 0.1 = 2.5000000000000004) so ties and
+	// whole numbers are recognized; it is far below a minor unit.
+	if math.Abs(x) < 1e9 {
+		x = math.Round(x*1e6) / 1e6
+	}
+
+	switch mode {
+	case RoundHalfUp:
+		return int64(math.Round(x))
+	case RoundDown:
+		return int64(math.Trunc(x))
+	case RoundUp:
+		if x < 0 {
+			return int64(math.Floor(x))
+		}
+		return int64(math.Ceil(x))
+	default:
+		return int64(math.RoundToEven(x))
+	}
+}
+
 // MultiplyInt multiplies the money by an integer.
 func (m Money) MultiplyInt(factor int) Money {
 	return Money{
@@ -84,6 +236,71 @@ func (m Money) MultiplyInt(factor int) Money {
 	}
 }
 
+// Convert returns m expressed in currency at rate (units of currency per unit
+// of m's currency), rounded half away from zero to the minor unit. Differences
+// in minor unit exponent (e.g., USD to JPY) are accounted for.
+func (m Money) Convert(currency string, rate float64) Money {
+	scale := math.Pow10(Exponent(currency) - Exponent(m.Currency))
+	return Money{
+		Amount:   int64(math.Round(float64(m.Amount) * rate * scale)),
+		Currency: currency,
+	}
+}
+
+// Abs returns the absolute value of m.
+func (m Money) Abs() Money {
+	if m.Amount < 0 {
+		return m.Negate()
+	}
+	return m
+}
+
+// Negate returns m with its sign flipped.
+func (m Money) Negate() Money {
+	return Money{Amount: -m.Amount, Currency: m.Currency}
+}
+
+// Min returns the smaller of a and b. Returns error if currencies differ.
+func Min(a, b Money) (Money, error) {
+	if !sameCurrency(a.Currency, b.Currency) {
+		return Money{}, ErrCurrencyMismatch
+	}
+	if b.Amount < a.Amount {
+		return b, nil
+	}
+	return a, nil
+}
+
+// Max returns the larger of a and b. Returns error if currencies differ.
+func Max(a, b Money) (Money, error) {
+	if !sameCurrency(a.Currency, b.Currency) {
+		return Money{}, ErrCurrencyMismatch
+	}
+	if b.Amount > a.Amount {
+		return b, nil
+	}
+	return a, nil
+}
+
+// Clamp pins m to the range [lo, hi] (e.g., to keep a discount between zero
+// and the line subtotal). Returns ErrCurrencyMismatch if currencies differ
+// and ErrInvalidRange if lo is greater than hi.
+func (m Money) Clamp(lo, hi Money) (Money, error) {
+	if !sameCurrency(m.Currency, lo.Currency) || !sameCurrency(m.Currency, hi.Currency) {
+		return Money{}, ErrCurrencyMismatch
+	}
+	if lo.Amount > hi.Amount {
+		return Money{}, ErrInvalidRange
+	}
+	if m.Amount < lo.Amount {
+		return lo, nil
+	}
+	if m.Amount > hi.Amount {
+		return hi, nil
+	}
+	return m, nil
+}
+
 // IsNegative returns true if the amount is negative.
 func (m Money) IsNegative() bool {
 	return m.Amount < 0
@@ -101,7 +318,7 @@ func (m Money) IsPositive() bool {
 
 // LessThan returns true if m is less than other.
 func (m Money) LessThan(other Money) (bool, error) {
-	if m.Currency != other.Currency {
+	if !sameCurrency(m.Currency, other.Currency) {
 		return false, ErrCurrencyMismatch
 	}
 	return m.Amount < other.Amount, nil
@@ -109,25 +326,38 @@ func (m Money) LessThan(other Money) (bool, error) {
 
 // GreaterThan returns true if m is greater than other.
 func (m Money) GreaterThan(other Money) (bool, error) {
-	if m.Currency != other.Currency {
+	if !sameCurrency(m.Currency, other.Currency) {
 		return false, ErrCurrencyMismatch
 	}
 	return m.Amount > other.Amount, nil
 }
 
-// Equals returns true if m equals other.
+// Equals returns true if m equals other. Currency codes are compared
+// case-insensitively, as in every Money operation.
 func (m Money) Equals(other Money) bool {
-	return m.Amount == other.Amount && m.Currency == other.Currency
+	return m.Amount == other.Amount && sameCurrency(m.Currency, other.Currency)
 }
 
-// ToFloat converts to a float (dollars, euros, etc.).
+// ToFloat converts to a float in major units (dollars, euros, yen, etc.).
 func (m Money) ToFloat() float64 {
-	return float64(m.Amount) / 100.0
+	return float64(m.Amount) / float64(minorPerMajor(m.Currency))
 }
 
-// String returns a human-readable representation.
+// String returns a human-readable representation with the currency's
+// number of decimal places (e.g., "USD 19.99", "JPY 1000", "KWD 19.999").
 func (m Money) String() string {
-	return fmt.Sprintf("%s %.2f", m.Currency, m.ToFloat())
+	exponent := Exponent(m.Currency)
+	sign := ""
+	amount := uint64(m.Amount)
+	if m.Amount < 0 {
+		sign = "-"
+		amount = uint64(-m.Amount)
+	}
+	if exponent == 0 {
+		return fmt.Sprintf("%s %s%d", m.Currency, sign, amount)
+	}
+	unit := uint64(minorPerMajor(m.Currency))
+	return fmt.Sprintf("%s %s%d.%0*d", m.Currency, sign, amount/unit, exponent, amount%unit)
 }
 
 // Allocate divides money into n parts, handling remainders correctly.
@@ -153,3 +383,148 @@ func (m Money) Allocate(n int) []Money {
 	}
 	return result
 }
+
+// AllocateByRatios divides money into parts proportional to ratios (e.g.,
+// line subtotals), so the parts sum exactly to m. Minor units left over after
+// the proportional split go to the parts with the largest remainders, earliest
+// first on ties. Ratios must not be negative; if they sum to zero the money is
+// split evenly as in Allocate.
+func (m Money) AllocateByRatios(ratios []int64) []Money {
+	if len(ratios) == 0 {
+		return []Money{}
+	}
+
+	var total int64
+	for _, ratio := range ratios {
+		total += ratio
+	}
+	if total <= 0 {
+		return m.Allocate(len(ratios))
+	}
+
+	amount := m.Amount
+	if amount < 0 {
+		amount = -amount
+	}
+
+	shares := make([]int64, len(ratios))
+	remainders := make([]int64, len(ratios))
+	var distributed int64
+	for i, ratio := range ratios {
+		shares[i] = amount * ratio / total
+		remainders[i] = amount * ratio % total
+		distributed += shares[i]
+	}
+
+	order := make([]int, len(ratios))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(a, b int) bool {
+		return remainders[order[a]] > remainders[order[b]]
+	})
+	for j := 0; distributed < amount; j = (j + 1) % len(order) {
+		shares[order[j]]++
+		distributed++
+	}
+
+	result := make([]Money, len(shares))
+	for i, share := range shares {
+		if m.Amount < 0 {
+			share = -share
+		}
+		result[i] = Money{Amount: share, Currency: m.Currency}
+	}
+	return result
+}
+
+// Divide splits money into divisor parts like Allocate, but returns
+// ErrDivisionByZero instead of an empty slice when divisor is not positive.
+func (m Money) Divide(divisor int) ([]Money, error) {
+	if divisor <= 0 {
+		return nil, ErrDivisionByZero
+	}
+	return m.Allocate(divisor), nil
+}
+
+// Percentage returns percent percent of m (e.g., 15 for 15%), rounded with
+// DefaultRoundingMode.
+func (m Money) Percentage(percent float64) Money {
+	return m.Multiply(percent / 100)
+}
+
+// RatioOf returns m as a fraction of other (e.g., 0.25 when m is a quarter
+// of other). It returns ErrCurrencyMismatch if the currencies differ and
+// ErrDivisionByZero if other is zero.
+func (m Money) RatioOf(other Money) (float64, error) {
+	if !sameCurrency(m.Currency, other.Currency) {
+		return 0, ErrCurrencyMismatch
+	}
+	if other.Amount == 0 {
+		return 0, ErrDivisionByZero
+	}
+	return float64(m.Amount) / float64(other.Amount), nil
+}
+
+// AllocateCapped distributes m across buckets in proportion to ratios without
+// giving any bucket more than its cap (caps[i] caps bucket i). Whatever a
+// capped bucket can't take is redistributed to the others; the part that fits
+// nowhere is returned as overflow. Remainder cents go to the earliest
+// buckets, as in Allocate. It returns ErrAllocationMismatch if caps and
+// ratios differ in length and ErrCurrencyMismatch if a cap is in another
+// currency.
+func (m Money) AllocateCapped(ratios []int64, caps []Money) ([]Money, Money, error) {
+	if len(caps) != len(ratios) {
+		return nil, Money{}, ErrAllocationMismatch
+	}
+	for _, c := range caps {
+		if !sameCurrency(c.Currency, m.Currency) {
+			return nil, Money{}, ErrCurrencyMismatch
+		}
+	}
+
+	amounts := make([]int64, len(ratios))
+	remaining := m.Amount
+	for remaining > 0 {
+		// Buckets that still have room and a share of the ratios.
+		var open []int
+		var totalRatio int64
+		for i, ratio := range ratios {
+			if ratio > 0 && amounts[i] < caps[i].Amount {
+				open = append(open, i)
+				totalRatio += ratio
+			}
+		}
+		if len(open) == 0 {
+			break
+		}
+
+		shares := make([]int64, len(open))
+		var distributed int64
+		for j, i := range open {
+			shares[j] = remaining * ratios[i] / totalRatio
+			distributed += shares[j]
+		}
+		for j := 0; distributed < remaining; j = (j + 1) % len(shares) {
+			shares[j]++
+			distributed++
+		}
+
+		placed := int64(0)
+		for j, i := range open {
+			room := caps[i].Amount - amounts[i]
+			if shares[j] > room {
+				shares[j] = room
+			}
+			amounts[i] += shares[j]
+			placed += shares[j]
+		}
+		remaining -= placed
+	}
+
+	result := make([]Money, len(amounts))
+	for i, amount := range amounts {
+		result[i] = Money{Amount: amount, Currency: m.Currency}
+	}
+	return result, Money{Amount: remaining, Currency: m.Currency}, nil
+}
@@ -0,0 +1,324 @@
+package money
+
+import (
+	"errors"
+	"math"
+	"testing"
+)
+
+func usd(cents int64) Money {
+	return Money{Amount: cents, Currency: "USD"}
+}
+
+func amounts(parts []Money) []int64 {
+	out := make([]int64, len(parts))
+	for i, p := range parts {
+		out[i] = p.Amount
+	}
+	return out
+}
+
+func equalAmounts(a, b []int64) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func TestAllocateCapped(t *testing.T) {
+	tests := []struct {
+		name         string
+		m            Money
+		ratios       []int64
+		caps         []Money
+		want         []int64
+		wantOverflow int64
+	}{
+		{"uncapped", usd(1000), []int64{1, 1}, []Money{usd(1000), usd(1000)}, []int64{500, 500}, 0},
+		{"capped bucket spills over", usd(1000), []int64{1, 1}, []Money{usd(200), usd(1000)}, []int64{200, 800}, 0},
+		{"overflow", usd(1000), []int64{1, 3}, []Money{usd(100), usd(300)}, []int64{100, 300}, 600},
+		{"remainder to earliest", usd(100), []int64{1, 1, 1}, []Money{usd(100), usd(100), usd(100)}, []int64{34, 33, 33}, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, overflow, err := tt.m.AllocateCapped(tt.ratios, tt.caps)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !equalAmounts(amounts(got), tt.want) {
+				t.Errorf("parts = %v, want %v", amounts(got), tt.want)
+			}
+			if overflow.Amount != tt.wantOverflow {
+				t.Errorf("overflow = %d, want %d", overflow.Amount, tt.wantOverflow)
+			}
+		})
+	}
+}
+
+func TestAllocateCappedRejectsBadCaps(t *testing.T) {
+	if _, _, err := usd(100).AllocateCapped([]int64{1, 1}, []Money{usd(100)}); !errors.Is(err, ErrAllocationMismatch) {
+		t.Errorf("short caps: error = %v, want %v", err, ErrAllocationMismatch)
+	}
+	eur := Money{Amount: 100, Currency: "EUR"}
+	if _, _, err := usd(100).AllocateCapped([]int64{1}, []Money{eur}); !errors.Is(err, ErrCurrencyMismatch) {
+		t.Errorf("EUR cap: error = %v, want %v", err, ErrCurrencyMismatch)
+	}
+}
+
+func TestAddMany(t *testing.T) {
+	total, err := usd(1000).AddMany(usd(250), usd(-50), usd(1))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if total != usd(1201) {
+		t.Errorf("total = %v, want USD 12.01", total)
+	}
+	if same, err := usd(1000).AddMany(); err != nil || same != usd(1000) {
+		t.Errorf("no others = %v, %v; want USD 10.00", same, err)
+	}
+	eur := Money{Amount: 100, Currency: "EUR"}
+	if _, err := usd(1000).AddMany(usd(1), eur); !errors.Is(err, ErrCurrencyMismatch) {
+		t.Errorf("EUR addend: error = %v, want %v", err, ErrCurrencyMismatch)
+	}
+}
+
+func TestNewFromFloatRounds(t *testing.T) {
+	tests := []struct {
+		amount float64
+		want   int64
+	}{
+		{19.99, 1999},
+		{0.29, 29},
+		{1.005, 101},
+		{1.004, 100},
+		{-1.005, -101},
+		{0.125, 13},
+		{-0.125, -13},
+		{100, 10000},
+	}
+	for _, tt := range tests {
+		got, err := NewFromFloat(tt.amount, "USD")
+		if err != nil {
+			t.Fatalf("NewFromFloat(%v): %v", tt.amount, err)
+		}
+		if got.Amount != tt.want {
+			t.Errorf("NewFromFloat(%v) = %d, want %d", tt.amount, got.Amount, tt.want)
+		}
+	}
+}
+
+func TestMultiplyWithRounding(t *testing.T) {
+	tests := []struct {
+		name   string
+		amount int64
+		factor float64
+		mode   RoundingMode
+		want   int64
+	}{
+		{"half even rounds tie down", 25, 0.1, RoundHalfEven, 2},
+		{"half even rounds tie up", 35, 0.1, RoundHalfEven, 4},
+		{"half up", 25, 0.1, RoundHalfUp, 3},
+		{"half up negative", -25, 0.1, RoundHalfUp, -3},
+		{"down", 199, 0.5, RoundDown, 99},
+		{"down negative", -199, 0.5, RoundDown, -99},
+		{"up", 201, 0.5, RoundUp, 101},
+		{"up negative", -201, 0.5, RoundUp, -101},
+		{"up from a tie", 1000, 0.0825, RoundUp, 83},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := usd(tt.amount).MultiplyWithRounding(tt.factor, tt.mode); got.Amount != tt.want {
+				t.Errorf("%d x %v = %d, want %d", tt.amount, tt.factor, got.Amount, tt.want)
+			}
+		})
+	}
+	if got := usd(25).Multiply(0.1); got.Amount != 2 {
+		t.Errorf("Multiply uses %d for a tie, want banker's rounding to 2", got.Amount)
+	}
+}
+
+func TestDividePercentageRatioOf(t *testing.T) {
+	parts, err := usd(1000).Divide(3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []int64{334, 333, 333}; !equalAmounts(amounts(parts), want) {
+		t.Errorf("Divide(3) = %v, want %v", amounts(parts), want)
+	}
+	for _, divisor := range []int{0, -2} {
+		if _, err := usd(1000).Divide(divisor); !errors.Is(err, ErrDivisionByZero) {
+			t.Errorf("Divide(%d): error = %v, want %v", divisor, err, ErrDivisionByZero)
+		}
+	}
+
+	if got := usd(1999).Percentage(15); got.Amount != 300 {
+		t.Errorf("15%% of 19.99 = %d, want 300", got.Amount)
+	}
+
+	ratio, err := usd(250).RatioOf(usd(1000))
+	if err != nil || ratio != 0.25 {
+		t.Errorf("RatioOf = %v, %v; want 0.25", ratio, err)
+	}
+	if _, err := usd(250).RatioOf(usd(0)); !errors.Is(err, ErrDivisionByZero) {
+		t.Errorf("RatioOf zero: error = %v, want %v", err, ErrDivisionByZero)
+	}
+	if _, err := usd(250).RatioOf(Money{Amount: 1000, Currency: "EUR"}); !errors.Is(err, ErrCurrencyMismatch) {
+		t.Errorf("RatioOf EUR: error = %v, want %v", err, ErrCurrencyMismatch)
+	}
+}
+
+func TestAbsNegateMinMaxClamp(t *testing.T) {
+	if got := usd(-150).Abs(); got != usd(150) {
+		t.Errorf("Abs(-150) = %v", got)
+	}
+	if got := usd(150).Abs(); got != usd(150) {
+		t.Errorf("Abs(150) = %v", got)
+	}
+	if got := usd(150).Negate(); got != usd(-150) {
+		t.Errorf("Negate(150) = %v", got)
+	}
+
+	if got, err := Min(usd(300), usd(200)); err != nil || got != usd(200) {
+		t.Errorf("Min = %v, %v; want 200", got, err)
+	}
+	if got, err := Max(usd(300), usd(200)); err != nil || got != usd(300) {
+		t.Errorf("Max = %v, %v; want 300", got, err)
+	}
+	eur := Money{Amount: 100, Currency: "EUR"}
+	if _, err := Min(usd(1), eur); !errors.Is(err, ErrCurrencyMismatch) {
+		t.Errorf("Min EUR: error = %v, want %v", err, ErrCurrencyMismatch)
+	}
+	if _, err := Max(usd(1), eur); !errors.Is(err, ErrCurrencyMismatch) {
+		t.Errorf("Max EUR: error = %v, want %v", err, ErrCurrencyMismatch)
+	}
+
+	tests := []struct {
+		m, want int64
+	}{
+		{-50, 0},
+		{500, 500},
+		{1500, 1000},
+	}
+	for _, tt := range tests {
+		if got, err := usd(tt.m).Clamp(usd(0), usd(1000)); err != nil || got.Amount != tt.want {
+			t.Errorf("Clamp(%d) = %v, %v; want %d", tt.m, got, err, tt.want)
+		}
+	}
+	if _, err := usd(5).Clamp(usd(10), usd(0)); !errors.Is(err, ErrInvalidRange) {
+		t.Errorf("inverted range: error = %v, want %v", err, ErrInvalidRange)
+	}
+	if _, err := usd(5).Clamp(usd(0), eur); !errors.Is(err, ErrCurrencyMismatch) {
+		t.Errorf("EUR bound: error = %v, want %v", err, ErrCurrencyMismatch)
+	}
+}
+
+func TestSum(t *testing.T) {
+	total, err := Sum(usd(100), usd(250), usd(-50))
+	if err != nil || total != usd(300) {
+		t.Errorf("Sum = %v, %v; want USD 3.00", total, err)
+	}
+	if empty, err := Sum(); err != nil || empty != Zero("") {
+		t.Errorf("Sum() = %+v, %v; want zero with no currency", empty, err)
+	}
+	if _, err := Sum(usd(100), Money{Amount: 1, Currency: "EUR"}); !errors.Is(err, ErrCurrencyMismatch) {
+		t.Errorf("mixed: error = %v, want %v", err, ErrCurrencyMismatch)
+	}
+}
+
+func TestAllocateByRatios(t *testing.T) {
+	tests := []struct {
+		name   string
+		m      Money
+		ratios []int64
+		want   []int64
+	}{
+		{"exact", usd(1000), []int64{1, 3}, []int64{250, 750}},
+		{"largest remainder", usd(100), []int64{1, 1, 1}, []int64{34, 33, 33}},
+		{"remainder goes to the largest fraction", usd(1000), []int64{3333, 3333, 3334}, []int64{333, 333, 334}},
+		{"largest remainder beats earliest", usd(10), []int64{1, 2}, []int64{3, 7}},
+		{"negative", usd(-100), []int64{1, 1, 1}, []int64{-34, -33, -33}},
+		{"zero ratios split evenly", usd(100), []int64{0, 0}, []int64{50, 50}},
+		{"zero ratio gets nothing", usd(100), []int64{0, 1}, []int64{0, 100}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := tt.m.AllocateByRatios(tt.ratios)
+			if !equalAmounts(amounts(got), tt.want) {
+				t.Errorf("parts = %v, want %v", amounts(got), tt.want)
+			}
+			var sum int64
+			for _, part := range got {
+				sum += part.Amount
+			}
+			if sum != tt.m.Amount {
+				t.Errorf("parts sum to %d, want %d", sum, tt.m.Amount)
+			}
+		})
+	}
+}
+
+func TestNewFromFloatRejectsOutOfRange(t *testing.T) {
+	for _, amount := range []float64{math.NaN(), math.Inf(1), math.Inf(-1), 1e17, -1e17} {
+		if _, err := NewFromFloat(amount, "USD"); !errors.Is(err, ErrAmountOutOfRange) {
+			t.Errorf("NewFromFloat(%v): error = %v, want %v", amount, err, ErrAmountOutOfRange)
+		}
+	}
+	if m, err := NewFromFloat(1e15, "USD"); err != nil || m.Amount != 1e17 {
+		t.Errorf("NewFromFloat(1e15) = %d, %v; want 1e17 minor units", m.Amount, err)
+	}
+}
+
+func TestCurrencyCaseInsensitive(t *testing.T) {
+	upper := Money{Amount: 500, Currency: "USD"}
+	lower := Money{Amount: 300, Currency: "usd"}
+
+	sum, err := upper.Add(lower)
+	if err != nil || sum.Amount != 800 {
+		t.Errorf("Add = %v, %v; want 800", sum, err)
+	}
+	if diff, err := upper.Subtract(lower); err != nil || diff.Amount != 200 {
+		t.Errorf("Subtract = %v, %v; want 200", diff, err)
+	}
+	if less, err := lower.LessThan(upper); err != nil || !less {
+		t.Errorf("LessThan = %t, %v; want true", less, err)
+	}
+	if !upper.Equals(Money{Amount: 500, Currency: "Usd"}) {
+		t.Error("Equals differs on currency case")
+	}
+	if _, err := upper.Add(Money{Amount: 1, Currency: "eur"}); !errors.Is(err, ErrCurrencyMismatch) {
+		t.Errorf("USD + eur: error = %v, want %v", err, ErrCurrencyMismatch)
+	}
+	if got := lower.Normalize(); got != (Money{Amount: 300, Currency: "USD"}) {
+		t.Errorf("Normalize = %+v, want USD", got)
+	}
+}
+
+func TestChange(t *testing.T) {
+	due := Money{Amount: 1875, Currency: "USD"}
+	tests := []struct {
+		name     string
+		tendered Money
+		want     int64
+		wantErr  error
+	}{
+		{"exact", Money{Amount: 1875, Currency: "USD"}, 0, nil},
+		{"overpaid", Money{Amount: 2000, Currency: "usd"}, 125, nil},
+		{"short", Money{Amount: 1800, Currency: "USD"}, 0, ErrInsufficientTender},
+		{"other currency", Money{Amount: 2000, Currency: "EUR"}, 0, ErrCurrencyMismatch},
+	}
+	for _, tt := range tests {
+		change, err := due.Change(tt.tendered)
+		if !errors.Is(err, tt.wantErr) {
+			t.Errorf("%s: error = %v, want %v", tt.name, err, tt.wantErr)
+			continue
+		}
+		if err == nil && (change.Amount != tt.want || change.Currency != "USD") {
+			t.Errorf("%s: change = %+v, want %d USD", tt.name, change, tt.want)
+		}
+	}
+}
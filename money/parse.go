@@ -0,0 +1,134 @@
+package money
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// ErrInvalidFormat is returned when a string can't be parsed as Money.
+var ErrInvalidFormat = errors.New("invalid money format")
+
+// currencySymbols maps leading symbols accepted by Parse to their currency.
+var currencySymbols = map[string]string{
+	"$": "USD",
+	"€": "EUR",
+	"£": "GBP",
+	"¥": "JPY",
+	"₹": "INR",
+}
+
+// Parse builds Money from a formatted string such as "USD 49.99",
+// "49.99 USD", "$1,049.99" or "-JPY 1000". The currency comes from an ISO
+// code prefix or suffix, or from a leading symbol ($ is read as USD). Commas
+// are thousands separators and "." is the decimal point; the number may not
+// have more decimal places than the currency's exponent. A code that isn't
+// ISO 4217 (see IsValidCurrency) is rejected with ErrInvalidCurrency.
+func Parse(s string) (Money, error) {
+	return parse(s, "")
+}
+
+// ParseWithCurrency is like Parse but uses currency when s has no currency
+// code. A conflicting code in s is an error; a leading symbol is accepted
+// and ignored, since symbols like $ are shared by several currencies.
+func ParseWithCurrency(s, currency string) (Money, error) {
+	currency, err := normalizeCurrency(currency)
+	if err != nil {
+		return Money{}, err
+	}
+	return parse(s, currency)
+}
+
+func parse(input, currency string) (Money, error) {
+	invalid := func(reason string) error {
+		return fmt.Errorf("%w: %q: %s", ErrInvalidFormat, input, reason)
+	}
+
+	s := strings.TrimSpace(input)
+	negative := false
+	if strings.HasPrefix(s, "-") {
+		negative = true
+		s = strings.TrimSpace(s[1:])
+	}
+
+	code := ""
+	if len(s) > 3 && isCurrencyCode(s[:3]) {
+		code, s = s[:3], strings.TrimSpace(s[3:])
+	} else if len(s) > 3 && isCurrencyCode(s[len(s)-3:]) {
+		code, s = s[len(s)-3:], strings.TrimSpace(s[:len(s)-3])
+	} else {
+		for symbol, symbolCurrency := range currencySymbols {
+			if strings.HasPrefix(s, symbol) {
+				code, s = symbolCurrency, strings.TrimSpace(s[len(symbol):])
+				if currency != "" {
+					code = currency
+				}
+				break
+			}
+		}
+	}
+	if !negative && strings.HasPrefix(s, "-") {
+		negative = true
+		s = strings.TrimSpace(s[1:])
+	}
+
+	switch {
+	case code == "" && currency == "":
+		return Money{}, invalid("missing currency")
+	case code == "":
+		code = currency
+	case currency != "" && code != currency:
+		return Money{}, invalid(fmt.Sprintf("currency %s does not match %s", code, currency))
+	}
+	if !IsValidCurrency(code) {
+		return Money{}, fmt.Errorf("%w: %q: unknown currency %s", ErrInvalidCurrency, input, code)
+	}
+
+	if strings.Count(s, ".") > 1 {
+		return Money{}, invalid("multiple decimal points")
+	}
+	whole, frac, _ := strings.Cut(s, ".")
+	if strings.Contains(frac, ",") {
+		return Money{}, invalid("thousands separator after decimal point")
+	}
+	whole = strings.ReplaceAll(whole, ",", "")
+	if whole == "" || !isDigits(whole) || !isDigits(frac) {
+		return Money{}, invalid("not a number")
+	}
+
+	exponent := Exponent(code)
+	if len(frac) > exponent {
+		return Money{}, invalid(fmt.Sprintf("%s allows at most %d decimal places", code, exponent))
+	}
+	frac += strings.Repeat("0", exponent-len(frac))
+
+	amount, err := strconv.ParseInt(whole+frac, 10, 64)
+	if err != nil {
+		return Money{}, invalid("amount out of range")
+	}
+	if negative {
+		amount = -amount
+	}
+	return Money{Amount: amount, Currency: code}, nil
+}
+
+// isCurrencyCode reports whether s looks like an ISO 4217 code.
+func isCurrencyCode(s string) bool {
+	for _, r := range s {
+		if r < 'A' || r > 'Z' {
+			return false
+		}
+	}
+	return len(s) == 3
+}
+
+func isDigits(s string) bool {
+	for _, r := range s {
+		if !unicode.IsDigit(r) || r > unicode.MaxASCII {
+			return false
+		}
+	}
+	return true
+}
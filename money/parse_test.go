@@ -0,0 +1,83 @@
+package money
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		in   string
+		want Money
+	}{
+		{"USD 49.99", Money{Amount: 4999, Currency: "USD"}},
+		{"49.99 USD", Money{Amount: 4999, Currency: "USD"}},
+		{"$1,049.99", Money{Amount: 104999, Currency: "USD"}},
+		{"-JPY 1000", Money{Amount: -1000, Currency: "JPY"}},
+		{"EUR 5", Money{Amount: 500, Currency: "EUR"}},
+	}
+	for _, tt := range tests {
+		got, err := Parse(tt.in)
+		if err != nil {
+			t.Errorf("Parse(%q): %v", tt.in, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("Parse(%q) = %+v, want %+v", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestParseRejects(t *testing.T) {
+	tests := []struct {
+		in      string
+		wantErr error
+	}{
+		{"ABC 10", ErrInvalidCurrency},
+		{"10 XYZ", ErrInvalidCurrency},
+		{"10", ErrInvalidFormat},
+		{"USD 1.2.3", ErrInvalidFormat},
+		{"JPY 10.5", ErrInvalidFormat},
+		{"USD 1.000,00", ErrInvalidFormat},
+	}
+	for _, tt := range tests {
+		if _, err := Parse(tt.in); !errors.Is(err, tt.wantErr) {
+			t.Errorf("Parse(%q) error = %v, want %v", tt.in, err, tt.wantErr)
+		}
+	}
+}
+
+func TestParseWithCurrency(t *testing.T) {
+	got, err := ParseWithCurrency("$12.50", "cad")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := (Money{Amount: 1250, Currency: "CAD"}); got != want {
+		t.Errorf("got %+v, want %+v", got, want)
+	}
+	if _, err := ParseWithCurrency("EUR 12.50", "USD"); !errors.Is(err, ErrInvalidFormat) {
+		t.Errorf("conflicting code: error = %v, want %v", err, ErrInvalidFormat)
+	}
+	if _, err := ParseWithCurrency("12.50", "XYZ"); !errors.Is(err, ErrInvalidCurrency) {
+		t.Errorf("unknown currency: error = %v, want %v", err, ErrInvalidCurrency)
+	}
+}
+
+func TestParseRoundTripsString(t *testing.T) {
+	for _, m := range []Money{
+		{Amount: 4999, Currency: "USD"},
+		{Amount: -5, Currency: "EUR"},
+		{Amount: 1000, Currency: "JPY"},
+		{Amount: 19999, Currency: "KWD"},
+		{Amount: 0, Currency: "GBP"},
+	} {
+		got, err := Parse(m.String())
+		if err != nil {
+			t.Errorf("Parse(%q): %v", m.String(), err)
+			continue
+		}
+		if got != m {
+			t.Errorf("Parse(%q) = %+v, want %+v", m.String(), got, m)
+		}
+	}
+}
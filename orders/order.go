@@ -1,6 +1,8 @@
 package orders
 
 import (
+	"fmt"
+	"strings"
 	"time"
 
 	"github.com/devchuckcamp/gocommerce/money"
@@ -23,11 +25,28 @@ type Order struct {
 	TaxTotal      money.Money
 	ShippingTotal money.Money
 	Total         money.Money
+
+	// Promotions that produced DiscountTotal, in the order they applied
+	AppliedPromotions []OrderPromotion
+
+	// Currency conversion audit; set only when the order was converted
+	// from the cart's currency into a display currency.
+	OriginalTotal money.Money // Total before conversion
+	ExchangeRate  float64     // Units of Total.Currency per unit of OriginalTotal.Currency
+
+	// Inventory
+	ReservationID         string // Reference of the stock reserved for the order
+	InventoryCommitFailed bool   // Paid, but committing the reservation failed; stock needs reconciling
+
+	// Cancellation
+	CancellationReason CancellationReason
+	CancellationNote   string // Free-text detail accompanying the reason
 	
 	// Metadata
-	Notes         string
-	IPAddress     string
-	UserAgent     string
+	Notes     string
+	IPAddress string
+	UserAgent string
+	Metadata  map[string]string // Integration data (e.g., ERP IDs, channel tags); not used in pricing
 	
 	// Timestamps
 	CreatedAt   time.Time
@@ -38,17 +57,25 @@ type Order struct {
 
 // OrderItem represents an item in an order.
 type OrderItem struct {
-	ID            string
-	ProductID     string
-	VariantID     *string
-	SKU           string
-	Name          string
-	UnitPrice     money.Money
-	Quantity      int
+	ID             string
+	ProductID      string
+	VariantID      *string
+	SKU            string
+	Name           string
+	UnitPrice      money.Money
+	Quantity       int
 	DiscountAmount money.Money
-	TaxAmount     money.Money
-	Total         money.Money
-	Attributes    map[string]string
+	TaxAmount      money.Money
+	Total          money.Money
+	Attributes     map[string]string
+	Backordered    bool // Reserved beyond available stock; ships when restocked
+}
+
+// OrderPromotion records a promotion applied to an order and the discount it gave.
+type OrderPromotion struct {
+	Code   string // Empty for discounts applied without a code
+	Name   string
+	Amount money.Money
 }
 
 // OrderStatus represents the state of an order.
@@ -64,18 +91,68 @@ const (
 	OrderStatusRefunded   OrderStatus = "refunded"
 )
 
+// IsValid returns true if s is a known order status.
+func (s OrderStatus) IsValid() bool {
+	switch s {
+	case OrderStatusPending,
+		OrderStatusPaid,
+		OrderStatusProcessing,
+		OrderStatusShipped,
+		OrderStatusDelivered,
+		OrderStatusCanceled,
+		OrderStatusRefunded:
+		return true
+	}
+	return false
+}
+
+// ParseOrderStatus converts a raw string (e.g., from HTTP or a database row)
+// into an OrderStatus, returning ErrUnknownOrderStatus if it isn't a known status.
+func ParseOrderStatus(s string) (OrderStatus, error) {
+	status := OrderStatus(s)
+	if !status.IsValid() {
+		return "", fmt.Errorf("%w: %q", ErrUnknownOrderStatus, s)
+	}
+	return status, nil
+}
+
+// CancellationReason categorizes why an order was canceled.
+type CancellationReason string
+
+const (
+	CancellationReasonCustomerRequest CancellationReason = "customer_request"
+	CancellationReasonFraud           CancellationReason = "fraud"
+	CancellationReasonOutOfStock      CancellationReason = "out_of_stock"
+	CancellationReasonPaymentFailed   CancellationReason = "payment_failed"
+	CancellationReasonOther           CancellationReason = "other"
+)
+
+// IsValid returns true if r is a known cancellation reason.
+func (r CancellationReason) IsValid() bool {
+	switch r {
+	case CancellationReasonCustomerRequest,
+		CancellationReasonFraud,
+		CancellationReasonOutOfStock,
+		CancellationReasonPaymentFailed,
+		CancellationReasonOther:
+		return true
+	}
+	return false
+}
+
 // Address represents a shipping or billing address.
 type Address struct {
-	FirstName   string
-	LastName    string
-	Company     string
+	FirstName    string
+	LastName     string
+	Company      string
+	TaxID        string // Optional buyer tax ID (e.g., VAT number) for B2B invoices
 	AddressLine1 string
 	AddressLine2 string
-	City        string
-	State       string
-	PostalCode  string
-	Country     string
-	Phone       string
+	City         string
+	State        string
+	PostalCode   string
+	Country      string
+	Phone        string
 }
 
 // FullName returns the full name from the address.
@@ -93,6 +170,42 @@ func (a Address) IsComplete() bool {
 		a.Country != ""
 }
 
+// Clone returns a deep copy of o: items, their variant IDs and attributes,
+// metadata, and timestamps are all independent of the original, so an edit
+// can be prepared on the copy and discarded if it fails.
+func (o *Order) Clone() *Order {
+	clone := *o
+
+	if o.Items != nil {
+		clone.Items = make([]OrderItem, len(o.Items))
+		for i, item := range o.Items {
+			if item.VariantID != nil {
+				variantID := *item.VariantID
+				item.VariantID = &variantID
+			}
+			item.Attributes = copyMetadata(item.Attributes)
+			clone.Items[i] = item
+		}
+	}
+	if o.AppliedPromotions != nil {
+		clone.AppliedPromotions = append([]OrderPromotion(nil), o.AppliedPromotions...)
+	}
+	clone.Metadata = copyMetadata(o.Metadata)
+	clone.CompletedAt = copyTime(o.CompletedAt)
+	clone.CanceledAt = copyTime(o.CanceledAt)
+
+	return &clone
+}
+
+// copyTime returns an independent copy of an optional timestamp.
+func copyTime(t *time.Time) *time.Time {
+	if t == nil {
+		return nil
+	}
+	copied := *t
+	return &copied
+}
+
 // CanTransitionTo checks if an order can transition to a new status.
 func (o *Order) CanTransitionTo(newStatus OrderStatus) bool {
 	transitions := map[OrderStatus][]OrderStatus{
@@ -174,3 +287,97 @@ func (o *Order) ItemCount() int {
 	}
 	return count
 }
+
+// CurrencyConversion describes converting an order into a display currency.
+type CurrencyConversion struct {
+	Currency string  // Target currency
+	Rate     float64 // Units of Currency per unit of the cart currency
+}
+
+// applyConversion converts all order amounts using conv and records the
+// original total and rate. Unit prices and per-item adjustments are converted
+// and every total is rebuilt from them, so the order stays internally
+// consistent (see Validate).
+func (o *Order) applyConversion(conv CurrencyConversion) {
+	convert := func(m money.Money) money.Money {
+		return m.Convert(conv.Currency, conv.Rate)
+	}
+
+	subtotal := money.Zero(conv.Currency)
+	discountTotal := money.Zero(conv.Currency)
+	for i := range o.Items {
+		item := &o.Items[i]
+		item.UnitPrice = convert(item.UnitPrice)
+		item.DiscountAmount = convert(item.DiscountAmount)
+		item.TaxAmount = convert(item.TaxAmount)
+
+		lineSubtotal := item.UnitPrice.MultiplyInt(item.Quantity)
+		item.Total = money.Money{
+			Amount:   lineSubtotal.Amount - item.DiscountAmount.Amount + item.TaxAmount.Amount,
+			Currency: conv.Currency,
+		}
+		subtotal.Amount += lineSubtotal.Amount
+		discountTotal.Amount += item.DiscountAmount.Amount
+	}
+
+	for i := range o.AppliedPromotions {
+		o.AppliedPromotions[i].Amount = convert(o.AppliedPromotions[i].Amount)
+	}
+
+	o.OriginalTotal = o.Total
+	o.ExchangeRate = conv.Rate
+	o.Subtotal = subtotal
+	o.DiscountTotal = discountTotal
+	o.TaxTotal = convert(o.TaxTotal)
+	o.ShippingTotal = convert(o.ShippingTotal)
+	o.Total = money.Money{
+		Amount:   o.Subtotal.Amount - o.DiscountTotal.Amount + o.TaxTotal.Amount + o.ShippingTotal.Amount,
+		Currency: conv.Currency,
+	}
+}
+
+// Validate checks that the order's amounts add up exactly: each item's Total
+// is its unit price times quantity less discount plus tax, the items sum to
+// Subtotal and DiscountTotal, and Total is Subtotal - DiscountTotal + TaxTotal
+// + ShippingTotal, all in one currency. It returns an error wrapping
+// ErrInconsistentTotals describing the first mismatch found.
+func (o *Order) Validate() error {
+	currency := o.Total.Currency
+	for _, m := range []money.Money{o.Subtotal, o.DiscountTotal, o.TaxTotal, o.ShippingTotal} {
+		if !strings.EqualFold(m.Currency, currency) {
+			return fmt.Errorf("%w: %v", ErrInconsistentTotals, money.ErrCurrencyMismatch)
+		}
+	}
+
+	var subtotal, discountTotal int64
+	for _, item := range o.Items {
+		for _, m := range []money.Money{item.UnitPrice, item.DiscountAmount, item.TaxAmount, item.Total} {
+			if !strings.EqualFold(m.Currency, currency) {
+				return fmt.Errorf("%w: item %s: %v", ErrInconsistentTotals, item.ID, money.ErrCurrencyMismatch)
+			}
+		}
+
+		lineSubtotal := item.UnitPrice.Amount * int64(item.Quantity)
+		if want := lineSubtotal - item.DiscountAmount.Amount + item.TaxAmount.Amount; item.Total.Amount != want {
+			return fmt.Errorf("%w: item %s total is %s, parts sum to %s", ErrInconsistentTotals,
+				item.ID, item.Total, money.Money{Amount: want, Currency: currency})
+		}
+		subtotal += lineSubtotal
+		discountTotal += item.DiscountAmount.Amount
+	}
+
+	if o.Subtotal.Amount != subtotal {
+		return fmt.Errorf("%w: subtotal is %s, items sum to %s", ErrInconsistentTotals,
+			o.Subtotal, money.Money{Amount: subtotal, Currency: currency})
+	}
+	if o.DiscountTotal.Amount != discountTotal {
+		return fmt.Errorf("%w: discount total is %s, items sum to %s", ErrInconsistentTotals,
+			o.DiscountTotal, money.Money{Amount: discountTotal, Currency: currency})
+	}
+	want := o.Subtotal.Amount - o.DiscountTotal.Amount + o.TaxTotal.Amount + o.ShippingTotal.Amount
+	if o.Total.Amount != want {
+		return fmt.Errorf("%w: total is %s, parts sum to %s", ErrInconsistentTotals,
+			o.Total, money.Money{Amount: want, Currency: currency})
+	}
+	return nil
+}
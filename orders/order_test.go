@@ -0,0 +1,104 @@
+package orders
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/devchuckcamp/gocommerce/money"
+)
+
+func TestOrderValidate(t *testing.T) {
+	valid := func() *Order {
+		return &Order{
+			Items: []OrderItem{{
+				ID:             "i1",
+				UnitPrice:      usd(1000),
+				Quantity:       2,
+				DiscountAmount: usd(200),
+				TaxAmount:      usd(144),
+				Total:          usd(1944),
+			}},
+			Subtotal:      usd(2000),
+			DiscountTotal: usd(200),
+			TaxTotal:      usd(144),
+			ShippingTotal: usd(500),
+			Total:         usd(2444),
+		}
+	}
+
+	if err := valid().Validate(); err != nil {
+		t.Errorf("valid order: %v", err)
+	}
+
+	lower := valid()
+	lower.Total.Currency = "usd"
+	lower.Items[0].TaxAmount.Currency = "Usd"
+	if err := lower.Validate(); err != nil {
+		t.Errorf("currency case differs: %v", err)
+	}
+
+	tests := map[string]func(o *Order){
+		"item total off by a cent": func(o *Order) { o.Items[0].Total.Amount++ },
+		"total off by a cent":      func(o *Order) { o.Total.Amount++ },
+		"subtotal mismatch":        func(o *Order) { o.Subtotal.Amount = 1900; o.Total.Amount = 2344 },
+		"other currency":           func(o *Order) { o.ShippingTotal = money.Money{Amount: 500, Currency: "EUR"} },
+	}
+	for name, corrupt := range tests {
+		o := valid()
+		corrupt(o)
+		if err := o.Validate(); !errors.Is(err, ErrInconsistentTotals) {
+			t.Errorf("%s: error = %v, want %v", name, err, ErrInconsistentTotals)
+		}
+	}
+}
+
+func TestParseOrderStatus(t *testing.T) {
+	for _, raw := range []string{"pending", "paid", "processing", "shipped", "delivered", "canceled", "refunded"} {
+		status, err := ParseOrderStatus(raw)
+		if err != nil || string(status) != raw {
+			t.Errorf("ParseOrderStatus(%q) = %q, %v", raw, status, err)
+		}
+	}
+	for _, raw := range []string{"", "PAID", "cancelled", "archived"} {
+		if _, err := ParseOrderStatus(raw); !errors.Is(err, ErrUnknownOrderStatus) {
+			t.Errorf("ParseOrderStatus(%q): error = %v, want %v", raw, err, ErrUnknownOrderStatus)
+		}
+	}
+}
+
+func TestOrderClone(t *testing.T) {
+	variantID := "v1"
+	completed := time.Now()
+	o := &Order{
+		ID:                "order-1",
+		Items:             []OrderItem{{ID: "i1", VariantID: &variantID, Attributes: map[string]string{"size": "M"}}},
+		AppliedPromotions: []OrderPromotion{{Code: "SAVE10"}},
+		Metadata:          map[string]string{"channel": "web"},
+		CompletedAt:       &completed,
+	}
+
+	clone := o.Clone()
+	*clone.Items[0].VariantID = "v2"
+	clone.Items[0].Attributes["size"] = "L"
+	clone.Items = append(clone.Items, OrderItem{ID: "i2"})
+	clone.AppliedPromotions[0].Code = "OTHER"
+	clone.Metadata["channel"] = "pos"
+	*clone.CompletedAt = completed.Add(time.Hour)
+
+	if *o.Items[0].VariantID != "v1" || o.Items[0].Attributes["size"] != "M" || len(o.Items) != 1 {
+		t.Errorf("items changed through clone: %+v", o.Items)
+	}
+	if o.AppliedPromotions[0].Code != "SAVE10" {
+		t.Errorf("applied promotion = %q, want SAVE10", o.AppliedPromotions[0].Code)
+	}
+	if o.Metadata["channel"] != "web" {
+		t.Errorf("metadata channel = %q, want web", o.Metadata["channel"])
+	}
+	if !o.CompletedAt.Equal(completed) {
+		t.Errorf("CompletedAt = %v, want %v", o.CompletedAt, completed)
+	}
+	if (&Order{}).Clone().Items != nil {
+		t.Error("nil items cloned to a non-nil slice")
+	}
+}
@@ -0,0 +1,83 @@
+package orders
+
+import (
+	"fmt"
+	"strings"
+)
+
+// SellerProfile identifies the merchant on receipts and invoices.
+type SellerProfile struct {
+	CompanyName string
+	TaxID       string // e.g., VAT or EIN; omitted from receipts when empty
+	Address     Address
+	Email       string
+}
+
+// RenderReceipt renders a plain-text receipt for order issued by seller.
+// Company names and tax IDs of the seller and the billing address are
+// included only when present, so the same receipt serves B2C and B2B orders.
+func RenderReceipt(order *Order, seller SellerProfile) string {
+	var b strings.Builder
+
+	writeLine(&b, seller.CompanyName)
+	writeAddress(&b, seller.Address)
+	if seller.TaxID != "" {
+		writeLine(&b, "Tax ID: "+seller.TaxID)
+	}
+	writeLine(&b, seller.Email)
+	b.WriteString("\n")
+
+	fmt.Fprintf(&b, "Receipt for order %s\n", order.OrderNumber)
+	fmt.Fprintf(&b, "Date: %s\n\n", order.CreatedAt.Format("2006-01-02"))
+
+	billing := order.BillingAddress
+	b.WriteString("Bill to:\n")
+	writeLine(&b, strings.TrimSpace(billing.FullName()))
+	writeLine(&b, billing.Company)
+	writeAddress(&b, billing)
+	if billing.TaxID != "" {
+		writeLine(&b, "Tax ID: "+billing.TaxID)
+	}
+	b.WriteString("\n")
+
+	for _, item := range order.Items {
+		fmt.Fprintf(&b, "%d x %s @ %s = %s\n", item.Quantity, item.Name, item.UnitPrice, item.Total)
+	}
+	b.WriteString("\n")
+
+	fmt.Fprintf(&b, "Subtotal: %s\n", order.Subtotal)
+	if order.DiscountTotal.IsPositive() {
+		fmt.Fprintf(&b, "Discount: -%s\n", order.DiscountTotal)
+	}
+	fmt.Fprintf(&b, "Shipping: %s\n", order.ShippingTotal)
+	fmt.Fprintf(&b, "Tax: %s\n", order.TaxTotal)
+	fmt.Fprintf(&b, "Total: %s\n", order.Total)
+
+	return b.String()
+}
+
+// writeLine writes s followed by a newline, skipping empty values.
+func writeLine(b *strings.Builder, s string) {
+	if s != "" {
+		b.WriteString(s)
+		b.WriteString("\n")
+	}
+}
+
+// writeAddress writes the postal lines of a.
+func writeAddress(b *strings.Builder, a Address) {
+	writeLine(b, a.AddressLine1)
+	writeLine(b, a.AddressLine2)
+	writeLine(b, strings.Join(nonEmpty(a.City, a.State, a.PostalCode), ", "))
+	writeLine(b, a.Country)
+}
+
+func nonEmpty(values ...string) []string {
+	out := make([]string, 0, len(values))
+	for _, v := range values {
+		if v != "" {
+			out = append(out, v)
+		}
+	}
+	return out
+}
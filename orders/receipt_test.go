@@ -0,0 +1,52 @@
+package orders
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRenderReceipt(t *testing.T) {
+	seller := SellerProfile{
+		CompanyName: "Acme GmbH",
+		TaxID:       "DE811907980",
+		Address:     Address{AddressLine1: "Hauptstr. 1", City: "Berlin", PostalCode: "10115", Country: "DE"},
+		Email:       "billing@acme.example",
+	}
+	order := &Order{
+		OrderNumber: "ORD-1001",
+		CreatedAt:   time.Date(2024, 3, 9, 10, 0, 0, 0, time.UTC),
+		BillingAddress: Address{
+			FirstName: "Ada", LastName: "Lovelace", Company: "Analytical Ltd", TaxID: "GB123456789",
+			AddressLine1: "1 Main St", City: "London", PostalCode: "N1 9GU", Country: "GB",
+		},
+		Items:         []OrderItem{{Name: "Mug", Quantity: 2, UnitPrice: usd(1000), Total: usd(2000)}},
+		Subtotal:      usd(2000),
+		DiscountTotal: usd(0),
+		ShippingTotal: usd(500),
+		TaxTotal:      usd(0),
+		Total:         usd(2500),
+	}
+
+	receipt := RenderReceipt(order, seller)
+	for _, want := range []string{
+		"Acme GmbH\nHauptstr. 1\nBerlin, 10115\nDE\nTax ID: DE811907980\n",
+		"Receipt for order ORD-1001\nDate: 2024-03-09\n",
+		"Bill to:\nAda Lovelace\nAnalytical Ltd\n1 Main St\nLondon, N1 9GU\nGB\nTax ID: GB123456789\n",
+		"2 x Mug @ USD 10.00 = USD 20.00\n",
+		"Total: USD 25.00\n",
+	} {
+		if !strings.Contains(receipt, want) {
+			t.Errorf("receipt missing %q:\n%s", want, receipt)
+		}
+	}
+	if strings.Contains(receipt, "Discount:") {
+		t.Errorf("receipt shows a zero discount:\n%s", receipt)
+	}
+
+	order.BillingAddress.Company, order.BillingAddress.TaxID = "", ""
+	seller.TaxID = ""
+	if receipt := RenderReceipt(order, seller); strings.Contains(receipt, "Tax ID") {
+		t.Errorf("B2C receipt shows a tax ID:\n%s", receipt)
+	}
+}
@@ -7,16 +7,23 @@ import (
 
 	"github.com/devchuckcamp/gocommerce/cart"
 	"github.com/devchuckcamp/gocommerce/inventory"
+	"github.com/devchuckcamp/gocommerce/money"
 	"github.com/devchuckcamp/gocommerce/payments"
 	"github.com/devchuckcamp/gocommerce/pricing"
 )
 
 var (
-	ErrOrderNotFound      = errors.New("order not found")
-	ErrInvalidStatus      = errors.New("invalid status transition")
-	ErrEmptyCart          = errors.New("cart is empty")
-	ErrInvalidAddress     = errors.New("invalid address")
-	ErrPaymentFailed      = errors.New("payment failed")
+	ErrOrderNotFound             = errors.New("order not found")
+	ErrInvalidStatus             = errors.New("invalid status transition")
+	ErrEmptyCart                 = errors.New("cart is empty")
+	ErrInvalidAddress            = errors.New("invalid address")
+	ErrPaymentFailed             = errors.New("payment failed")
+	ErrInvalidCancellationReason = errors.New("invalid cancellation reason")
+	ErrOrderNotCancelable        = errors.New("order cannot be canceled")
+	ErrUnknownOrderStatus        = errors.New("unknown order status")
+	ErrInvalidConversion         = errors.New("invalid currency conversion")
+	ErrInconsistentTotals        = errors.New("order totals do not add up")
+	ErrOrderAccessDenied         = errors.New("order belongs to another user")
 )
 
 // Repository defines methods for order persistence.
@@ -24,8 +31,20 @@ type Repository interface {
 	FindByID(ctx context.Context, id string) (*Order, error)
 	FindByOrderNumber(ctx context.Context, orderNumber string) (*Order, error)
 	FindByUserID(ctx context.Context, userID string, filter OrderFilter) ([]*Order, error)
+	// FindAll returns orders of any user matching the filter, newest first.
+	FindAll(ctx context.Context, filter OrderFilter) ([]*Order, error)
 	Save(ctx context.Context, order *Order) error
 	Delete(ctx context.Context, id string) error
+
+	// SumTotals sums order totals matching the filter (Status, DateFrom, DateTo).
+	// It returns money.ErrCurrencyMismatch if matching orders use more than one
+	// currency, and a zero Money with no currency if nothing matches.
+	SumTotals(ctx context.Context, filter OrderFilter) (money.Money, error)
+	// SumTotalsByCurrency is like SumTotals but segments the sums by currency.
+	SumTotalsByCurrency(ctx context.Context, filter OrderFilter) (map[string]money.Money, error)
+	// CountByCancellationReason counts canceled orders matching the filter
+	// (DateFrom, DateTo) per cancellation reason.
+	CountByCancellationReason(ctx context.Context, filter OrderFilter) (map[CancellationReason]int, error)
 }
 
 // OrderFilter defines query filters for orders.
@@ -41,23 +60,28 @@ type OrderFilter struct {
 type Service interface {
 	CreateFromCart(ctx context.Context, req CreateOrderRequest) (*Order, error)
 	GetOrder(ctx context.Context, id string) (*Order, error)
+	GetOrderForUser(ctx context.Context, orderID, userID string, isAdmin bool) (*Order, error)
 	GetUserOrders(ctx context.Context, userID string, filter OrderFilter) ([]*Order, error)
 	UpdateStatus(ctx context.Context, orderID string, status OrderStatus) (*Order, error)
-	CancelOrder(ctx context.Context, orderID string, reason string) (*Order, error)
+	BulkUpdateStatus(ctx context.Context, orderIDs []string, status OrderStatus) map[string]error
+	CancelOrder(ctx context.Context, orderID string, reason CancellationReason, note string) (*Order, error)
+	CancelStalePending(ctx context.Context, olderThan time.Time) (int, error)
 }
 
 // CreateOrderRequest contains data needed to create an order.
 type CreateOrderRequest struct {
-	Cart            *cart.Cart
-	UserID          string
-	ShippingAddress Address
-	BillingAddress  Address
-	PaymentMethodID string
-	PromotionCodes  []string
+	Cart             *cart.Cart
+	UserID           string
+	ShippingAddress  Address
+	BillingAddress   Address
+	PaymentMethodID  string
+	PromotionCodes   []string
 	ShippingMethodID string
-	Notes           string
-	IPAddress       string
-	UserAgent       string
+	Notes            string
+	IPAddress        string
+	UserAgent        string
+	Metadata         map[string]string
+	Conversion       *CurrencyConversion // Optional; prices the order in a display currency
 }
 
 // OrderService implements the Service interface.
@@ -94,6 +118,10 @@ func (s *OrderService) CreateFromCart(ctx context.Context, req CreateOrderReques
 	if req.Cart == nil || req.Cart.IsEmpty() {
 		return nil, ErrEmptyCart
 	}
+
+	if err := req.Cart.Validate(); err != nil {
+		return nil, err
+	}
 	
 	if !req.ShippingAddress.IsComplete() {
 		return nil, ErrInvalidAddress
@@ -102,13 +130,21 @@ func (s *OrderService) CreateFromCart(ctx context.Context, req CreateOrderReques
 	if !req.BillingAddress.IsComplete() {
 		req.BillingAddress = req.ShippingAddress
 	}
+
+	if req.Conversion != nil && (req.Conversion.Currency == "" || req.Conversion.Rate <= 0) {
+		return nil, ErrInvalidConversion
+	}
 	
-	// Calculate pricing
-	shippingMethodID := req.ShippingMethodID
+	// Calculate pricing. Without a chosen method, PriceCart falls back to its
+	// default shipping method, if one is configured.
+	var shippingMethodID *string
+	if req.ShippingMethodID != "" {
+		shippingMethodID = &req.ShippingMethodID
+	}
 	pricingResult, err := s.pricingService.PriceCart(ctx, pricing.PriceCartRequest{
 		Cart:             req.Cart,
 		PromotionCodes:   req.PromotionCodes,
-		ShippingMethodID: &shippingMethodID,
+		ShippingMethodID: shippingMethodID,
 		ShippingAddress: &pricing.Address{
 			Country:    req.ShippingAddress.Country,
 			State:      req.ShippingAddress.State,
@@ -121,16 +157,37 @@ func (s *OrderService) CreateFromCart(ctx context.Context, req CreateOrderReques
 		return nil, err
 	}
 	
-	// Reserve inventory
+	// Reserve inventory, one reservation per SKU since reservations for the
+	// same reference and SKU replace each other. Each is remembered so a
+	// failure can release exactly what was reserved. SKUs whose products
+	// accept backorders are reserved even when short, and their lines are
+	// marked backordered.
+	var reservationID string
+	var reserved []inventory.StockRequest
+	backordered := make(map[string]bool)
 	if s.inventoryService != nil {
-		reservationID := s.idGenerator()
+		reservationID = s.idGenerator()
+		allowBackorder := make(map[string]bool)
 		for _, item := range req.Cart.Items {
-			err := s.inventoryService.Reserve(ctx, item.SKU, item.Quantity, reservationID)
+			if item.AllowBackorder {
+				allowBackorder[item.SKU] = true
+			}
+		}
+		for _, request := range stockRequests(req.Cart.Items) {
+			var err error
+			if allowBackorder[request.SKU] {
+				var short int
+				short, err = s.inventoryService.ReserveAllowingBackorder(ctx, request.SKU, request.Quantity, reservationID)
+				backordered[request.SKU] = short > 0
+			} else {
+				err = s.inventoryService.Reserve(ctx, request.SKU, request.Quantity, reservationID)
+			}
 			if err != nil {
 				// Rollback previous reservations
-				s.rollbackInventory(ctx, reservationID)
+				s.rollbackInventory(ctx, reservationID, reserved)
 				return nil, err
 			}
+			reserved = append(reserved, request)
 		}
 	}
 	
@@ -154,36 +211,76 @@ func (s *OrderService) CreateFromCart(ctx context.Context, req CreateOrderReques
 			TaxAmount:      itemPrice.TaxAmount,
 			Total:          itemPrice.Total,
 			Attributes:     cartItem.Attributes,
+			Backordered:    backordered[cartItem.SKU],
 		}
 	}
 	
+	appliedPromotions := make([]OrderPromotion, len(pricingResult.AppliedDiscounts))
+	for i, discount := range pricingResult.AppliedDiscounts {
+		appliedPromotions[i] = OrderPromotion{
+			Code:   discount.Code,
+			Name:   discount.Name,
+			Amount: discount.Amount,
+		}
+	}
+
 	// Create order
 	order := &Order{
-		ID:              s.idGenerator(),
-		OrderNumber:     s.orderNumberGen(),
-		UserID:          req.UserID,
-		Status:          OrderStatusPending,
-		Items:           orderItems,
-		ShippingAddress: req.ShippingAddress,
-		BillingAddress:  req.BillingAddress,
-		PaymentMethodID: req.PaymentMethodID,
-		Subtotal:        pricingResult.Subtotal,
-		DiscountTotal:   pricingResult.DiscountTotal,
-		TaxTotal:        pricingResult.TaxTotal,
-		ShippingTotal:   pricingResult.ShippingTotal,
-		Total:           pricingResult.Total,
-		Notes:           req.Notes,
-		IPAddress:       req.IPAddress,
-		UserAgent:       req.UserAgent,
-		CreatedAt:       time.Now(),
-		UpdatedAt:       time.Now(),
+		ID:                s.idGenerator(),
+		OrderNumber:       s.orderNumberGen(),
+		UserID:            req.UserID,
+		Status:            OrderStatusPending,
+		Items:             orderItems,
+		ShippingAddress:   req.ShippingAddress,
+		BillingAddress:    req.BillingAddress,
+		PaymentMethodID:   req.PaymentMethodID,
+		Subtotal:          pricingResult.Subtotal,
+		DiscountTotal:     pricingResult.DiscountTotal,
+		TaxTotal:          pricingResult.TaxTotal,
+		ShippingTotal:     pricingResult.ShippingTotal,
+		Total:             pricingResult.Total,
+		AppliedPromotions: appliedPromotions,
+		Notes:             req.Notes,
+		IPAddress:         req.IPAddress,
+		UserAgent:         req.UserAgent,
+		Metadata:          copyMetadata(req.Metadata),
+		ReservationID:     reservationID,
+		CreatedAt:         time.Now(),
+		UpdatedAt:         time.Now(),
+	}
+
+	if req.Conversion != nil {
+		order.applyConversion(*req.Conversion)
+	}
+
+	if err := order.Validate(); err != nil {
+		s.rollbackInventory(ctx, reservationID, reserved)
+		return nil, err
 	}
 	
 	// Save order
 	err = s.repo.Save(ctx, order)
 	if err != nil {
+		s.rollbackInventory(ctx, reservationID, reserved)
 		return nil, err
 	}
+
+	// Count promotion usage only for codes that actually applied, once the
+	// order exists. The increment is conditional, so concurrent orders can't
+	// exceed a limit; an order whose codes can't be redeemed is removed.
+	var codes []string
+	for _, discount := range pricingResult.AppliedDiscounts {
+		if !discount.Automatic {
+			codes = append(codes, discount.Code)
+		}
+	}
+	if len(codes) > 0 {
+		if err := s.pricingService.RedeemPromotions(ctx, codes, req.UserID); err != nil {
+			_ = s.repo.Delete(ctx, order.ID)
+			s.rollbackInventory(ctx, reservationID, reserved)
+			return nil, err
+		}
+	}
 	
 	// Process payment if gateway available
 	if s.paymentGateway != nil {
@@ -200,6 +297,7 @@ func (s *OrderService) CreateFromCart(ctx context.Context, req CreateOrderReques
 		
 		if intent.Status == payments.IntentStatusSucceeded {
 			order.UpdateStatus(OrderStatusPaid)
+			s.commitInventory(ctx, order)
 			s.repo.Save(ctx, order)
 		}
 	}
@@ -212,12 +310,29 @@ func (s *OrderService) GetOrder(ctx context.Context, id string) (*Order, error)
 	return s.repo.FindByID(ctx, id)
 }
 
+// GetOrderForUser retrieves an order on behalf of userID, returning
+// ErrOrderAccessDenied if it belongs to someone else. Admins (isAdmin) may
+// read any order.
+func (s *OrderService) GetOrderForUser(ctx context.Context, orderID, userID string, isAdmin bool) (*Order, error) {
+	order, err := s.repo.FindByID(ctx, orderID)
+	if err != nil {
+		return nil, err
+	}
+
+	if !isAdmin && (userID == "" || order.UserID != userID) {
+		return nil, ErrOrderAccessDenied
+	}
+
+	return order, nil
+}
+
 // GetUserOrders retrieves orders for a user.
 func (s *OrderService) GetUserOrders(ctx context.Context, userID string, filter OrderFilter) ([]*Order, error) {
 	return s.repo.FindByUserID(ctx, userID, filter)
 }
 
-// UpdateStatus updates the order status.
+// UpdateStatus updates the order status. Moving to OrderStatusPaid commits
+// the order's inventory reservation (see commitInventory).
 func (s *OrderService) UpdateStatus(ctx context.Context, orderID string, status OrderStatus) (*Order, error) {
 	order, err := s.repo.FindByID(ctx, orderID)
 	if err != nil {
@@ -227,6 +342,9 @@ func (s *OrderService) UpdateStatus(ctx context.Context, orderID string, status
 	if !order.UpdateStatus(status) {
 		return nil, ErrInvalidStatus
 	}
+	if status == OrderStatusPaid {
+		s.commitInventory(ctx, order)
+	}
 	
 	err = s.repo.Save(ctx, order)
 	if err != nil {
@@ -236,26 +354,50 @@ func (s *OrderService) UpdateStatus(ctx context.Context, orderID string, status
 	return order, nil
 }
 
-// CancelOrder cancels an order.
-func (s *OrderService) CancelOrder(ctx context.Context, orderID string, reason string) (*Order, error) {
+// BulkUpdateStatus updates the status of many orders (e.g., marking a batch shipped).
+// Each order is validated and saved independently; a failure on one order does not
+// abort the batch. The result maps every order ID to its outcome (nil on success).
+func (s *OrderService) BulkUpdateStatus(ctx context.Context, orderIDs []string, status OrderStatus) map[string]error {
+	results := make(map[string]error, len(orderIDs))
+	for _, orderID := range orderIDs {
+		_, err := s.UpdateStatus(ctx, orderID, status)
+		results[orderID] = err
+	}
+	return results
+}
+
+// CancelOrder cancels an order, recording a structured reason and an optional
+// free-text note. An empty reason is recorded as CancellationReasonOther.
+func (s *OrderService) CancelOrder(ctx context.Context, orderID string, reason CancellationReason, note string) (*Order, error) {
+	if reason == "" {
+		reason = CancellationReasonOther
+	}
+	if !reason.IsValid() {
+		return nil, ErrInvalidCancellationReason
+	}
+
 	order, err := s.repo.FindByID(ctx, orderID)
 	if err != nil {
 		return nil, err
 	}
 	
 	if !order.IsCancelable() {
-		return nil, errors.New("order cannot be canceled")
+		return nil, ErrOrderNotCancelable
 	}
 	
-	// Release inventory
+	// Release inventory. Stock is reserved under the order's ReservationID;
+	// orders saved before it existed reserved under their own ID.
 	if s.inventoryService != nil {
-		for _, item := range order.Items {
-			_ = s.inventoryService.Release(ctx, item.SKU, item.Quantity, order.ID)
+		reservationID := order.ReservationID
+		if reservationID == "" {
+			reservationID = order.ID
 		}
+		_ = s.inventoryService.Release(ctx, "", 0, reservationID)
 	}
 	
 	order.UpdateStatus(OrderStatusCanceled)
-	order.Notes = order.Notes + "\nCanceled: " + reason
+	order.CancellationReason = reason
+	order.CancellationNote = note
 	
 	err = s.repo.Save(ctx, order)
 	if err != nil {
@@ -265,9 +407,84 @@ func (s *OrderService) CancelOrder(ctx context.Context, orderID string, reason s
 	return order, nil
 }
 
-// rollbackInventory releases reserved inventory.
-func (s *OrderService) rollbackInventory(ctx context.Context, reservationID string) {
-	if s.inventoryService != nil {
-		_ = s.inventoryService.Release(ctx, "", 0, reservationID)
+// CancelStalePending cancels pending orders created before olderThan, releasing
+// their inventory, and returns how many were canceled. Orders that stop being
+// cancelable in the meantime (e.g., paid concurrently) are skipped; other
+// failures don't stop the sweep and are returned together.
+func (s *OrderService) CancelStalePending(ctx context.Context, olderThan time.Time) (int, error) {
+	status := OrderStatusPending
+	stale, err := s.repo.FindAll(ctx, OrderFilter{
+		Status: &status,
+		DateTo: &olderThan,
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	canceled := 0
+	var errs []error
+	for _, order := range stale {
+		if !order.CreatedAt.Before(olderThan) {
+			continue
+		}
+		_, err := s.CancelOrder(ctx, order.ID, CancellationReasonOther, "unpaid order expired")
+		if errors.Is(err, ErrOrderNotCancelable) {
+			continue
+		}
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		canceled++
+	}
+
+	return canceled, errors.Join(errs...)
+}
+
+// commitInventory turns the order's reserved stock into a stock decrement
+// once it is paid. Payment has already been taken, so a failed commit
+// doesn't undo it: the order stays paid with InventoryCommitFailed set for
+// reconciliation.
+func (s *OrderService) commitInventory(ctx context.Context, order *Order) {
+	if s.inventoryService == nil || order.ReservationID == "" {
+		return
+	}
+	order.InventoryCommitFailed = s.inventoryService.Commit(ctx, order.ReservationID) != nil
+}
+
+// stockRequests sums cart item quantities per SKU, in order of first appearance.
+func stockRequests(items []cart.CartItem) []inventory.StockRequest {
+	var requests []inventory.StockRequest
+	index := make(map[string]int)
+	for _, item := range items {
+		if i, ok := index[item.SKU]; ok {
+			requests[i].Quantity += item.Quantity
+			continue
+		}
+		index[item.SKU] = len(requests)
+		requests = append(requests, inventory.StockRequest{SKU: item.SKU, Quantity: item.Quantity})
+	}
+	return requests
+}
+
+// rollbackInventory releases each reservation made under reservationID.
+func (s *OrderService) rollbackInventory(ctx context.Context, reservationID string, reserved []inventory.StockRequest) {
+	if s.inventoryService == nil {
+		return
+	}
+	for _, r := range reserved {
+		_ = s.inventoryService.Release(ctx, r.SKU, r.Quantity, reservationID)
+	}
+}
+
+// copyMetadata copies metadata so later changes by the caller don't leak into the order.
+func copyMetadata(metadata map[string]string) map[string]string {
+	if metadata == nil {
+		return nil
+	}
+	out := make(map[string]string, len(metadata))
+	for k, v := range metadata {
+		out[k] = v
 	}
+	return out
 }
@@ -0,0 +1,682 @@
+package orders
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/devchuckcamp/gocommerce/cart"
+	"github.com/devchuckcamp/gocommerce/inventory"
+	"github.com/devchuckcamp/gocommerce/money"
+	"github.com/devchuckcamp/gocommerce/pricing"
+	"github.com/devchuckcamp/gocommerce/shipping"
+	"github.com/devchuckcamp/gocommerce/tax"
+)
+
+// memoryRepo is an in-memory Repository for the methods the service uses.
+type memoryRepo struct {
+	Repository
+	orders  map[string]*Order
+	saveErr error
+}
+
+func newMemoryRepo() *memoryRepo {
+	return &memoryRepo{orders: make(map[string]*Order)}
+}
+
+func (r *memoryRepo) FindByID(ctx context.Context, id string) (*Order, error) {
+	order, ok := r.orders[id]
+	if !ok {
+		return nil, ErrOrderNotFound
+	}
+	return order, nil
+}
+
+func (r *memoryRepo) FindAll(ctx context.Context, filter OrderFilter) ([]*Order, error) {
+	var result []*Order
+	for _, order := range r.orders {
+		if filter.Status != nil && order.Status != *filter.Status {
+			continue
+		}
+		if filter.DateTo != nil && order.CreatedAt.After(*filter.DateTo) {
+			continue
+		}
+		result = append(result, order)
+	}
+	return result, nil
+}
+
+func (r *memoryRepo) Save(ctx context.Context, order *Order) error {
+	if r.saveErr != nil {
+		return r.saveErr
+	}
+	r.orders[order.ID] = order
+	return nil
+}
+
+func (r *memoryRepo) Delete(ctx context.Context, id string) error {
+	delete(r.orders, id)
+	return nil
+}
+
+// promotionRepo is an in-memory pricing.PromotionRepository.
+type promotionRepo struct {
+	promotions map[string]*pricing.Promotion
+	uses       map[string][]string
+}
+
+func newPromotionRepo(promotions ...*pricing.Promotion) *promotionRepo {
+	r := &promotionRepo{
+		promotions: make(map[string]*pricing.Promotion),
+		uses:       make(map[string][]string),
+	}
+	for _, p := range promotions {
+		r.promotions[p.Code] = p
+	}
+	return r
+}
+
+func (r *promotionRepo) FindByCode(ctx context.Context, code string) (*pricing.Promotion, error) {
+	p, ok := r.promotions[code]
+	if !ok {
+		return nil, pricing.ErrPromotionNotFound
+	}
+	copied := *p
+	return &copied, nil
+}
+
+func (r *promotionRepo) FindActive(ctx context.Context) ([]*pricing.Promotion, error) {
+	return nil, nil
+}
+
+func (r *promotionRepo) Save(ctx context.Context, p *pricing.Promotion) error {
+	r.promotions[p.Code] = p
+	return nil
+}
+
+func (r *promotionRepo) IncrementUsage(ctx context.Context, code, userID string) error {
+	p, ok := r.promotions[code]
+	if !ok {
+		return pricing.ErrPromotionNotFound
+	}
+	if p.UsageLimit > 0 && p.UsageCount >= p.UsageLimit {
+		return pricing.ErrPromotionUsageExceeded
+	}
+	if userID != "" && p.PerUserLimit > 0 {
+		if used, _ := r.CountUsageByUser(ctx, code, userID); used >= p.PerUserLimit {
+			return pricing.ErrPromotionAlreadyUsed
+		}
+	}
+	p.UsageCount++
+	r.uses[code] = append(r.uses[code], userID)
+	return nil
+}
+
+func (r *promotionRepo) DecrementUsage(ctx context.Context, code, userID string) error {
+	p, ok := r.promotions[code]
+	if !ok {
+		return pricing.ErrPromotionNotFound
+	}
+	p.UsageCount--
+	uses := r.uses[code]
+	for i := len(uses) - 1; i >= 0; i-- {
+		if uses[i] == userID {
+			r.uses[code] = append(uses[:i], uses[i+1:]...)
+			break
+		}
+	}
+	return nil
+}
+
+func (r *promotionRepo) CountUsageByUser(ctx context.Context, code, userID string) (int, error) {
+	n := 0
+	for _, u := range r.uses[code] {
+		if u == userID {
+			n++
+		}
+	}
+	return n, nil
+}
+
+type fixture struct {
+	service    *OrderService
+	repo       *memoryRepo
+	promotions *promotionRepo
+	inventory  *inventory.MemoryService
+}
+
+// newFixture stocks each SKU with onHand units in the default warehouse.
+func newFixture(t *testing.T, onHand map[string]int, promotions ...*pricing.Promotion) *fixture {
+	t.Helper()
+	ctx := context.Background()
+
+	invRepo := inventory.NewMemoryRepository()
+	for sku, qty := range onHand {
+		if err := invRepo.UpdateStockLevel(ctx, &inventory.StockLevel{
+			SKU:               sku,
+			QuantityOnHand:    qty,
+			QuantityAvailable: qty,
+		}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	seq := 0
+	next := func() string {
+		seq++
+		return fmt.Sprintf("id-%d", seq)
+	}
+
+	f := &fixture{
+		repo:       newMemoryRepo(),
+		promotions: newPromotionRepo(promotions...),
+		inventory:  inventory.NewMemoryService(invRepo),
+	}
+	f.service = NewOrderService(
+		f.repo,
+		pricing.NewPricingService(f.promotions, nil, nil),
+		f.inventory,
+		nil,
+		next,
+		next,
+	)
+	return f
+}
+
+func (f *fixture) available(t *testing.T, sku string) int {
+	t.Helper()
+	n, err := f.inventory.GetAvailableStock(context.Background(), sku)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return n
+}
+
+func usd(cents int64) money.Money {
+	return money.Money{Amount: cents, Currency: "USD"}
+}
+
+func testCart(items ...cart.CartItem) *cart.Cart {
+	for i := range items {
+		items[i].ID = fmt.Sprintf("line-%d", i)
+		items[i].ProductID = items[i].SKU
+		items[i].Name = items[i].SKU
+	}
+	return &cart.Cart{ID: "cart-1", UserID: "user-1", Items: items}
+}
+
+func orderRequest(c *cart.Cart, codes ...string) CreateOrderRequest {
+	return CreateOrderRequest{
+		Cart:   c,
+		UserID: c.UserID,
+		ShippingAddress: Address{
+			FirstName:    "Ada",
+			LastName:     "Lovelace",
+			AddressLine1: "1 Main St",
+			City:         "Springfield",
+			PostalCode:   "12345",
+			Country:      "US",
+		},
+		PromotionCodes: codes,
+	}
+}
+
+func TestCancelOrderReleasesStock(t *testing.T) {
+	ctx := context.Background()
+	f := newFixture(t, map[string]int{"SKU-1": 10})
+
+	order, err := f.service.CreateFromCart(ctx, orderRequest(testCart(
+		cart.CartItem{SKU: "SKU-1", Price: usd(1000), Quantity: 3},
+	)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := f.available(t, "SKU-1"); got != 7 {
+		t.Fatalf("available after order = %d, want 7", got)
+	}
+
+	if _, err := f.service.CancelOrder(ctx, order.ID, CancellationReasonCustomerRequest, ""); err != nil {
+		t.Fatal(err)
+	}
+	if got := f.available(t, "SKU-1"); got != 10 {
+		t.Errorf("available after cancel = %d, want 10", got)
+	}
+}
+
+func TestCancelStalePendingReleasesStock(t *testing.T) {
+	ctx := context.Background()
+	f := newFixture(t, map[string]int{"SKU-1": 10})
+
+	stale, err := f.service.CreateFromCart(ctx, orderRequest(testCart(
+		cart.CartItem{SKU: "SKU-1", Price: usd(1000), Quantity: 3},
+	)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	stale.CreatedAt = time.Now().Add(-2 * time.Hour)
+
+	if _, err := f.service.CreateFromCart(ctx, orderRequest(testCart(
+		cart.CartItem{SKU: "SKU-1", Price: usd(1000), Quantity: 2},
+	))); err != nil {
+		t.Fatal(err)
+	}
+	if got := f.available(t, "SKU-1"); got != 5 {
+		t.Fatalf("available after orders = %d, want 5", got)
+	}
+
+	canceled, err := f.service.CancelStalePending(ctx, time.Now().Add(-time.Hour))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if canceled != 1 {
+		t.Errorf("canceled = %d, want 1", canceled)
+	}
+	if stale.Status != OrderStatusCanceled || stale.CancellationReason != CancellationReasonOther {
+		t.Errorf("stale order is %s (%s), want canceled for reason other", stale.Status, stale.CancellationReason)
+	}
+	if got := f.available(t, "SKU-1"); got != 8 {
+		t.Errorf("available after sweep = %d, want 8", got)
+	}
+}
+
+func percentOff(code string, value float64) *pricing.Promotion {
+	return &pricing.Promotion{
+		ID:           code,
+		Code:         code,
+		DiscountType: pricing.DiscountTypePercentage,
+		Value:        value,
+		IsActive:     true,
+		ValidFrom:    time.Now().Add(-time.Hour),
+		ValidTo:      time.Now().Add(time.Hour),
+	}
+}
+
+func TestCreateFromCartSaveFailureReleasesStockAndCodes(t *testing.T) {
+	ctx := context.Background()
+	f := newFixture(t, map[string]int{"SKU-1": 10}, percentOff("SAVE10", 0.10))
+	f.repo.saveErr = errors.New("database unavailable")
+
+	_, err := f.service.CreateFromCart(ctx, orderRequest(testCart(
+		cart.CartItem{SKU: "SKU-1", Price: usd(1000), Quantity: 3},
+	), "SAVE10"))
+	if !errors.Is(err, f.repo.saveErr) {
+		t.Fatalf("err = %v, want %v", err, f.repo.saveErr)
+	}
+	if got := f.available(t, "SKU-1"); got != 10 {
+		t.Errorf("available = %d, want 10", got)
+	}
+	if got := f.promotions.promotions["SAVE10"].UsageCount; got != 0 {
+		t.Errorf("SAVE10 usage = %d, want 0", got)
+	}
+}
+
+// exhaustedOnRedeem is a promotion repository whose code runs out between
+// pricing and redemption, as when another checkout redeems it first.
+type exhaustedOnRedeem struct {
+	*promotionRepo
+	code string
+}
+
+func (r exhaustedOnRedeem) IncrementUsage(ctx context.Context, code, userID string) error {
+	if code == r.code {
+		return pricing.ErrPromotionUsageExceeded
+	}
+	return r.promotionRepo.IncrementUsage(ctx, code, userID)
+}
+
+func TestCreateFromCartRedemptionFailureUndoesOrder(t *testing.T) {
+	ctx := context.Background()
+	f := newFixture(t, map[string]int{"SKU-1": 10}, percentOff("AAA10", 0.10), percentOff("ZZZ5", 0.05))
+	f.service.pricingService = pricing.NewPricingService(exhaustedOnRedeem{f.promotions, "ZZZ5"}, nil, nil)
+
+	_, err := f.service.CreateFromCart(ctx, orderRequest(testCart(
+		cart.CartItem{SKU: "SKU-1", Price: usd(1000), Quantity: 3},
+	), "AAA10", "ZZZ5"))
+	if !errors.Is(err, pricing.ErrPromotionUsageExceeded) {
+		t.Fatalf("err = %v, want %v", err, pricing.ErrPromotionUsageExceeded)
+	}
+	if len(f.repo.orders) != 0 {
+		t.Errorf("%d order(s) saved, want none", len(f.repo.orders))
+	}
+	if got := f.available(t, "SKU-1"); got != 10 {
+		t.Errorf("available = %d, want 10", got)
+	}
+	if got := f.promotions.promotions["AAA10"].UsageCount; got != 0 {
+		t.Errorf("AAA10 usage = %d, want 0", got)
+	}
+}
+
+// taxRates is a tax.Repository holding a fixed set of rates.
+type taxRates struct {
+	tax.Repository
+	rates []*tax.TaxRate
+}
+
+func (r taxRates) FindRatesByAddress(ctx context.Context, address tax.Address) ([]*tax.TaxRate, error) {
+	return r.rates, nil
+}
+
+func (r taxRates) FindRatesByPostalCode(ctx context.Context, country, postalCode string) ([]*tax.TaxRate, error) {
+	return nil, nil
+}
+
+// flatShipping is a shipping.RateCalculator charging cost for any named method.
+type flatShipping money.Money
+
+func (f flatShipping) GetRate(ctx context.Context, req shipping.RateRequest) (*shipping.ShippingRate, error) {
+	if req.ShippingMethodID == "" {
+		return nil, errors.New("no shipping method")
+	}
+	return &shipping.ShippingRate{MethodID: req.ShippingMethodID, Cost: money.Money(f)}, nil
+}
+
+func (f flatShipping) GetAvailableRates(ctx context.Context, req shipping.RateRequest) ([]*shipping.ShippingRate, error) {
+	rate, _ := f.GetRate(ctx, req)
+	return []*shipping.ShippingRate{rate}, nil
+}
+
+func TestCreateFromCartPartsSumToTotal(t *testing.T) {
+	ctx := context.Background()
+	f := newFixture(t, map[string]int{"SKU-1": 10, "SKU-2": 10, "SKU-3": 10}, percentOff("SAVE15", 0.15))
+	f.service.pricingService = pricing.NewPricingService(
+		f.promotions,
+		tax.NewStandardCalculator(taxRates{rates: []*tax.TaxRate{
+			{ID: "tx", Name: "Sales tax", Rate: 0.0825, Country: "US", TaxType: tax.TaxTypeSales},
+		}}),
+		flatShipping(usd(599)),
+	)
+
+	req := orderRequest(testCart(
+		cart.CartItem{SKU: "SKU-1", Price: usd(333), Quantity: 3},
+		cart.CartItem{SKU: "SKU-2", Price: usd(1999), Quantity: 1},
+		cart.CartItem{SKU: "SKU-3", Price: usd(149), Quantity: 7},
+	), "SAVE15")
+	req.ShippingMethodID = "ground"
+	order, err := f.service.CreateFromCart(ctx, req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := order.Validate(); err != nil {
+		t.Fatal(err)
+	}
+	if order.DiscountTotal.IsZero() || order.TaxTotal.IsZero() || order.ShippingTotal.Amount != 599 {
+		t.Fatalf("order has discount %s, tax %s, shipping %s; want all three",
+			order.DiscountTotal, order.TaxTotal, order.ShippingTotal)
+	}
+
+	var items, itemTax int64
+	for _, item := range order.Items {
+		items += item.Total.Amount
+		itemTax += item.TaxAmount.Amount
+	}
+	shippingTax := order.TaxTotal.Amount - itemTax
+	if got := items + order.ShippingTotal.Amount + shippingTax; got != order.Total.Amount {
+		t.Errorf("items %d + shipping %d + shipping tax %d = %d, want total %d",
+			items, order.ShippingTotal.Amount, shippingTax, got, order.Total.Amount)
+	}
+}
+
+func TestBulkUpdateStatusReportsEachOrder(t *testing.T) {
+	ctx := context.Background()
+	f := newFixture(t, nil)
+	f.repo.orders["processing"] = &Order{ID: "processing", Status: OrderStatusProcessing}
+	f.repo.orders["pending"] = &Order{ID: "pending", Status: OrderStatusPending}
+
+	results := f.service.BulkUpdateStatus(ctx, []string{"processing", "pending", "missing"}, OrderStatusShipped)
+
+	if len(results) != 3 {
+		t.Fatalf("got %d results, want 3", len(results))
+	}
+	if err := results["processing"]; err != nil {
+		t.Errorf("processing: %v", err)
+	}
+	if !errors.Is(results["pending"], ErrInvalidStatus) {
+		t.Errorf("pending: error = %v, want %v", results["pending"], ErrInvalidStatus)
+	}
+	if !errors.Is(results["missing"], ErrOrderNotFound) {
+		t.Errorf("missing: error = %v, want %v", results["missing"], ErrOrderNotFound)
+	}
+	if got := f.repo.orders["processing"].Status; got != OrderStatusShipped {
+		t.Errorf("processing order is %s, want shipped", got)
+	}
+	if got := f.repo.orders["pending"].Status; got != OrderStatusPending {
+		t.Errorf("pending order is %s, want pending", got)
+	}
+}
+
+func TestCreateFromCartEstimatesDefaultShipping(t *testing.T) {
+	ctx := context.Background()
+	f := newFixture(t, map[string]int{"SKU-1": 10})
+	f.service.pricingService = pricing.NewPricingService(
+		f.promotions, nil, flatShipping(usd(599)), pricing.WithDefaultShippingMethod("ground"),
+	)
+
+	order, err := f.service.CreateFromCart(ctx, orderRequest(testCart(
+		cart.CartItem{SKU: "SKU-1", Price: usd(1000), Quantity: 1},
+	)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if order.ShippingTotal.Amount != 599 {
+		t.Errorf("ShippingTotal = %s, want default method's 5.99", order.ShippingTotal)
+	}
+}
+
+func TestCancelOrderRecordsReason(t *testing.T) {
+	ctx := context.Background()
+	f := newFixture(t, nil)
+	f.repo.orders["o1"] = &Order{ID: "o1", Status: OrderStatusPaid}
+	f.repo.orders["o2"] = &Order{ID: "o2", Status: OrderStatusPending}
+	f.repo.orders["o3"] = &Order{ID: "o3", Status: OrderStatusPending}
+
+	if _, err := f.service.CancelOrder(ctx, "o3", CancellationReason("changed_mind"), ""); !errors.Is(err, ErrInvalidCancellationReason) {
+		t.Errorf("unknown reason: error = %v, want %v", err, ErrInvalidCancellationReason)
+	}
+	if f.repo.orders["o3"].Status != OrderStatusPending {
+		t.Error("order canceled despite an invalid reason")
+	}
+
+	order, err := f.service.CancelOrder(ctx, "o1", CancellationReasonFraud, "chargeback on card")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if order.CancellationReason != CancellationReasonFraud || order.CancellationNote != "chargeback on card" {
+		t.Errorf("recorded %q / %q", order.CancellationReason, order.CancellationNote)
+	}
+
+	order, err = f.service.CancelOrder(ctx, "o2", "", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if order.CancellationReason != CancellationReasonOther {
+		t.Errorf("empty reason recorded as %q, want %q", order.CancellationReason, CancellationReasonOther)
+	}
+}
+
+func TestCreateFromCartCopiesMetadata(t *testing.T) {
+	ctx := context.Background()
+	f := newFixture(t, map[string]int{"SKU-1": 10})
+
+	req := orderRequest(testCart(cart.CartItem{SKU: "SKU-1", Price: usd(1000), Quantity: 1}))
+	req.Metadata = map[string]string{"erp_id": "SO-1001", "channel": "pos"}
+	order, err := f.service.CreateFromCart(ctx, req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	req.Metadata["erp_id"] = "changed"
+
+	if order.Metadata["erp_id"] != "SO-1001" || order.Metadata["channel"] != "pos" {
+		t.Errorf("Metadata = %v, want erp_id SO-1001 and channel pos", order.Metadata)
+	}
+}
+
+func TestCreateFromCartConvertsCurrency(t *testing.T) {
+	ctx := context.Background()
+	f := newFixture(t, map[string]int{"SKU-1": 10, "SKU-2": 10}, percentOff("SAVE10", 0.10))
+
+	req := orderRequest(testCart(
+		cart.CartItem{SKU: "SKU-1", Price: usd(1999), Quantity: 3},
+		cart.CartItem{SKU: "SKU-2", Price: usd(333), Quantity: 1},
+	), "SAVE10")
+	req.Conversion = &CurrencyConversion{Currency: "EUR", Rate: 0.9137}
+	order, err := f.service.CreateFromCart(ctx, req)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if order.OriginalTotal.Currency != "USD" || order.OriginalTotal.Amount != 5697 {
+		t.Errorf("OriginalTotal = %s, want USD 56.97", order.OriginalTotal)
+	}
+	if order.ExchangeRate != 0.9137 {
+		t.Errorf("ExchangeRate = %v, want 0.9137", order.ExchangeRate)
+	}
+	if order.Total.Currency != "EUR" || order.Items[0].UnitPrice != (money.Money{Amount: 1826, Currency: "EUR"}) {
+		t.Errorf("Total = %s, first unit price = %s; want both in EUR", order.Total, order.Items[0].UnitPrice)
+	}
+	if err := order.Validate(); err != nil {
+		t.Errorf("converted order: %v", err)
+	}
+
+	req.Conversion = &CurrencyConversion{Currency: "EUR"}
+	if _, err := f.service.CreateFromCart(ctx, req); !errors.Is(err, ErrInvalidConversion) {
+		t.Errorf("zero rate: error = %v, want %v", err, ErrInvalidConversion)
+	}
+}
+
+func TestGetOrderForUser(t *testing.T) {
+	ctx := context.Background()
+	f := newFixture(t, nil)
+	f.repo.orders["o1"] = &Order{ID: "o1", UserID: "user-1"}
+	f.repo.orders["guest"] = &Order{ID: "guest"}
+
+	tests := []struct {
+		name    string
+		orderID string
+		userID  string
+		isAdmin bool
+		wantErr error
+	}{
+		{"owner", "o1", "user-1", false, nil},
+		{"another user", "o1", "user-2", false, ErrOrderAccessDenied},
+		{"anonymous caller on a guest order", "guest", "", false, ErrOrderAccessDenied},
+		{"admin", "o1", "admin-1", true, nil},
+		{"missing order", "o2", "user-1", false, ErrOrderNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			order, err := f.service.GetOrderForUser(ctx, tt.orderID, tt.userID, tt.isAdmin)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("error = %v, want %v", err, tt.wantErr)
+			}
+			if tt.wantErr == nil && order.ID != tt.orderID {
+				t.Errorf("got order %s, want %s", order.ID, tt.orderID)
+			}
+			if tt.wantErr != nil && order != nil {
+				t.Errorf("returned order %s alongside error", order.ID)
+			}
+		})
+	}
+}
+
+func TestCreateFromCartRecordsAppliedPromotions(t *testing.T) {
+	ctx := context.Background()
+	take5 := percentOff("TAKE5", 0)
+	take5.DiscountType, take5.Value, take5.Name = pricing.DiscountTypeFixedAmount, 500, "Five off"
+	f := newFixture(t, map[string]int{"SKU-1": 10}, percentOff("SAVE10", 0.10), take5)
+
+	order, err := f.service.CreateFromCart(ctx, orderRequest(testCart(
+		cart.CartItem{SKU: "SKU-1", Price: usd(10000), Quantity: 1},
+	), "TAKE5", "SAVE10", "MISSING"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := []OrderPromotion{
+		{Code: "SAVE10", Amount: usd(1000)},
+		{Code: "TAKE5", Name: "Five off", Amount: usd(500)},
+	}
+	saved := f.repo.orders[order.ID]
+	for _, o := range []*Order{order, saved} {
+		if len(o.AppliedPromotions) != len(want) {
+			t.Fatalf("applied promotions = %+v, want %+v", o.AppliedPromotions, want)
+		}
+		for i := range want {
+			if o.AppliedPromotions[i] != want[i] {
+				t.Errorf("promotion %d = %+v, want %+v", i, o.AppliedPromotions[i], want[i])
+			}
+		}
+	}
+}
+
+func TestCreateFromCartShortSKUReleasesEarlierReservations(t *testing.T) {
+	ctx := context.Background()
+	f := newFixture(t, map[string]int{"SKU-1": 10, "SKU-2": 1, "SKU-3": 10})
+
+	_, err := f.service.CreateFromCart(ctx, orderRequest(testCart(
+		cart.CartItem{SKU: "SKU-1", Price: usd(1000), Quantity: 3},
+		cart.CartItem{SKU: "SKU-2", Price: usd(1000), Quantity: 2},
+		cart.CartItem{SKU: "SKU-3", Price: usd(1000), Quantity: 4},
+	)))
+	if !errors.Is(err, inventory.ErrInsufficientStock) {
+		t.Fatalf("err = %v, want %v", err, inventory.ErrInsufficientStock)
+	}
+	for sku, want := range map[string]int{"SKU-1": 10, "SKU-2": 1, "SKU-3": 10} {
+		if got := f.available(t, sku); got != want {
+			t.Errorf("%s available = %d, want %d", sku, got, want)
+		}
+	}
+	if len(f.repo.orders) != 0 {
+		t.Errorf("saved %d orders, want none", len(f.repo.orders))
+	}
+}
+
+func TestCreateFromCartReservesRepeatedSKUOnce(t *testing.T) {
+	ctx := context.Background()
+	f := newFixture(t, map[string]int{"SKU-1": 10})
+
+	order, err := f.service.CreateFromCart(ctx, orderRequest(testCart(
+		cart.CartItem{SKU: "SKU-1", Price: usd(1000), Quantity: 2, Attributes: map[string]string{"color": "red"}},
+		cart.CartItem{SKU: "SKU-1", Price: usd(1000), Quantity: 3, Attributes: map[string]string{"color": "blue"}},
+	)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := f.available(t, "SKU-1"); got != 5 {
+		t.Errorf("available = %d, want 5", got)
+	}
+
+	if _, err := f.service.CancelOrder(ctx, order.ID, CancellationReasonCustomerRequest, ""); err != nil {
+		t.Fatal(err)
+	}
+	if got := f.available(t, "SKU-1"); got != 10 {
+		t.Errorf("available after cancel = %d, want 10", got)
+	}
+}
+
+func TestCreateFromCartBackorders(t *testing.T) {
+	ctx := context.Background()
+	f := newFixture(t, map[string]int{"SKU-1": 1, "SKU-2": 10})
+
+	order, err := f.service.CreateFromCart(ctx, orderRequest(testCart(
+		cart.CartItem{SKU: "SKU-1", Price: usd(1000), Quantity: 3, AllowBackorder: true},
+		cart.CartItem{SKU: "SKU-2", Price: usd(1000), Quantity: 2},
+	)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !order.Items[0].Backordered || order.Items[1].Backordered {
+		t.Errorf("backordered = %t, %t; want true, false", order.Items[0].Backordered, order.Items[1].Backordered)
+	}
+	if got := f.available(t, "SKU-1"); got != -2 {
+		t.Errorf("SKU-1 available = %d, want -2", got)
+	}
+
+	_, err = f.service.CreateFromCart(ctx, orderRequest(testCart(
+		cart.CartItem{SKU: "SKU-2", Price: usd(1000), Quantity: 20},
+	)))
+	if !errors.Is(err, inventory.ErrInsufficientStock) {
+		t.Errorf("short without backorders: error = %v, want %v", err, inventory.ErrInsufficientStock)
+	}
+}
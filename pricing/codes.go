@@ -0,0 +1,86 @@
+package pricing
+
+import (
+	"context"
+	"math/rand"
+	"strings"
+	"time"
+)
+
+// codeAlphabet omits characters that are easily confused when read aloud or
+// typed from print (0/O, 1/I/L).
+const codeAlphabet = "ABCDEFGHJKMNPQRSTUVWXYZ23456789"
+
+// codeGroupLength and codeGroups shape generated codes as PREFIX-XXXX-XXXX.
+const (
+	codeGroupLength = 4
+	codeGroups      = 2
+)
+
+// GenerateCodes returns count distinct promotion codes of the form
+// PREFIX-XXXX-XXXX (e.g., "SUMMER-7KQ2-HX9D"). The prefix is upper-cased and
+// omitted with its dash when empty. A nil rng uses a time-seeded source; pass
+// a seeded one for reproducible codes.
+func GenerateCodes(prefix string, count int, rng *rand.Rand) []string {
+	if count <= 0 {
+		return []string{}
+	}
+	if rng == nil {
+		rng = rand.New(rand.NewSource(time.Now().UnixNano()))
+	}
+
+	prefix = strings.ToUpper(prefix)
+	if prefix != "" {
+		prefix += "-"
+	}
+
+	codes := make([]string, 0, count)
+	seen := make(map[string]bool, count)
+	var b strings.Builder
+	for len(codes) < count {
+		b.Reset()
+		b.WriteString(prefix)
+		for g := 0; g < codeGroups; g++ {
+			if g > 0 {
+				b.WriteByte('-')
+			}
+			for i := 0; i < codeGroupLength; i++ {
+				b.WriteByte(codeAlphabet[rng.Intn(len(codeAlphabet))])
+			}
+		}
+		code := b.String()
+		if seen[code] {
+			continue
+		}
+		seen[code] = true
+		codes = append(codes, code)
+	}
+	return codes
+}
+
+// CreateBulkPromotions saves count single-use promotions copied from template,
+// each with a generated code (see GenerateCodes) that also serves as its ID.
+// The template's ID, Code, and usage counters are ignored. Saving stops at the
+// first error; the promotions saved so far are returned with it.
+func (s *PricingService) CreateBulkPromotions(ctx context.Context, template Promotion, prefix string, count int) ([]*Promotion, error) {
+	codes := GenerateCodes(prefix, count, nil)
+
+	promotions := make([]*Promotion, 0, len(codes))
+	for _, code := range codes {
+		promotion := template
+		promotion.ID = code
+		promotion.Code = code
+		promotion.UsageLimit = 1
+		promotion.UsageCount = 0
+		promotion.ApplicableProductIDs = append([]string(nil), template.ApplicableProductIDs...)
+		promotion.ApplicableCategoryIDs = append([]string(nil), template.ApplicableCategoryIDs...)
+		promotion.ExcludedProductIDs = append([]string(nil), template.ExcludedProductIDs...)
+		promotion.Tiers = append([]DiscountTier(nil), template.Tiers...)
+
+		if err := s.promotionRepo.Save(ctx, &promotion); err != nil {
+			return promotions, err
+		}
+		promotions = append(promotions, &promotion)
+	}
+	return promotions, nil
+}
@@ -0,0 +1,70 @@
+package pricing
+
+import (
+	"context"
+	"math/rand"
+	"regexp"
+	"testing"
+)
+
+func TestGenerateCodes(t *testing.T) {
+	shape := regexp.MustCompile(`^SUMMER-[` + codeAlphabet + `]{4}-[` + codeAlphabet + `]{4}$`)
+	codes := GenerateCodes("summer", 500, rand.New(rand.NewSource(1)))
+	if len(codes) != 500 {
+		t.Fatalf("got %d codes, want 500", len(codes))
+	}
+	seen := make(map[string]bool)
+	for _, code := range codes {
+		if !shape.MatchString(code) {
+			t.Errorf("code %q has the wrong shape", code)
+		}
+		if seen[code] {
+			t.Errorf("duplicate code %q", code)
+		}
+		seen[code] = true
+	}
+
+	again := GenerateCodes("summer", 500, rand.New(rand.NewSource(1)))
+	for i := range codes {
+		if again[i] != codes[i] {
+			t.Fatalf("same seed gave %q at %d, want %q", again[i], i, codes[i])
+		}
+	}
+
+	if got := GenerateCodes("", 1, rand.New(rand.NewSource(1)))[0]; len(got) != 9 {
+		t.Errorf("unprefixed code = %q, want XXXX-XXXX", got)
+	}
+	if got := GenerateCodes("X", 0, nil); len(got) != 0 {
+		t.Errorf("zero count = %v, want none", got)
+	}
+}
+
+func TestCreateBulkPromotions(t *testing.T) {
+	ctx := context.Background()
+	repo := newPromotionRepo()
+	s := NewPricingService(repo, nil, nil)
+
+	template := *activePromotion("TEMPLATE", DiscountTypePercentage, 0.1)
+	template.UsageCount = 7
+	template.ApplicableProductIDs = []string{"A"}
+	promotions, err := s.CreateBulkPromotions(ctx, template, "vip", 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(promotions) != 3 || len(repo.promotions) != 3 {
+		t.Fatalf("created %d promotions, saved %d; want 3", len(promotions), len(repo.promotions))
+	}
+	for _, p := range promotions {
+		if p.ID != p.Code || repo.promotions[p.Code] == nil {
+			t.Errorf("promotion %q: ID %q, saved %t", p.Code, p.ID, repo.promotions[p.Code] != nil)
+		}
+		if p.UsageLimit != 1 || p.UsageCount != 0 || p.Value != 0.1 {
+			t.Errorf("promotion %q: limit %d, count %d, value %v", p.Code, p.UsageLimit, p.UsageCount, p.Value)
+		}
+	}
+
+	promotions[0].ApplicableProductIDs[0] = "B"
+	if promotions[1].ApplicableProductIDs[0] != "A" || template.ApplicableProductIDs[0] != "A" {
+		t.Error("generated promotions share the template's product IDs")
+	}
+}
@@ -1,6 +1,7 @@
 package pricing
 
 import (
+	"strings"
 	"time"
 
 	"github.com/devchuckcamp/gocommerce/money"
@@ -8,30 +9,59 @@ import (
 
 // LineItem represents an item to be priced.
 type LineItem struct {
-	ID         string
-	ProductID  string
-	VariantID  *string
-	SKU        string
-	Name       string
-	UnitPrice  money.Money
-	Quantity   int
-	Attributes map[string]string
+	ID          string
+	ProductID   string
+	VariantID   *string
+	SKU         string
+	Name        string
+	UnitPrice   money.Money
+	Quantity    int
+	Attributes  map[string]string
+	TaxCode     string
+	CategoryID  string
+	WeightGrams int
+	LengthCm    int
+	WidthCm     int
+	HeightCm    int
 }
 
 // PricingResult contains the complete pricing breakdown.
 type PricingResult struct {
-	Subtotal       money.Money
-	DiscountTotal  money.Money
-	TaxTotal       money.Money
-	ShippingTotal  money.Money
-	Total          money.Money
-	LineItemPrices []LineItemPrice
-	AppliedDiscounts []AppliedDiscount
-	TaxLines       []TaxLine
-	Currency       string
-	CalculatedAt   time.Time
+	Subtotal                  money.Money
+	DiscountTotal             money.Money
+	TaxTotal                  money.Money
+	ShippingTotal             money.Money
+	ShippingEstimated         bool   // True when ShippingTotal is an estimate, not the customer's choice
+	EstimatedShippingMethodID string // Method used for the estimate when ShippingEstimated
+	Total                     money.Money
+	LineItemPrices            []LineItemPrice
+	AppliedDiscounts          []AppliedDiscount
+	RejectedPromotions        []RejectedPromotion // Codes that couldn't be used, with reasons
+	TaxLines                  []TaxLine
+	Currency                  string
+	CalculatedAt              time.Time
+	Trace                     []TraceStep // Populated only when the request sets Verbose
 }
 
+// TraceStep records a single computation step taken while pricing.
+// It is intended for debugging and support tooling, not for display to customers.
+type TraceStep struct {
+	Step   TraceStepType
+	Detail string      // Human-readable explanation, e.g. "promotion SAVE10 (percentage) applied to 2 item(s)"
+	Amount money.Money // Amount produced by this step
+}
+
+// TraceStepType identifies the kind of pricing step recorded in a trace.
+type TraceStepType string
+
+const (
+	TraceStepSubtotal TraceStepType = "subtotal"
+	TraceStepDiscount TraceStepType = "discount"
+	TraceStepShipping TraceStepType = "shipping"
+	TraceStepTax      TraceStepType = "tax"
+	TraceStepTotal    TraceStepType = "total"
+)
+
 // LineItemPrice contains pricing details for a single line item.
 type LineItemPrice struct {
 	LineItemID     string
@@ -43,12 +73,21 @@ type LineItemPrice struct {
 
 // AppliedDiscount represents a discount that was applied.
 type AppliedDiscount struct {
-	PromotionID   string
-	Code          string
-	Name          string
-	DiscountType  DiscountType
-	Amount        money.Money
+	PromotionID    string
+	Code           string
+	Name           string
+	DiscountType   DiscountType
+	Amount         money.Money
 	AppliedToItems []string // Line item IDs
+	Automatic      bool     // Applied without a code (e.g., a member discount)
+}
+
+// RejectedPromotion is a promotion code that was not applied because it
+// couldn't be found or isn't usable now.
+type RejectedPromotion struct {
+	Code   string
+	Err    error  // e.g., ErrPromotionNotFound, ErrPromotionInvalid
+	Reason string // Err's message, for display
 }
 
 // TaxLine represents a tax calculation.
@@ -63,10 +102,11 @@ type TaxLine struct {
 type DiscountType string
 
 const (
-	DiscountTypePercentage  DiscountType = "percentage"
-	DiscountTypeFixedAmount DiscountType = "fixed_amount"
-	DiscountTypeBuyXGetY    DiscountType = "buy_x_get_y"
+	DiscountTypePercentage   DiscountType = "percentage"
+	DiscountTypeFixedAmount  DiscountType = "fixed_amount" // Order-level amount shared across eligible items
+	DiscountTypeBuyXGetY     DiscountType = "buy_x_get_y"
 	DiscountTypeFreeShipping DiscountType = "free_shipping"
+	DiscountTypeTiered       DiscountType = "tiered" // Discount set by the highest tier the subtotal reaches
 )
 
 // Promotion represents a discount promotion.
@@ -84,10 +124,45 @@ type Promotion struct {
 	IsActive     bool
 	UsageLimit   int
 	UsageCount   int
+	PerUserLimit int  // Uses allowed per signed-in customer; 0 means unlimited
+	Priority     int  // Lower values are applied first; ties are broken by Code
+	Exclusive    bool // Applies alone; see PricingService.PriceCart for resolution
 	// Additional rules
 	ApplicableProductIDs  []string
 	ApplicableCategoryIDs []string
 	ExcludedProductIDs    []string
+	// Tiers, when set on a percentage promotion, replace Value with the
+	// PercentOff of the highest tier the cart subtotal reaches. A tiered
+	// promotion always uses them, and its tiers may give AmountOff instead.
+	Tiers []DiscountTier
+	// Buy-X-get-Y: for every BuyQuantity units bought, GetQuantity more units
+	// are discounted by GetPercentOff (1.0 = free, the default when zero).
+	BuyQuantity   int
+	GetQuantity   int
+	GetPercentOff float64
+}
+
+// DiscountTier is a spend threshold and the discount it unlocks.
+type DiscountTier struct {
+	Threshold  money.Money  // Minimum cart subtotal, inclusive
+	PercentOff float64      // 0.10 = 10%
+	AmountOff  *money.Money // Fixed amount off the order instead of PercentOff (DiscountTypeTiered only)
+}
+
+// TierFor returns the tier with the highest threshold that subtotal reaches,
+// or nil if subtotal is below every tier. Tiers in other currencies are ignored.
+func (p *Promotion) TierFor(subtotal money.Money) *DiscountTier {
+	var best *DiscountTier
+	for i := range p.Tiers {
+		tier := &p.Tiers[i]
+		if !strings.EqualFold(tier.Threshold.Currency, subtotal.Currency) || tier.Threshold.Amount > subtotal.Amount {
+			continue
+		}
+		if best == nil || tier.Threshold.Amount > best.Threshold.Amount {
+			best = tier
+		}
+	}
+	return best
 }
 
 // IsValid checks if a promotion can be used.
@@ -106,6 +181,14 @@ func (p *Promotion) IsValid(at time.Time) bool {
 
 // CanApplyToProduct checks if promotion applies to a product.
 func (p *Promotion) CanApplyToProduct(productID string) bool {
+	return p.CanApplyToItem(productID, "")
+}
+
+// CanApplyToItem checks if promotion applies to a product in a category.
+// Excluded products never qualify. Otherwise, when applicable products or
+// categories are listed, the product qualifies if it is listed or its
+// category is; with neither listed, every product qualifies.
+func (p *Promotion) CanApplyToItem(productID, categoryID string) bool {
 	// Check exclusions
 	for _, excludedID := range p.ExcludedProductIDs {
 		if excludedID == productID {
@@ -113,16 +196,22 @@ func (p *Promotion) CanApplyToProduct(productID string) bool {
 		}
 	}
 	
-	// If specific products listed, check if product is in list
-	if len(p.ApplicableProductIDs) > 0 {
-		for _, applicableID := range p.ApplicableProductIDs {
-			if applicableID == productID {
+	// If no specific products or categories, applies to all
+	if len(p.ApplicableProductIDs) == 0 && len(p.ApplicableCategoryIDs) == 0 {
+		return true
+	}
+
+	for _, applicableID := range p.ApplicableProductIDs {
+		if applicableID == productID {
+			return true
+		}
+	}
+	if categoryID != "" {
+		for _, applicableID := range p.ApplicableCategoryIDs {
+			if applicableID == categoryID {
 				return true
 			}
 		}
-		return false
 	}
-	
-	// If no specific products, applies to all
-	return true
+	return false
 }
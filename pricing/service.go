@@ -2,6 +2,9 @@ package pricing
 
 import (
 	"context"
+	"fmt"
+	"sort"
+	"strings"
 	"time"
 
 	"github.com/devchuckcamp/gocommerce/cart"
@@ -14,7 +17,24 @@ import (
 type Service interface {
 	PriceCart(ctx context.Context, req PriceCartRequest) (*PricingResult, error)
 	PriceLineItems(ctx context.Context, req PriceLineItemsRequest) (*PricingResult, error)
+	// ValidatePromotion checks that code can be used on a cart totaling
+	// cartTotal. Each reason for rejection has its own error, so callers can
+	// tell the customer why: ErrPromotionNotFound (no such code),
+	// ErrPromotionNotStarted (before ValidFrom), ErrPromotionInvalid
+	// (inactive or expired), ErrPromotionUsageExceeded (usage limit reached),
+	// and ErrMinPurchaseNotMet.
 	ValidatePromotion(ctx context.Context, code string, cartTotal money.Money) (*Promotion, error)
+	ValidatePromotionForUser(ctx context.Context, code, userID string, cartTotal money.Money) (*Promotion, error)
+	ListPromotions(ctx context.Context) (*PromotionSchedule, error)
+	CreateBulkPromotions(ctx context.Context, template Promotion, prefix string, count int) ([]*Promotion, error)
+	RedeemPromotions(ctx context.Context, codes []string, userID string) error
+}
+
+// PromotionSchedule groups active-flagged promotions by lifecycle stage.
+type PromotionSchedule struct {
+	Upcoming []*Promotion // ValidFrom is in the future
+	Active   []*Promotion // Usable now
+	Expired  []*Promotion // Past ValidTo or usage limit reached
 }
 
 // PriceCartRequest contains data needed to price a cart.
@@ -23,7 +43,15 @@ type PriceCartRequest struct {
 	PromotionCodes   []string
 	ShippingMethodID *string
 	ShippingAddress  *Address // For tax calculation
-	TaxInclusive     bool
+	TaxInclusive     bool     // Prices include tax; TaxTotal is the embedded portion and is not added to Total
+	Verbose          bool     // Record each computation step in PricingResult.Trace
+	// EstimateShipping prices the cheapest available rate when no shipping
+	// method is selected and no default is configured (e.g., checkout preview).
+	EstimateShipping bool
+	// StrictPromotions makes PriceCart fail with a *PromotionRejectedError if
+	// any code is rejected. Otherwise rejected codes are skipped and listed in
+	// PricingResult.RejectedPromotions.
+	StrictPromotions bool
 }
 
 // PriceLineItemsRequest prices arbitrary line items.
@@ -33,6 +61,8 @@ type PriceLineItemsRequest struct {
 	ShippingCost     *money.Money
 	ShippingAddress  *Address
 	TaxInclusive     bool
+	Verbose          bool
+	StrictPromotions bool
 }
 
 // Address represents a shipping/billing address (minimal for pricing).
@@ -45,16 +75,70 @@ type Address struct {
 
 // PromotionRepository defines methods for promotion persistence.
 type PromotionRepository interface {
+	// FindByCode returns ErrPromotionNotFound if no promotion has code.
 	FindByCode(ctx context.Context, code string) (*Promotion, error)
 	FindActive(ctx context.Context) ([]*Promotion, error)
 	Save(ctx context.Context, promotion *Promotion) error
+	// IncrementUsage atomically adds one to the promotion's UsageCount, unless
+	// that would exceed its UsageLimit, in which case it returns
+	// ErrPromotionUsageExceeded and leaves the count unchanged. A non-empty
+	// userID is recorded against the use for CountUsageByUser; if userID
+	// already has PerUserLimit uses, it returns ErrPromotionAlreadyUsed
+	// instead. Both limits are checked in the same atomic step as the
+	// increment, so concurrent orders can't exceed either.
+	IncrementUsage(ctx context.Context, code, userID string) error
+	// DecrementUsage undoes one IncrementUsage for code and userID, e.g.,
+	// when a later code of the same redemption is rejected.
+	DecrementUsage(ctx context.Context, code, userID string) error
+	// CountUsageByUser returns how many times userID has used the promotion.
+	CountUsageByUser(ctx context.Context, code, userID string) (int, error)
+}
+
+// CustomerDiscountResolver supplies standing discounts that apply to a
+// customer without a code, such as a loyalty-tier percentage.
+type CustomerDiscountResolver interface {
+	// CustomerDiscount returns the promotion userID is entitled to, or nil if
+	// there is none. It is applied as returned; validity dates and usage
+	// limits are not checked.
+	CustomerDiscount(ctx context.Context, userID string) (*Promotion, error)
 }
 
 // PricingService implements the Service interface.
 type PricingService struct {
-	promotionRepo    PromotionRepository
-	taxCalculator    tax.Calculator
-	shippingCalc     shipping.RateCalculator
+	promotionRepo           PromotionRepository
+	taxCalculator           tax.Calculator
+	shippingCalc            shipping.RateCalculator
+	defaultShippingMethodID string
+	roundingMode            money.RoundingMode
+	customerDiscounts       CustomerDiscountResolver
+}
+
+// Option configures optional PricingService behavior.
+type Option func(*PricingService)
+
+// WithDefaultShippingMethod sets the shipping method used to estimate shipping
+// when a request doesn't specify one. Results priced this way have
+// PricingResult.ShippingEstimated set.
+func WithDefaultShippingMethod(methodID string) Option {
+	return func(s *PricingService) {
+		s.defaultShippingMethodID = methodID
+	}
+}
+
+// WithRoundingMode sets how percentage discounts and tax are rounded to minor
+// units. It defaults to money.DefaultRoundingMode.
+func WithRoundingMode(mode money.RoundingMode) Option {
+	return func(s *PricingService) {
+		s.roundingMode = mode
+	}
+}
+
+// WithCustomerDiscounts applies the discount resolver returns for the cart's
+// user to every priced cart, ahead of any promotion codes.
+func WithCustomerDiscounts(resolver CustomerDiscountResolver) Option {
+	return func(s *PricingService) {
+		s.customerDiscounts = resolver
+	}
 }
 
 // NewPricingService creates a new pricing service.
@@ -62,32 +146,55 @@ func NewPricingService(
 	promotionRepo PromotionRepository,
 	taxCalculator tax.Calculator,
 	shippingCalc shipping.RateCalculator,
+	opts ...Option,
 ) *PricingService {
-	return &PricingService{
+	s := &PricingService{
 		promotionRepo: promotionRepo,
 		taxCalculator: taxCalculator,
 		shippingCalc:  shippingCalc,
+		roundingMode:  money.DefaultRoundingMode,
+	}
+	for _, opt := range opts {
+		opt(s)
 	}
+	return s
 }
 
 // PriceCart calculates the complete pricing for a cart.
+//
+// The customer's standing discount (see WithCustomerDiscounts) comes first,
+// then promotion codes in Priority-then-Code order. If any exclusive one
+// discounts the cart, the first such one is the only discount applied;
+// otherwise every stackable one is applied in turn, each limited to what is
+// left of a line after the earlier ones.
 func (s *PricingService) PriceCart(ctx context.Context, req PriceCartRequest) (*PricingResult, error) {
 	if req.Cart == nil || req.Cart.IsEmpty() {
 		return nil, nil
 	}
+	if err := req.Cart.Validate(); err != nil {
+		return nil, err
+	}
 	
+	trace := &tracer{enabled: req.Verbose}
+
 	// Convert cart items to line items
 	lineItems := make([]LineItem, len(req.Cart.Items))
 	for i, item := range req.Cart.Items {
 		lineItems[i] = LineItem{
-			ID:         item.ID,
-			ProductID:  item.ProductID,
-			VariantID:  item.VariantID,
-			SKU:        item.SKU,
-			Name:       item.Name,
-			UnitPrice:  item.Price,
-			Quantity:   item.Quantity,
-			Attributes: item.Attributes,
+			ID:          item.ID,
+			ProductID:   item.ProductID,
+			VariantID:   item.VariantID,
+			SKU:         item.SKU,
+			Name:        item.Name,
+			UnitPrice:   item.Price,
+			Quantity:    item.Quantity,
+			Attributes:  item.Attributes,
+			TaxCode:     item.TaxCode,
+			CategoryID:  item.CategoryID,
+			WeightGrams: item.WeightGrams,
+			LengthCm:    item.LengthCm,
+			WidthCm:     item.WidthCm,
+			HeightCm:    item.HeightCm,
 		}
 	}
 	
@@ -107,12 +214,26 @@ func (s *PricingService) PriceCart(ctx context.Context, req PriceCartRequest) (*
 		}
 		subtotal, _ = subtotal.Add(itemSubtotal)
 	}
+	trace.add(TraceStepSubtotal, fmt.Sprintf("%d line item(s)", len(lineItems)), subtotal)
 	
-	// Apply promotions
-	appliedDiscounts, err := s.applyPromotions(ctx, lineItems, lineItemPrices, req.PromotionCodes)
+	// Apply the customer's standing discount, if any, and promotions
+	var automatic []*Promotion
+	if s.customerDiscounts != nil && req.Cart.UserID != "" {
+		customerDiscount, err := s.customerDiscounts.CustomerDiscount(ctx, req.Cart.UserID)
+		if err != nil {
+			return nil, err
+		}
+		if customerDiscount != nil {
+			automatic = append(automatic, customerDiscount)
+		}
+	}
+	appliedDiscounts, rejectedPromotions, err := s.applyPromotions(ctx, lineItems, lineItemPrices, automatic, req.Cart.UserID, req.PromotionCodes, trace)
 	if err != nil {
 		return nil, err
 	}
+	if req.StrictPromotions && len(rejectedPromotions) > 0 {
+		return nil, &PromotionRejectedError{Rejected: rejectedPromotions}
+	}
 	
 	// Calculate total discount
 	discountTotal := money.Zero(currency)
@@ -120,17 +241,51 @@ func (s *PricingService) PriceCart(ctx context.Context, req PriceCartRequest) (*
 		discountTotal, _ = discountTotal.Add(discount.Amount)
 	}
 	
-	// Calculate shipping
+	subtotalAfterDiscount, _ := subtotal.Subtract(discountTotal)
+
+	// Calculate shipping, falling back to the default method as an estimate
 	shippingTotal := money.Zero(currency)
-	if req.ShippingMethodID != nil && s.shippingCalc != nil {
+	shippingMethodID := req.ShippingMethodID
+	usingDefault := false
+	if shippingMethodID == nil && s.defaultShippingMethodID != "" {
+		shippingMethodID = &s.defaultShippingMethodID
+		usingDefault = true
+	}
+	shippingEstimated := false
+	estimatedMethodID := ""
+	if shippingMethodID != nil && s.shippingCalc != nil {
 		shippingRate, err := s.shippingCalc.GetRate(ctx, shipping.RateRequest{
-			Items:            convertToShippingItems(lineItems),
+			Items:              convertToShippingItems(lineItems),
 			DestinationAddress: convertToShippingAddress(req.ShippingAddress),
-			ShippingMethodID: *req.ShippingMethodID,
+			ShippingMethodID:   *shippingMethodID,
+			OrderValue:         subtotalAfterDiscount,
 		})
 		if err == nil && shippingRate != nil {
 			shippingTotal = shippingRate.Cost
+			if usingDefault {
+				shippingEstimated = true
+				estimatedMethodID = *shippingMethodID
+			}
+			trace.add(TraceStepShipping, fmt.Sprintf("method %s (%s)", *shippingMethodID, shippingRate.MethodName), shippingTotal)
+		} else {
+			trace.add(TraceStepShipping, fmt.Sprintf("method %s: no rate available", *shippingMethodID), shippingTotal)
 		}
+	} else if req.EstimateShipping && s.shippingCalc != nil {
+		cheapest := s.cheapestShippingRate(ctx, shipping.RateRequest{
+			Items:              convertToShippingItems(lineItems),
+			DestinationAddress: convertToShippingAddress(req.ShippingAddress),
+			OrderValue:         subtotalAfterDiscount,
+		}, currency)
+		if cheapest != nil {
+			shippingTotal = cheapest.Cost
+			shippingEstimated = true
+			estimatedMethodID = cheapest.MethodID
+			trace.add(TraceStepShipping, fmt.Sprintf("estimated with cheapest method %s (%s)", cheapest.MethodID, cheapest.MethodName), shippingTotal)
+		} else {
+			trace.add(TraceStepShipping, "no shipping rates available to estimate", shippingTotal)
+		}
+	} else {
+		trace.add(TraceStepShipping, "no shipping method selected", shippingTotal)
 	}
 	
 	// Calculate tax
@@ -139,16 +294,20 @@ func (s *PricingService) PriceCart(ctx context.Context, req PriceCartRequest) (*
 	
 	if req.ShippingAddress != nil && s.taxCalculator != nil {
 		taxReq := tax.CalculationRequest{
-			LineItems:       convertToTaxableItems(lineItems, lineItemPrices),
-			ShippingCost:    shippingTotal,
-			Address:         convertToTaxAddress(req.ShippingAddress),
-			TaxInclusive:    req.TaxInclusive,
+			LineItems:    convertToTaxableItems(lineItems, lineItemPrices),
+			ShippingCost: shippingTotal,
+			Address:      convertToTaxAddress(req.ShippingAddress),
+			TaxInclusive: req.TaxInclusive,
+			RoundingMode: s.roundingMode,
 		}
 		
 		taxResult, err := s.taxCalculator.Calculate(ctx, taxReq)
 		if err == nil {
 			taxLines = convertTaxLines(taxResult)
 			taxTotal = taxResult.TotalTax
+			for _, rate := range taxResult.TaxRates {
+				trace.add(TraceStepTax, fmt.Sprintf("%s %.4g%% (%s)", rate.Name, rate.Rate*100, rate.Jurisdiction), rate.Amount)
+			}
 			
 			// Update line item tax amounts
 			for i, taxLine := range taxResult.LineItemTaxes {
@@ -159,31 +318,47 @@ func (s *PricingService) PriceCart(ctx context.Context, req PriceCartRequest) (*
 		}
 	}
 	
-	// Calculate totals
-	subtotalAfterDiscount, _ := subtotal.Subtract(discountTotal)
-	total := subtotalAfterDiscount
-	total, _ = total.Add(taxTotal)
-	total, _ = total.Add(shippingTotal)
+	// Calculate totals; tax-inclusive prices already contain their tax
+	var total money.Money
+	if req.TaxInclusive {
+		total, err = subtotalAfterDiscount.Add(shippingTotal)
+		if err != nil {
+			return nil, err
+		}
+		trace.add(TraceStepTotal, "subtotal - discounts + shipping (tax included)", total)
+	} else {
+		total, err = subtotalAfterDiscount.AddMany(taxTotal, shippingTotal)
+		if err != nil {
+			return nil, err
+		}
+		trace.add(TraceStepTotal, "subtotal - discounts + tax + shipping", total)
+	}
 	
 	// Update line item totals
 	for i := range lineItemPrices {
 		itemTotal := lineItemPrices[i].Subtotal
 		itemTotal, _ = itemTotal.Subtract(lineItemPrices[i].DiscountAmount)
-		itemTotal, _ = itemTotal.Add(lineItemPrices[i].TaxAmount)
+		if !req.TaxInclusive {
+			itemTotal, _ = itemTotal.Add(lineItemPrices[i].TaxAmount)
+		}
 		lineItemPrices[i].Total = itemTotal
 	}
 	
 	return &PricingResult{
-		Subtotal:         subtotal,
-		DiscountTotal:    discountTotal,
-		TaxTotal:         taxTotal,
-		ShippingTotal:    shippingTotal,
-		Total:            total,
-		LineItemPrices:   lineItemPrices,
-		AppliedDiscounts: appliedDiscounts,
-		TaxLines:         taxLines,
-		Currency:         currency,
-		CalculatedAt:     time.Now(),
+		Subtotal:                  subtotal,
+		DiscountTotal:             discountTotal,
+		TaxTotal:                  taxTotal,
+		ShippingTotal:             shippingTotal,
+		ShippingEstimated:         shippingEstimated,
+		EstimatedShippingMethodID: estimatedMethodID,
+		Total:                     total,
+		LineItemPrices:            lineItemPrices,
+		AppliedDiscounts:          appliedDiscounts,
+		RejectedPromotions:        rejectedPromotions,
+		TaxLines:                  taxLines,
+		Currency:                  currency,
+		CalculatedAt:              time.Now(),
+		Trace:                     trace.steps,
 	}, nil
 }
 
@@ -198,6 +373,8 @@ func (s *PricingService) PriceLineItems(ctx context.Context, req PriceLineItemsR
 		PromotionCodes:   req.PromotionCodes,
 		ShippingAddress:  req.ShippingAddress,
 		TaxInclusive:     req.TaxInclusive,
+		Verbose:          req.Verbose,
+		StrictPromotions: req.StrictPromotions,
 	})
 }
 
@@ -208,8 +385,8 @@ func (s *PricingService) ValidatePromotion(ctx context.Context, code string, car
 		return nil, err
 	}
 	
-	if !promotion.IsValid(time.Now()) {
-		return nil, ErrPromotionInvalid
+	if err := promotionUsableAt(promotion, time.Now()); err != nil {
+		return nil, err
 	}
 	
 	if promotion.MinPurchase != nil {
@@ -222,28 +399,232 @@ func (s *PricingService) ValidatePromotion(ctx context.Context, code string, car
 	return promotion, nil
 }
 
-// applyPromotions applies promotions to line items.
+// promotionUsableAt returns why promotion can't be used at now, or nil.
+func promotionUsableAt(promotion *Promotion, now time.Time) error {
+	switch {
+	case !promotion.IsActive || now.After(promotion.ValidTo):
+		return ErrPromotionInvalid
+	case now.Before(promotion.ValidFrom):
+		return ErrPromotionNotStarted
+	case promotion.UsageLimit > 0 && promotion.UsageCount >= promotion.UsageLimit:
+		return ErrPromotionUsageExceeded
+	}
+	return nil
+}
+
+// ValidatePromotionForUser is like ValidatePromotion but also returns
+// ErrPromotionAlreadyUsed if userID has reached the promotion's PerUserLimit.
+// Guests (empty userID) are not limited per user.
+func (s *PricingService) ValidatePromotionForUser(ctx context.Context, code, userID string, cartTotal money.Money) (*Promotion, error) {
+	promotion, err := s.ValidatePromotion(ctx, code, cartTotal)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.checkPerUserLimit(ctx, promotion, userID); err != nil {
+		return nil, err
+	}
+	return promotion, nil
+}
+
+// checkPerUserLimit returns ErrPromotionAlreadyUsed if userID has used
+// promotion PerUserLimit times.
+func (s *PricingService) checkPerUserLimit(ctx context.Context, promotion *Promotion, userID string) error {
+	if userID == "" || promotion.PerUserLimit <= 0 {
+		return nil
+	}
+	used, err := s.promotionRepo.CountUsageByUser(ctx, promotion.Code, userID)
+	if err != nil {
+		return err
+	}
+	if used >= promotion.PerUserLimit {
+		return ErrPromotionAlreadyUsed
+	}
+	return nil
+}
+
+// RedeemPromotions records one use of each distinct code by userID (e.g., the
+// codes of an order's applied discounts; userID is empty for guests). It is
+// all or nothing: if any code fails, the uses already recorded are undone and
+// the error is returned, ErrPromotionAlreadyUsed if userID has reached a
+// code's PerUserLimit and ErrPromotionUsageExceeded if its overall usage
+// limit has been reached.
+func (s *PricingService) RedeemPromotions(ctx context.Context, codes []string, userID string) error {
+	seen := make(map[string]bool, len(codes))
+	var redeemed []string
+	for _, code := range codes {
+		if seen[code] {
+			continue
+		}
+		seen[code] = true
+
+		if err := s.promotionRepo.IncrementUsage(ctx, code, userID); err != nil {
+			for i := len(redeemed) - 1; i >= 0; i-- {
+				_ = s.promotionRepo.DecrementUsage(ctx, redeemed[i], userID)
+			}
+			return err
+		}
+		redeemed = append(redeemed, code)
+	}
+	return nil
+}
+
+// ListPromotions returns promotions bucketed into upcoming, active, and expired,
+// each ordered by ValidFrom then Code. Promotions switched off (IsActive false)
+// are not listed.
+func (s *PricingService) ListPromotions(ctx context.Context) (*PromotionSchedule, error) {
+	promotions, err := s.promotionRepo.FindActive(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	sort.Slice(promotions, func(i, j int) bool {
+		if promotions[i].ValidFrom.Equal(promotions[j].ValidFrom) {
+			return promotions[i].Code < promotions[j].Code
+		}
+		return promotions[i].ValidFrom.Before(promotions[j].ValidFrom)
+	})
+
+	now := time.Now()
+	schedule := &PromotionSchedule{
+		Upcoming: []*Promotion{},
+		Active:   []*Promotion{},
+		Expired:  []*Promotion{},
+	}
+	for _, promotion := range promotions {
+		switch {
+		case now.Before(promotion.ValidFrom):
+			schedule.Upcoming = append(schedule.Upcoming, promotion)
+		case promotion.IsValid(now):
+			schedule.Active = append(schedule.Active, promotion)
+		default:
+			schedule.Expired = append(schedule.Expired, promotion)
+		}
+	}
+
+	return schedule, nil
+}
+
+// applyPromotions applies the automatic promotions and then those named by
+// codes to line items. Codes that can't be found, can't be used now or that
+// userID has already used PerUserLimit times are returned as rejected rather
+// than failing the whole calculation.
 func (s *PricingService) applyPromotions(
 	ctx context.Context,
 	lineItems []LineItem,
 	lineItemPrices []LineItemPrice,
+	automatic []*Promotion,
+	userID string,
 	codes []string,
-) ([]AppliedDiscount, error) {
+	trace *tracer,
+) ([]AppliedDiscount, []RejectedPromotion, error) {
 	appliedDiscounts := []AppliedDiscount{}
+	var rejected []RejectedPromotion
+	currency := lineItems[0].UnitPrice.Currency
 	
+	now := time.Now()
+	promotions := make([]*Promotion, 0, len(codes))
+	seen := make(map[string]bool, len(codes))
 	for _, code := range codes {
+		if seen[code] {
+			continue
+		}
+		seen[code] = true
+
 		promotion, err := s.promotionRepo.FindByCode(ctx, code)
-		if err != nil || !promotion.IsValid(time.Now()) {
+		if err == nil {
+			err = promotionUsableAt(promotion, now)
+		}
+		if err == nil {
+			err = s.checkPerUserLimit(ctx, promotion, userID)
+		}
+		if err != nil {
+			rejected = append(rejected, RejectedPromotion{Code: code, Err: err, Reason: err.Error()})
+			trace.add(TraceStepDiscount, fmt.Sprintf("promotion %s skipped: %v", code, err), money.Zero(currency))
 			continue
 		}
-		
+		promotions = append(promotions, promotion)
+	}
+
+	// Discounts interact through the per-line caps, so evaluate promotions in a
+	// stable order regardless of how the codes were supplied.
+	sortPromotions(promotions)
+	promotions = append(append([]*Promotion(nil), automatic...), promotions...)
+	isAutomatic := make(map[*Promotion]bool, len(automatic))
+	for _, promotion := range automatic {
+		isAutomatic[promotion] = true
+	}
+
+	// Exclusive promotions don't combine with anything. The first one in
+	// Priority-then-Code order that discounts the cart is applied alone; only
+	// if none of them applies are the stackable promotions applied together.
+	for _, promotion := range promotions {
+		if !promotion.Exclusive {
+			continue
+		}
+		discount := s.calculateDiscount(promotion, lineItems, lineItemPrices)
+		if discount == nil {
+			trace.add(TraceStepDiscount, fmt.Sprintf("promotion %s skipped: no eligible items", promotion.Code), money.Zero(currency))
+			continue
+		}
+		discount.Automatic = isAutomatic[promotion]
+		trace.add(TraceStepDiscount, fmt.Sprintf("exclusive promotion %s (%s) applied to %d item(s)",
+			promotion.Code, promotion.DiscountType, len(discount.AppliedToItems)), discount.Amount)
+		for _, other := range promotions {
+			if other != promotion {
+				trace.add(TraceStepDiscount, fmt.Sprintf("promotion %s skipped: %s is exclusive", other.Code, promotion.Code), money.Zero(currency))
+			}
+		}
+		return append(appliedDiscounts, *discount), rejected, nil
+	}
+
+	for _, promotion := range promotions {
+		if promotion.Exclusive {
+			continue
+		}
+		code := promotion.Code
 		discount := s.calculateDiscount(promotion, lineItems, lineItemPrices)
 		if discount != nil {
+			discount.Automatic = isAutomatic[promotion]
 			appliedDiscounts = append(appliedDiscounts, *discount)
+			trace.add(TraceStepDiscount, fmt.Sprintf("promotion %s (%s) applied to %d item(s)",
+				code, promotion.DiscountType, len(discount.AppliedToItems)), discount.Amount)
+		} else {
+			trace.add(TraceStepDiscount, fmt.Sprintf("promotion %s skipped: no eligible items", code), money.Zero(currency))
 		}
 	}
 	
-	return appliedDiscounts, nil
+	return appliedDiscounts, rejected, nil
+}
+
+// cheapestShippingRate returns the lowest-cost rate in currency, breaking ties
+// by method ID, or nil if no rate is available.
+func (s *PricingService) cheapestShippingRate(ctx context.Context, req shipping.RateRequest, currency string) *shipping.ShippingRate {
+	rates, err := s.shippingCalc.GetAvailableRates(ctx, req)
+	if err != nil {
+		return nil
+	}
+
+	var cheapest *shipping.ShippingRate
+	for _, rate := range rates {
+		if rate == nil || !strings.EqualFold(rate.Cost.Currency, currency) {
+			continue
+		}
+		if cheapest == nil || rate.Cost.Amount < cheapest.Cost.Amount ||
+			(rate.Cost.Amount == cheapest.Cost.Amount && rate.MethodID < cheapest.MethodID) {
+			cheapest = rate
+		}
+	}
+	return cheapest
+}
+
+// sortPromotions orders promotions by Priority, then Code.
+func sortPromotions(promotions []*Promotion) {
+	sort.SliceStable(promotions, func(i, j int) bool {
+		if promotions[i].Priority != promotions[j].Priority {
+			return promotions[i].Priority < promotions[j].Priority
+		}
+		return promotions[i].Code < promotions[j].Code
+	})
 }
 
 // calculateDiscount calculates discount for a promotion.
@@ -260,32 +641,107 @@ func (s *PricingService) calculateDiscount(
 	totalDiscount := money.Zero(currency)
 	appliedToItems := []string{}
 	
+	// Cap the cumulative discount across all promotions at the line subtotal,
+	// so overlapping promotions can never discount an item below zero.
+	eligible := []int{}
+	remaining := make([]money.Money, len(lineItems))
 	for i, item := range lineItems {
-		if !promotion.CanApplyToProduct(item.ProductID) {
+		if !promotion.CanApplyToItem(item.ProductID, item.CategoryID) {
 			continue
 		}
-		
-		var itemDiscount money.Money
-		
-		switch promotion.DiscountType {
-		case DiscountTypePercentage:
-			itemDiscount = lineItemPrices[i].Subtotal.Multiply(promotion.Value)
-		case DiscountTypeFixedAmount:
-			discountMoney, _ := money.New(int64(promotion.Value), currency)
-			itemDiscount = discountMoney
+		eligible = append(eligible, i)
+		remaining[i], _ = lineItemPrices[i].Subtotal.Subtract(lineItemPrices[i].DiscountAmount)
+	}
+
+	discountType := promotion.DiscountType
+	percentOff := promotion.Value
+	discountMoney, _ := money.New(int64(promotion.Value), currency)
+
+	// Tiered promotions, and percentage ones with tiers, use the single
+	// highest tier the cart subtotal reaches.
+	if discountType == DiscountTypeTiered || (discountType == DiscountTypePercentage && len(promotion.Tiers) > 0) {
+		cartSubtotal := money.Zero(currency)
+		for _, price := range lineItemPrices {
+			cartSubtotal, _ = cartSubtotal.Add(price.Subtotal)
 		}
-		
-		// Apply max discount if set
+		tier := promotion.TierFor(cartSubtotal)
+		if tier == nil {
+			return nil
+		}
+
+		discountType = DiscountTypePercentage
+		percentOff = tier.PercentOff
+		if promotion.DiscountType == DiscountTypeTiered && tier.AmountOff != nil {
+			discountType = DiscountTypeFixedAmount
+			discountMoney = *tier.AmountOff
+		}
+	}
+
+	itemDiscounts := make([]money.Money, len(lineItems))
+	switch discountType {
+	case DiscountTypePercentage:
+		for _, i := range eligible {
+			itemDiscount := lineItemPrices[i].Subtotal.MultiplyWithRounding(percentOff, s.roundingMode)
+			if exceeds, _ := itemDiscount.GreaterThan(remaining[i]); exceeds {
+				itemDiscount = remaining[i]
+			}
+			itemDiscounts[i] = itemDiscount
+		}
+	case DiscountTypeFixedAmount:
+		// The amount is spread across eligible items by subtotal; any part that
+		// would push an item below zero goes to the others or is dropped.
 		if promotion.MaxDiscount != nil {
-			isGreater, _ := itemDiscount.GreaterThan(*promotion.MaxDiscount)
-			if isGreater {
-				itemDiscount = *promotion.MaxDiscount
+			if isGreater, _ := discountMoney.GreaterThan(*promotion.MaxDiscount); isGreater {
+				discountMoney = *promotion.MaxDiscount
 			}
 		}
 		
+		ratios := make([]int64, len(eligible))
+		caps := make([]money.Money, len(eligible))
+		for j, i := range eligible {
+			ratios[j] = lineItemPrices[i].Subtotal.Amount
+			caps[j] = remaining[i]
+		}
+		allocated, _, err := discountMoney.AllocateCapped(ratios, caps)
+		if err != nil {
+			return nil
+		}
+		for j, i := range eligible {
+			itemDiscounts[i] = allocated[j]
+		}
+	case DiscountTypeBuyXGetY:
+		s.calculateBuyXGetY(promotion, lineItems, eligible, remaining, itemDiscounts)
+	}
+
+	// MaxDiscount caps the promotion as a whole. When the items' discounts add
+	// up to more, the cap is shared out in proportion to them, so no item gets
+	// more than it would have uncapped.
+	if promotion.MaxDiscount != nil {
+		ratios := make([]int64, len(eligible))
+		uncapped := money.Zero(currency)
+		for j, i := range eligible {
+			if itemDiscounts[i].IsPositive() {
+				ratios[j] = itemDiscounts[i].Amount
+				uncapped, _ = uncapped.Add(itemDiscounts[i])
+			}
+		}
+		if exceeds, _ := uncapped.GreaterThan(*promotion.MaxDiscount); exceeds {
+			capped := promotion.MaxDiscount.AllocateByRatios(ratios)
+			for j, i := range eligible {
+				itemDiscounts[i] = capped[j]
+			}
+		}
+	}
+
+	for _, i := range eligible {
+		itemDiscount := itemDiscounts[i]
+		if !itemDiscount.IsPositive() {
+			continue
+		}
+
 		lineItemPrices[i].DiscountAmount, _ = lineItemPrices[i].DiscountAmount.Add(itemDiscount)
 		totalDiscount, _ = totalDiscount.Add(itemDiscount)
-		appliedToItems = append(appliedToItems, item.ID)
+		appliedToItems = append(appliedToItems, lineItems[i].ID)
 	}
 	
 	if totalDiscount.IsZero() {
@@ -302,28 +758,108 @@ func (s *PricingService) calculateDiscount(
 	}
 }
 
+// calculateBuyXGetY fills itemDiscounts for a buy-X-get-Y promotion. Eligible
+// units from all lines are pooled, so buying two different eligible products
+// can earn a third; every full group of BuyQuantity+GetQuantity units earns
+// GetQuantity discounted units, which are always the cheapest ones.
+func (s *PricingService) calculateBuyXGetY(
+	promotion *Promotion,
+	lineItems []LineItem,
+	eligible []int,
+	remaining []money.Money,
+	itemDiscounts []money.Money,
+) {
+	if promotion.BuyQuantity <= 0 || promotion.GetQuantity <= 0 {
+		return
+	}
+	percentOff := promotion.GetPercentOff
+	if percentOff == 0 {
+		percentOff = 1
+	}
+
+	units := 0
+	for _, i := range eligible {
+		units += lineItems[i].Quantity
+	}
+	discounted := units / (promotion.BuyQuantity + promotion.GetQuantity) * promotion.GetQuantity
+	if discounted == 0 {
+		return
+	}
+
+	cheapest := append([]int(nil), eligible...)
+	sort.SliceStable(cheapest, func(a, b int) bool {
+		return lineItems[cheapest[a]].UnitPrice.Amount < lineItems[cheapest[b]].UnitPrice.Amount
+	})
+	for _, i := range cheapest {
+		if discounted == 0 {
+			break
+		}
+		quantity := lineItems[i].Quantity
+		if quantity > discounted {
+			quantity = discounted
+		}
+		discounted -= quantity
+
+		itemDiscount := lineItems[i].UnitPrice.MultiplyInt(quantity).MultiplyWithRounding(percentOff, s.roundingMode)
+		if exceeds, _ := itemDiscount.GreaterThan(remaining[i]); exceeds {
+			itemDiscount = remaining[i]
+		}
+		itemDiscounts[i] = itemDiscount
+	}
+}
+
+// tracer collects TraceSteps when verbose pricing is requested.
+// A disabled tracer discards everything, so call sites need no guards.
+type tracer struct {
+	enabled bool
+	steps   []TraceStep
+}
+
+func (t *tracer) add(step TraceStepType, detail string, amount money.Money) {
+	if !t.enabled {
+		return
+	}
+	t.steps = append(t.steps, TraceStep{Step: step, Detail: detail, Amount: amount})
+}
+
 // Helper conversion functions
 
 func convertLineItemsToCartItems(items []LineItem) []cart.CartItem {
 	cartItems := make([]cart.CartItem, len(items))
 	for i, item := range items {
 		cartItems[i] = cart.CartItem{
-			ID:         item.ID,
-			ProductID:  item.ProductID,
-			VariantID:  item.VariantID,
-			SKU:        item.SKU,
-			Name:       item.Name,
-			Price:      item.UnitPrice,
-			Quantity:   item.Quantity,
-			Attributes: item.Attributes,
+			ID:          item.ID,
+			ProductID:   item.ProductID,
+			VariantID:   item.VariantID,
+			SKU:         item.SKU,
+			Name:        item.Name,
+			Price:       item.UnitPrice,
+			Quantity:    item.Quantity,
+			Attributes:  item.Attributes,
+			TaxCode:     item.TaxCode,
+			CategoryID:  item.CategoryID,
+			WeightGrams: item.WeightGrams,
+			LengthCm:    item.LengthCm,
+			WidthCm:     item.WidthCm,
+			HeightCm:    item.HeightCm,
 		}
 	}
 	return cartItems
 }
 
 func convertToShippingItems(items []LineItem) []shipping.ShippableItem {
-	// Stub - would convert to shipping items
-	return []shipping.ShippableItem{}
+	shippingItems := make([]shipping.ShippableItem, len(items))
+	for i, item := range items {
+		shippingItems[i] = shipping.ShippableItem{
+			SKU:         item.SKU,
+			Quantity:    item.Quantity,
+			WeightGrams: item.WeightGrams,
+			LengthCm:    item.LengthCm,
+			WidthCm:     item.WidthCm,
+			HeightCm:    item.HeightCm,
+		}
+	}
+	return shippingItems
 }
 
 func convertToShippingAddress(addr *Address) shipping.Address {
@@ -342,9 +878,11 @@ func convertToTaxableItems(items []LineItem, prices []LineItemPrice) []tax.Taxab
 	taxItems := make([]tax.TaxableItem, len(items))
 	for i, item := range items {
 		taxItems[i] = tax.TaxableItem{
-			ID:       item.ID,
-			Amount:   prices[i].Subtotal,
-			Quantity: item.Quantity,
+			ID:        item.ID,
+			Amount:    prices[i].Subtotal,
+			Quantity:  item.Quantity,
+			TaxCode:   item.TaxCode,
+			IsTaxable: item.TaxCode != tax.TaxCodeExempt,
 		}
 	}
 	return taxItems
@@ -376,10 +914,36 @@ func convertTaxLines(result *tax.CalculationResult) []TaxLine {
 }
 
 var (
-	ErrPromotionInvalid   = DiscountError{Message: "promotion code is invalid"}
-	ErrMinPurchaseNotMet  = DiscountError{Message: "minimum purchase not met"}
+	ErrPromotionNotFound      = DiscountError{Message: "promotion code not found"}
+	ErrPromotionInvalid       = DiscountError{Message: "promotion code is invalid"}
+	ErrPromotionNotStarted    = DiscountError{Message: "promotion has not started yet"}
+	ErrMinPurchaseNotMet      = DiscountError{Message: "minimum purchase not met"}
+	ErrPromotionUsageExceeded = DiscountError{Message: "promotion usage limit reached"}
+	ErrPromotionAlreadyUsed   = DiscountError{Message: "promotion already used by this customer"}
 )
 
+// PromotionRejectedError is returned by PriceCart in strict mode when any
+// promotion code is rejected. errors.Is matches the individual reasons.
+type PromotionRejectedError struct {
+	Rejected []RejectedPromotion
+}
+
+func (e *PromotionRejectedError) Error() string {
+	reasons := make([]string, len(e.Rejected))
+	for i, r := range e.Rejected {
+		reasons[i] = fmt.Sprintf("%s: %s", r.Code, r.Reason)
+	}
+	return "promotion codes rejected: " + strings.Join(reasons, "; ")
+}
+
+func (e *PromotionRejectedError) Unwrap() []error {
+	errs := make([]error, len(e.Rejected))
+	for i, r := range e.Rejected {
+		errs[i] = r.Err
+	}
+	return errs
+}
+
 type DiscountError struct {
 	Message string
 }
@@ -0,0 +1,994 @@
+package pricing
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/devchuckcamp/gocommerce/cart"
+	"github.com/devchuckcamp/gocommerce/money"
+	"github.com/devchuckcamp/gocommerce/shipping"
+	"github.com/devchuckcamp/gocommerce/tax"
+)
+
+// promotionRepo is an in-memory PromotionRepository.
+type promotionRepo struct {
+	promotions map[string]*Promotion
+	uses       map[string]map[string]int
+}
+
+func newPromotionRepo(promotions ...*Promotion) *promotionRepo {
+	r := &promotionRepo{
+		promotions: make(map[string]*Promotion),
+		uses:       make(map[string]map[string]int),
+	}
+	for _, p := range promotions {
+		r.promotions[p.Code] = p
+	}
+	return r
+}
+
+func (r *promotionRepo) FindByCode(ctx context.Context, code string) (*Promotion, error) {
+	p, ok := r.promotions[code]
+	if !ok {
+		return nil, ErrPromotionNotFound
+	}
+	copied := *p
+	return &copied, nil
+}
+
+func (r *promotionRepo) FindActive(ctx context.Context) ([]*Promotion, error) {
+	var active []*Promotion
+	for _, p := range r.promotions {
+		if p.IsActive {
+			active = append(active, p)
+		}
+	}
+	return active, nil
+}
+
+func (r *promotionRepo) Save(ctx context.Context, p *Promotion) error {
+	r.promotions[p.Code] = p
+	return nil
+}
+
+func (r *promotionRepo) IncrementUsage(ctx context.Context, code, userID string) error {
+	p, ok := r.promotions[code]
+	if !ok {
+		return ErrPromotionNotFound
+	}
+	if p.UsageLimit > 0 && p.UsageCount >= p.UsageLimit {
+		return ErrPromotionUsageExceeded
+	}
+	if userID != "" && p.PerUserLimit > 0 && r.uses[code][userID] >= p.PerUserLimit {
+		return ErrPromotionAlreadyUsed
+	}
+	p.UsageCount++
+	if userID != "" {
+		if r.uses[code] == nil {
+			r.uses[code] = make(map[string]int)
+		}
+		r.uses[code][userID]++
+	}
+	return nil
+}
+
+func (r *promotionRepo) DecrementUsage(ctx context.Context, code, userID string) error {
+	p, ok := r.promotions[code]
+	if !ok {
+		return ErrPromotionNotFound
+	}
+	p.UsageCount--
+	if userID != "" {
+		r.uses[code][userID]--
+	}
+	return nil
+}
+
+func (r *promotionRepo) CountUsageByUser(ctx context.Context, code, userID string) (int, error) {
+	return r.uses[code][userID], nil
+}
+
+// flatRates is a shipping.RateCalculator charging a flat cost per method.
+type flatRates map[string]money.Money
+
+func (f flatRates) GetRate(ctx context.Context, req shipping.RateRequest) (*shipping.ShippingRate, error) {
+	cost, ok := f[req.ShippingMethodID]
+	if !ok {
+		return nil, shipping.ErrMethodUnavailable
+	}
+	return &shipping.ShippingRate{MethodID: req.ShippingMethodID, MethodName: req.ShippingMethodID, Cost: cost}, nil
+}
+
+func (f flatRates) GetAvailableRates(ctx context.Context, req shipping.RateRequest) ([]*shipping.ShippingRate, error) {
+	var rates []*shipping.ShippingRate
+	for id := range f {
+		rate, _ := f.GetRate(ctx, shipping.RateRequest{ShippingMethodID: id})
+		rates = append(rates, rate)
+	}
+	return rates, nil
+}
+
+func testCart(items ...cart.CartItem) *cart.Cart {
+	for i := range items {
+		items[i].ID = items[i].SKU
+		items[i].ProductID = items[i].SKU
+		items[i].Name = items[i].SKU
+	}
+	return &cart.Cart{ID: "cart-1", Items: items}
+}
+
+func usd(cents int64) money.Money {
+	return money.Money{Amount: cents, Currency: "USD"}
+}
+
+func activePromotion(code string, discountType DiscountType, value float64) *Promotion {
+	return &Promotion{
+		ID:           code,
+		Code:         code,
+		Name:         code,
+		DiscountType: discountType,
+		Value:        value,
+		IsActive:     true,
+		ValidFrom:    time.Now().Add(-time.Hour),
+		ValidTo:      time.Now().Add(time.Hour),
+	}
+}
+
+func TestRedeemPromotionsIsAllOrNothing(t *testing.T) {
+	ctx := context.Background()
+	limited := activePromotion("LIMITED", DiscountTypePercentage, 0.05)
+	limited.UsageLimit = 1
+	limited.UsageCount = 1
+	repo := newPromotionRepo(
+		activePromotion("FIRST", DiscountTypePercentage, 0.10),
+		activePromotion("SECOND", DiscountTypePercentage, 0.10),
+		limited,
+	)
+	s := NewPricingService(repo, nil, nil)
+
+	err := s.RedeemPromotions(ctx, []string{"FIRST", "SECOND", "LIMITED"}, "user-1")
+	if !errors.Is(err, ErrPromotionUsageExceeded) {
+		t.Fatalf("err = %v, want %v", err, ErrPromotionUsageExceeded)
+	}
+	for _, code := range []string{"FIRST", "SECOND"} {
+		if got := repo.promotions[code].UsageCount; got != 0 {
+			t.Errorf("%s usage = %d, want 0", code, got)
+		}
+		if got, _ := repo.CountUsageByUser(ctx, code, "user-1"); got != 0 {
+			t.Errorf("%s uses by user-1 = %d, want 0", code, got)
+		}
+	}
+
+	if err := s.RedeemPromotions(ctx, []string{"FIRST", "SECOND", "FIRST"}, "user-1"); err != nil {
+		t.Fatal(err)
+	}
+	for _, code := range []string{"FIRST", "SECOND"} {
+		if got := repo.promotions[code].UsageCount; got != 1 {
+			t.Errorf("%s usage = %d, want 1", code, got)
+		}
+	}
+}
+
+func TestRedeemPromotionsPerUserLimit(t *testing.T) {
+	ctx := context.Background()
+	once := activePromotion("WELCOME", DiscountTypePercentage, 0.10)
+	once.PerUserLimit = 1
+	repo := newPromotionRepo(once, activePromotion("OTHER", DiscountTypePercentage, 0.05))
+	s := NewPricingService(repo, nil, nil)
+
+	if err := s.RedeemPromotions(ctx, []string{"WELCOME"}, "user-1"); err != nil {
+		t.Fatal(err)
+	}
+	err := s.RedeemPromotions(ctx, []string{"OTHER", "WELCOME"}, "user-1")
+	if !errors.Is(err, ErrPromotionAlreadyUsed) {
+		t.Fatalf("err = %v, want %v", err, ErrPromotionAlreadyUsed)
+	}
+	if got := repo.promotions["OTHER"].UsageCount; got != 0 {
+		t.Errorf("OTHER usage = %d, want 0", got)
+	}
+	if err := s.RedeemPromotions(ctx, []string{"WELCOME"}, "user-2"); err != nil {
+		t.Errorf("another user: %v", err)
+	}
+	if err := s.RedeemPromotions(ctx, []string{"WELCOME"}, ""); err != nil {
+		t.Errorf("guest: %v", err)
+	}
+}
+
+func TestPriceCartSkipsCodeUsedByUser(t *testing.T) {
+	ctx := context.Background()
+	once := activePromotion("WELCOME", DiscountTypePercentage, 0.10)
+	once.PerUserLimit = 1
+	repo := newPromotionRepo(once)
+	s := NewPricingService(repo, nil, nil)
+	if err := s.RedeemPromotions(ctx, []string{"WELCOME"}, "user-1"); err != nil {
+		t.Fatal(err)
+	}
+
+	c := testCart(cart.CartItem{SKU: "A", Price: usd(2000), Quantity: 1})
+	c.UserID = "user-1"
+	result, err := s.PriceCart(ctx, PriceCartRequest{Cart: c, PromotionCodes: []string{"WELCOME"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !result.DiscountTotal.IsZero() {
+		t.Errorf("DiscountTotal = %s, want zero for a code the user already used", result.DiscountTotal)
+	}
+	if len(result.RejectedPromotions) != 1 || !errors.Is(result.RejectedPromotions[0].Err, ErrPromotionAlreadyUsed) {
+		t.Errorf("RejectedPromotions = %+v, want WELCOME with %v", result.RejectedPromotions, ErrPromotionAlreadyUsed)
+	}
+
+	c.UserID = "user-2"
+	result, err = s.PriceCart(ctx, PriceCartRequest{Cart: c, PromotionCodes: []string{"WELCOME"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.DiscountTotal.Amount != 200 {
+		t.Errorf("another user: DiscountTotal = %s, want 2.00", result.DiscountTotal)
+	}
+}
+
+func TestPriceCartShippingEstimated(t *testing.T) {
+	ctx := context.Background()
+	c := testCart(cart.CartItem{SKU: "A", Price: usd(2000), Quantity: 1})
+
+	tests := []struct {
+		name          string
+		calc          shipping.RateCalculator
+		wantEstimated bool
+		wantShipping  int64
+	}{
+		{"no calculator", nil, false, 0},
+		{"no rate for default method", flatRates{"express": usd(1500)}, false, 0},
+		{"default method rated", flatRates{"ground": usd(599)}, true, 599},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := NewPricingService(newPromotionRepo(), nil, tt.calc, WithDefaultShippingMethod("ground"))
+			result, err := s.PriceCart(ctx, PriceCartRequest{Cart: c})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if result.ShippingEstimated != tt.wantEstimated {
+				t.Errorf("ShippingEstimated = %t, want %t", result.ShippingEstimated, tt.wantEstimated)
+			}
+			if result.ShippingTotal.Amount != tt.wantShipping {
+				t.Errorf("ShippingTotal = %s, want %d", result.ShippingTotal, tt.wantShipping)
+			}
+			if tt.wantEstimated && result.EstimatedShippingMethodID != "ground" {
+				t.Errorf("EstimatedShippingMethodID = %q, want ground", result.EstimatedShippingMethodID)
+			}
+		})
+	}
+}
+
+func TestPriceCartFixedAmountCappedPerLine(t *testing.T) {
+	ctx := context.Background()
+	repo := newPromotionRepo(activePromotion("TAKE45", DiscountTypeFixedAmount, 4500))
+	s := NewPricingService(repo, nil, nil)
+
+	// $45 off $40 of goods: neither line can go below zero, and the $5 that
+	// fits nowhere is dropped.
+	result, err := s.PriceCart(ctx, PriceCartRequest{
+		Cart: testCart(
+			cart.CartItem{SKU: "A", Price: usd(1000), Quantity: 1},
+			cart.CartItem{SKU: "B", Price: usd(3000), Quantity: 1},
+		),
+		PromotionCodes: []string{"TAKE45"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.DiscountTotal.Amount != 4000 {
+		t.Errorf("DiscountTotal = %s, want USD 40.00", result.DiscountTotal)
+	}
+	for i, want := range []int64{1000, 3000} {
+		if got := result.LineItemPrices[i].DiscountAmount.Amount; got != want {
+			t.Errorf("line %d discount = %d, want %d", i, got, want)
+		}
+	}
+}
+
+func TestPriceCartVerboseTrace(t *testing.T) {
+	ctx := context.Background()
+	repo := newPromotionRepo(activePromotion("SAVE10", DiscountTypePercentage, 0.10))
+	s := NewPricingService(repo, nil, nil)
+	c := testCart(cart.CartItem{SKU: "A", Price: usd(2000), Quantity: 1})
+
+	quiet, err := s.PriceCart(ctx, PriceCartRequest{Cart: c, PromotionCodes: []string{"SAVE10"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(quiet.Trace) != 0 {
+		t.Errorf("trace recorded without Verbose: %v", quiet.Trace)
+	}
+
+	result, err := s.PriceCart(ctx, PriceCartRequest{
+		Cart:           c,
+		PromotionCodes: []string{"SAVE10", "MISSING"},
+		Verbose:        true,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []struct {
+		step   TraceStepType
+		amount int64
+	}{
+		{TraceStepSubtotal, 2000},
+		{TraceStepDiscount, 0},
+		{TraceStepDiscount, 200},
+		{TraceStepShipping, 0},
+		{TraceStepTotal, 1800},
+	}
+	if len(result.Trace) != len(want) {
+		t.Fatalf("got %d trace steps, want %d: %v", len(result.Trace), len(want), result.Trace)
+	}
+	for i, w := range want {
+		got := result.Trace[i]
+		if got.Step != w.step || got.Amount.Amount != w.amount {
+			t.Errorf("step %d = %s %d (%s), want %s %d", i, got.Step, got.Amount.Amount, got.Detail, w.step, w.amount)
+		}
+	}
+}
+
+func TestPriceCartStackedPromotionsCappedAtLineSubtotal(t *testing.T) {
+	ctx := context.Background()
+	repo := newPromotionRepo(
+		activePromotion("HALF", DiscountTypePercentage, 0.50),
+		activePromotion("SIXTY", DiscountTypePercentage, 0.60),
+	)
+	s := NewPricingService(repo, nil, nil)
+
+	result, err := s.PriceCart(ctx, PriceCartRequest{
+		Cart:           testCart(cart.CartItem{SKU: "A", Price: usd(1000), Quantity: 2}),
+		PromotionCodes: []string{"HALF", "SIXTY"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	line := result.LineItemPrices[0]
+	if line.DiscountAmount.Amount != 2000 {
+		t.Errorf("line discount = %s, want USD 20.00", line.DiscountAmount)
+	}
+	if line.Total.IsNegative() || result.Total.IsNegative() {
+		t.Errorf("line total %s, cart total %s; want neither negative", line.Total, result.Total)
+	}
+	if result.DiscountTotal.Amount != 2000 {
+		t.Errorf("DiscountTotal = %s, want USD 20.00", result.DiscountTotal)
+	}
+}
+
+// recordingTax is a tax.Calculator that records the last request and charges
+// nothing.
+type recordingTax struct {
+	req tax.CalculationRequest
+}
+
+func (r *recordingTax) Calculate(ctx context.Context, req tax.CalculationRequest) (*tax.CalculationResult, error) {
+	r.req = req
+	return &tax.CalculationResult{TotalTax: money.Zero(req.LineItems[0].Amount.Currency)}, nil
+}
+
+func (r *recordingTax) GetRatesForAddress(ctx context.Context, address tax.Address) ([]tax.TaxRate, error) {
+	return nil, nil
+}
+
+func TestPriceCartPassesTaxCodes(t *testing.T) {
+	ctx := context.Background()
+	taxCalc := &recordingTax{}
+	s := NewPricingService(newPromotionRepo(), taxCalc, nil)
+
+	_, err := s.PriceCart(ctx, PriceCartRequest{
+		Cart: testCart(
+			cart.CartItem{SKU: "SHIRT", Price: usd(2500), Quantity: 2, TaxCode: "clothing"},
+			cart.CartItem{SKU: "MUG", Price: usd(1200), Quantity: 1},
+		),
+		ShippingAddress: &Address{Country: "US", State: "NY"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	items := taxCalc.req.LineItems
+	if len(items) != 2 {
+		t.Fatalf("got %d taxable items, want 2", len(items))
+	}
+	if items[0].TaxCode != "clothing" || items[1].TaxCode != "" {
+		t.Errorf("tax codes = %q, %q; want clothing and none", items[0].TaxCode, items[1].TaxCode)
+	}
+	if items[0].Amount.Amount != 5000 || items[0].Quantity != 2 {
+		t.Errorf("first item = %s x %d, want the USD 50.00 line amount x 2", items[0].Amount, items[0].Quantity)
+	}
+}
+
+func TestPriceCartMarksItemsTaxableUnlessExempt(t *testing.T) {
+	ctx := context.Background()
+	taxCalc := &recordingTax{}
+	s := NewPricingService(newPromotionRepo(), taxCalc, nil)
+
+	_, err := s.PriceCart(ctx, PriceCartRequest{
+		Cart: testCart(
+			cart.CartItem{SKU: "MUG", Price: usd(1200), Quantity: 1},
+			cart.CartItem{SKU: "BREAD", Price: usd(400), Quantity: 1, TaxCode: tax.TaxCodeExempt},
+			cart.CartItem{SKU: "SHIRT", Price: usd(2500), Quantity: 1, TaxCode: "clothing"},
+		),
+		ShippingAddress: &Address{Country: "US", State: "NY"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, want := range []bool{true, false, true} {
+		if got := taxCalc.req.LineItems[i].IsTaxable; got != want {
+			t.Errorf("item %d IsTaxable = %t, want %t", i, got, want)
+		}
+	}
+}
+
+func TestListPromotionsBuckets(t *testing.T) {
+	ctx := context.Background()
+	now := time.Now()
+	upcoming := activePromotion("SUMMER", DiscountTypePercentage, 0.10)
+	upcoming.ValidFrom, upcoming.ValidTo = now.Add(24*time.Hour), now.Add(48*time.Hour)
+	expired := activePromotion("SPRING", DiscountTypePercentage, 0.10)
+	expired.ValidFrom, expired.ValidTo = now.Add(-48*time.Hour), now.Add(-24*time.Hour)
+	usedUp := activePromotion("GONE", DiscountTypePercentage, 0.10)
+	usedUp.UsageLimit, usedUp.UsageCount = 1, 1
+	off := activePromotion("OFF", DiscountTypePercentage, 0.10)
+	off.IsActive = false
+	b := activePromotion("B-NOW", DiscountTypePercentage, 0.10)
+	a := activePromotion("A-NOW", DiscountTypePercentage, 0.10)
+	a.ValidFrom = b.ValidFrom
+	s := NewPricingService(newPromotionRepo(upcoming, expired, usedUp, off, b, a), nil, nil)
+
+	schedule, err := s.ListPromotions(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	codes := func(promotions []*Promotion) []string {
+		var out []string
+		for _, p := range promotions {
+			out = append(out, p.Code)
+		}
+		return out
+	}
+	tests := []struct {
+		bucket string
+		got    []*Promotion
+		want   []string
+	}{
+		{"upcoming", schedule.Upcoming, []string{"SUMMER"}},
+		{"active", schedule.Active, []string{"A-NOW", "B-NOW"}},
+		{"expired", schedule.Expired, []string{"SPRING", "GONE"}},
+	}
+	for _, tt := range tests {
+		got := codes(tt.got)
+		if len(got) != len(tt.want) {
+			t.Errorf("%s = %v, want %v", tt.bucket, got, tt.want)
+			continue
+		}
+		for i := range got {
+			if got[i] != tt.want[i] {
+				t.Errorf("%s = %v, want %v", tt.bucket, got, tt.want)
+				break
+			}
+		}
+	}
+}
+
+func TestPriceCartAppliesPromotionsByPriorityThenCode(t *testing.T) {
+	ctx := context.Background()
+	first := activePromotion("ZFIRST", DiscountTypeFixedAmount, 800)
+	first.Priority = -1
+	repo := newPromotionRepo(
+		first,
+		activePromotion("BETA", DiscountTypeFixedAmount, 500),
+		activePromotion("ALPHA", DiscountTypeFixedAmount, 500),
+	)
+	s := NewPricingService(repo, nil, nil)
+	c := testCart(cart.CartItem{SKU: "A", Price: usd(1000), Quantity: 1})
+
+	// Whatever order the codes arrive in, ZFIRST takes $8 of the $10 line,
+	// ALPHA the remaining $2, and BETA nothing.
+	for _, codes := range [][]string{{"BETA", "ALPHA", "ZFIRST"}, {"ZFIRST", "BETA", "ALPHA"}} {
+		result, err := s.PriceCart(ctx, PriceCartRequest{Cart: c, PromotionCodes: codes})
+		if err != nil {
+			t.Fatal(err)
+		}
+		want := []struct {
+			code   string
+			amount int64
+		}{{"ZFIRST", 800}, {"ALPHA", 200}}
+		if len(result.AppliedDiscounts) != len(want) {
+			t.Fatalf("codes %v: applied %d discounts, want %d", codes, len(result.AppliedDiscounts), len(want))
+		}
+		for i, w := range want {
+			got := result.AppliedDiscounts[i]
+			if got.Code != w.code || got.Amount.Amount != w.amount {
+				t.Errorf("codes %v: discount %d = %s %d, want %s %d", codes, i, got.Code, got.Amount.Amount, w.code, w.amount)
+			}
+		}
+	}
+}
+
+func TestPriceCartEstimatesCheapestShipping(t *testing.T) {
+	ctx := context.Background()
+	c := testCart(cart.CartItem{SKU: "A", Price: usd(2000), Quantity: 1})
+	s := NewPricingService(newPromotionRepo(), nil, flatRates{
+		"express": usd(1500),
+		"ground":  usd(599),
+		"economy": usd(599),
+		"euro":    {Amount: 100, Currency: "EUR"},
+	})
+
+	result, err := s.PriceCart(ctx, PriceCartRequest{Cart: c, EstimateShipping: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !result.ShippingEstimated || result.EstimatedShippingMethodID != "economy" || result.ShippingTotal.Amount != 599 {
+		t.Errorf("estimate = %t %q %s, want economy at USD 5.99", result.ShippingEstimated, result.EstimatedShippingMethodID, result.ShippingTotal)
+	}
+	if result.Total.Amount != 2599 {
+		t.Errorf("Total = %s, want USD 25.99", result.Total)
+	}
+
+	result, err = s.PriceCart(ctx, PriceCartRequest{Cart: c})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.ShippingEstimated || !result.ShippingTotal.IsZero() {
+		t.Errorf("without EstimateShipping: estimated %t, shipping %s", result.ShippingEstimated, result.ShippingTotal)
+	}
+}
+
+func TestPriceCartSpendTieredPercentage(t *testing.T) {
+	ctx := context.Background()
+	tiered := activePromotion("SPEND", DiscountTypePercentage, 0)
+	tiered.Tiers = []DiscountTier{
+		{Threshold: usd(20000), PercentOff: 0.20},
+		{Threshold: usd(5000), PercentOff: 0.05},
+		{Threshold: usd(10000), PercentOff: 0.10},
+	}
+	s := NewPricingService(newPromotionRepo(tiered), nil, nil)
+
+	tests := []struct {
+		name         string
+		price        int64
+		wantDiscount int64
+	}{
+		{"below every tier", 4999, 0},
+		{"first tier, inclusive", 5000, 250},
+		{"middle tier", 15000, 1500},
+		{"top tier", 30000, 6000},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := s.PriceCart(ctx, PriceCartRequest{
+				Cart:           testCart(cart.CartItem{SKU: "A", Price: usd(tt.price), Quantity: 1}),
+				PromotionCodes: []string{"SPEND"},
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if result.DiscountTotal.Amount != tt.wantDiscount {
+				t.Errorf("DiscountTotal = %s, want %d", result.DiscountTotal, tt.wantDiscount)
+			}
+		})
+	}
+}
+
+func TestPriceCartIgnoresCurrencyCase(t *testing.T) {
+	ctx := context.Background()
+	tiered := activePromotion("SPEND", DiscountTypePercentage, 0)
+	tiered.Tiers = []DiscountTier{{Threshold: money.Money{Amount: 5000, Currency: "usd"}, PercentOff: 0.10}}
+	s := NewPricingService(newPromotionRepo(tiered), nil, flatRates{"ground": {Amount: 599, Currency: "usd"}})
+
+	result, err := s.PriceCart(ctx, PriceCartRequest{
+		Cart:             testCart(cart.CartItem{SKU: "A", Price: usd(10000), Quantity: 1}),
+		PromotionCodes:   []string{"SPEND"},
+		EstimateShipping: true,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.DiscountTotal.Amount != 1000 {
+		t.Errorf("DiscountTotal = %s, want the lowercase-currency tier's 10.00", result.DiscountTotal)
+	}
+	if result.EstimatedShippingMethodID != "ground" || result.ShippingTotal.Amount != 599 {
+		t.Errorf("estimate = %q %s, want the lowercase-currency ground rate", result.EstimatedShippingMethodID, result.ShippingTotal)
+	}
+}
+
+func TestPriceCartBuyXGetYDiscountsCheapestUnits(t *testing.T) {
+	ctx := context.Background()
+	b2g1 := activePromotion("B2G1", DiscountTypeBuyXGetY, 0)
+	b2g1.BuyQuantity, b2g1.GetQuantity = 2, 1
+	half := activePromotion("B1G1HALF", DiscountTypeBuyXGetY, 0)
+	half.BuyQuantity, half.GetQuantity, half.GetPercentOff = 1, 1, 0.5
+	s := NewPricingService(newPromotionRepo(b2g1, half), nil, nil)
+
+	tests := []struct {
+		name         string
+		code         string
+		items        []cart.CartItem
+		wantDiscount []int64
+	}{
+		{"cheapest unit free", "B2G1", []cart.CartItem{
+			{SKU: "A", Price: usd(3000), Quantity: 1},
+			{SKU: "B", Price: usd(1000), Quantity: 1},
+			{SKU: "C", Price: usd(2000), Quantity: 1},
+		}, []int64{0, 1000, 0}},
+		{"two free of seven", "B2G1", []cart.CartItem{
+			{SKU: "A", Price: usd(3000), Quantity: 4},
+			{SKU: "B", Price: usd(1000), Quantity: 1},
+			{SKU: "C", Price: usd(2000), Quantity: 2},
+		}, []int64{0, 1000, 2000}},
+		{"not enough units", "B2G1", []cart.CartItem{
+			{SKU: "A", Price: usd(3000), Quantity: 2},
+		}, []int64{0}},
+		{"half off", "B1G1HALF", []cart.CartItem{
+			{SKU: "A", Price: usd(3000), Quantity: 1},
+			{SKU: "B", Price: usd(1000), Quantity: 1},
+		}, []int64{0, 500}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := s.PriceCart(ctx, PriceCartRequest{Cart: testCart(tt.items...), PromotionCodes: []string{tt.code}})
+			if err != nil {
+				t.Fatal(err)
+			}
+			for i, want := range tt.wantDiscount {
+				if got := result.LineItemPrices[i].DiscountAmount.Amount; got != want {
+					t.Errorf("line %d discount = %d, want %d", i, got, want)
+				}
+			}
+		})
+	}
+}
+
+func TestPriceCartMaxDiscountSharedProportionally(t *testing.T) {
+	ctx := context.Background()
+	capped := activePromotion("SAVE20", DiscountTypePercentage, 0.20)
+	limit := usd(1500)
+	capped.MaxDiscount = &limit
+	s := NewPricingService(newPromotionRepo(capped), nil, nil)
+
+	// 20% of $100 and $50 is $30; the $15 cap is split 2:1 like the
+	// uncapped discounts.
+	result, err := s.PriceCart(ctx, PriceCartRequest{
+		Cart: testCart(
+			cart.CartItem{SKU: "A", Price: usd(10000), Quantity: 1},
+			cart.CartItem{SKU: "B", Price: usd(5000), Quantity: 1},
+		),
+		PromotionCodes: []string{"SAVE20"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.DiscountTotal.Amount != 1500 {
+		t.Errorf("DiscountTotal = %s, want USD 15.00", result.DiscountTotal)
+	}
+	for i, want := range []int64{1000, 500} {
+		if got := result.LineItemPrices[i].DiscountAmount.Amount; got != want {
+			t.Errorf("line %d discount = %d, want %d", i, got, want)
+		}
+	}
+
+	small, err := s.PriceCart(ctx, PriceCartRequest{
+		Cart:           testCart(cart.CartItem{SKU: "A", Price: usd(5000), Quantity: 1}),
+		PromotionCodes: []string{"SAVE20"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if small.DiscountTotal.Amount != 1000 {
+		t.Errorf("under the cap: DiscountTotal = %s, want USD 10.00", small.DiscountTotal)
+	}
+}
+
+func TestPriceCartApplicableCategories(t *testing.T) {
+	ctx := context.Background()
+	mugs := activePromotion("MUGS10", DiscountTypePercentage, 0.10)
+	mugs.ApplicableCategoryIDs = []string{"mugs"}
+	mugs.ApplicableProductIDs = []string{"C"}
+	mugs.ExcludedProductIDs = []string{"B"}
+	s := NewPricingService(newPromotionRepo(mugs), nil, nil)
+
+	result, err := s.PriceCart(ctx, PriceCartRequest{
+		Cart: testCart(
+			cart.CartItem{SKU: "A", Price: usd(1000), Quantity: 1, CategoryID: "mugs"},
+			cart.CartItem{SKU: "B", Price: usd(1000), Quantity: 1, CategoryID: "mugs"},
+			cart.CartItem{SKU: "C", Price: usd(1000), Quantity: 1, CategoryID: "plates"},
+			cart.CartItem{SKU: "D", Price: usd(1000), Quantity: 1, CategoryID: "plates"},
+			cart.CartItem{SKU: "E", Price: usd(1000), Quantity: 1},
+		),
+		PromotionCodes: []string{"MUGS10"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// A by category, C by product; B is excluded, D and E don't match.
+	for i, want := range []int64{100, 0, 100, 0, 0} {
+		if got := result.LineItemPrices[i].DiscountAmount.Amount; got != want {
+			t.Errorf("line %d discount = %d, want %d", i, got, want)
+		}
+	}
+}
+
+func TestPriceCartExclusivePromotions(t *testing.T) {
+	ctx := context.Background()
+	save10 := activePromotion("SAVE10", DiscountTypePercentage, 0.10)
+	vip := activePromotion("VIP25", DiscountTypePercentage, 0.25)
+	vip.Exclusive = true
+	staff := activePromotion("STAFF30", DiscountTypePercentage, 0.30)
+	staff.Exclusive = true
+	staff.Priority = 1
+	other := activePromotion("OTHER50", DiscountTypePercentage, 0.50)
+	other.Exclusive = true
+	other.ApplicableProductIDs = []string{"Z"}
+	s := NewPricingService(newPromotionRepo(save10, vip, staff, other), nil, nil)
+
+	tests := []struct {
+		name         string
+		codes        []string
+		wantCodes    []string
+		wantDiscount int64
+	}{
+		{"exclusive applies alone", []string{"SAVE10", "VIP25"}, []string{"VIP25"}, 2500},
+		{"first exclusive by priority", []string{"STAFF30", "VIP25"}, []string{"VIP25"}, 2500},
+		{"exclusive with no eligible items", []string{"OTHER50", "SAVE10"}, []string{"SAVE10"}, 1000},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := s.PriceCart(ctx, PriceCartRequest{
+				Cart:           testCart(cart.CartItem{SKU: "A", Price: usd(10000), Quantity: 1}),
+				PromotionCodes: tt.codes,
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if result.DiscountTotal.Amount != tt.wantDiscount {
+				t.Errorf("DiscountTotal = %s, want %d", result.DiscountTotal, tt.wantDiscount)
+			}
+			if len(result.AppliedDiscounts) != len(tt.wantCodes) {
+				t.Fatalf("applied %d discounts, want %v", len(result.AppliedDiscounts), tt.wantCodes)
+			}
+			for i, d := range result.AppliedDiscounts {
+				if d.Code != tt.wantCodes[i] {
+					t.Errorf("discount %d = %s, want %s", i, d.Code, tt.wantCodes[i])
+				}
+			}
+		})
+	}
+}
+
+// memberDiscounts is a CustomerDiscountResolver keyed by user ID.
+type memberDiscounts map[string]*Promotion
+
+func (m memberDiscounts) CustomerDiscount(ctx context.Context, userID string) (*Promotion, error) {
+	return m[userID], nil
+}
+
+func TestPriceCartCustomerDiscount(t *testing.T) {
+	ctx := context.Background()
+	gold := &Promotion{ID: "gold", Code: "GOLD", DiscountType: DiscountTypePercentage, Value: 0.05}
+	save10 := activePromotion("SAVE10", DiscountTypePercentage, 0.10)
+	s := NewPricingService(newPromotionRepo(save10), nil, nil,
+		WithCustomerDiscounts(memberDiscounts{"member": gold}))
+
+	tests := []struct {
+		name         string
+		userID       string
+		wantCodes    []string
+		wantDiscount int64
+	}{
+		{"member", "member", []string{"GOLD", "SAVE10"}, 1500},
+		{"other customer", "guest", []string{"SAVE10"}, 1000},
+		{"anonymous cart", "", []string{"SAVE10"}, 1000},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := testCart(cart.CartItem{SKU: "A", Price: usd(10000), Quantity: 1})
+			c.UserID = tt.userID
+			result, err := s.PriceCart(ctx, PriceCartRequest{Cart: c, PromotionCodes: []string{"SAVE10"}})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if result.DiscountTotal.Amount != tt.wantDiscount {
+				t.Errorf("DiscountTotal = %s, want %d", result.DiscountTotal, tt.wantDiscount)
+			}
+			if len(result.AppliedDiscounts) != len(tt.wantCodes) {
+				t.Fatalf("applied %d discounts, want %v", len(result.AppliedDiscounts), tt.wantCodes)
+			}
+			for i, d := range result.AppliedDiscounts {
+				if d.Code != tt.wantCodes[i] || d.Automatic != (d.Code == "GOLD") {
+					t.Errorf("discount %d = %s (automatic %t), want %s", i, d.Code, d.Automatic, tt.wantCodes[i])
+				}
+			}
+		})
+	}
+}
+
+func TestValidatePromotionReasons(t *testing.T) {
+	ctx := context.Background()
+	upcoming := activePromotion("UPCOMING", DiscountTypePercentage, 0.1)
+	upcoming.ValidFrom = time.Now().Add(time.Hour)
+	upcoming.ValidTo = time.Now().Add(2 * time.Hour)
+	expired := activePromotion("EXPIRED", DiscountTypePercentage, 0.1)
+	expired.ValidTo = time.Now().Add(-time.Minute)
+	inactive := activePromotion("INACTIVE", DiscountTypePercentage, 0.1)
+	inactive.IsActive = false
+	usedUp := activePromotion("USEDUP", DiscountTypePercentage, 0.1)
+	usedUp.UsageLimit, usedUp.UsageCount = 5, 5
+	minimum := activePromotion("MIN50", DiscountTypePercentage, 0.1)
+	min50 := usd(5000)
+	minimum.MinPurchase = &min50
+	s := NewPricingService(newPromotionRepo(upcoming, expired, inactive, usedUp, minimum,
+		activePromotion("OK", DiscountTypePercentage, 0.1)), nil, nil)
+
+	tests := []struct {
+		code string
+		want error
+	}{
+		{"MISSING", ErrPromotionNotFound},
+		{"UPCOMING", ErrPromotionNotStarted},
+		{"EXPIRED", ErrPromotionInvalid},
+		{"INACTIVE", ErrPromotionInvalid},
+		{"USEDUP", ErrPromotionUsageExceeded},
+		{"MIN50", ErrMinPurchaseNotMet},
+		{"OK", nil},
+	}
+	for _, tt := range tests {
+		if _, err := s.ValidatePromotion(ctx, tt.code, usd(2000)); !errors.Is(err, tt.want) {
+			t.Errorf("%s: error = %v, want %v", tt.code, err, tt.want)
+		}
+	}
+}
+
+func TestPriceCartRejectedPromotions(t *testing.T) {
+	ctx := context.Background()
+	expired := activePromotion("EXPIRED", DiscountTypePercentage, 0.1)
+	expired.ValidTo = time.Now().Add(-time.Minute)
+	s := NewPricingService(newPromotionRepo(expired, activePromotion("SAVE10", DiscountTypePercentage, 0.1)), nil, nil)
+	req := PriceCartRequest{
+		Cart:           testCart(cart.CartItem{SKU: "A", Price: usd(10000), Quantity: 1}),
+		PromotionCodes: []string{"SAVE10", "EXPIRED", "MISSING"},
+	}
+
+	result, err := s.PriceCart(ctx, req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.DiscountTotal.Amount != 1000 {
+		t.Errorf("DiscountTotal = %s, want USD 10.00", result.DiscountTotal)
+	}
+	want := map[string]error{"EXPIRED": ErrPromotionInvalid, "MISSING": ErrPromotionNotFound}
+	if len(result.RejectedPromotions) != len(want) {
+		t.Fatalf("rejected = %+v, want EXPIRED and MISSING", result.RejectedPromotions)
+	}
+	for _, r := range result.RejectedPromotions {
+		if !errors.Is(r.Err, want[r.Code]) || r.Reason == "" {
+			t.Errorf("%s rejected with %v (%q), want %v", r.Code, r.Err, r.Reason, want[r.Code])
+		}
+	}
+
+	req.StrictPromotions = true
+	_, err = s.PriceCart(ctx, req)
+	var rejectedErr *PromotionRejectedError
+	if !errors.As(err, &rejectedErr) || len(rejectedErr.Rejected) != 2 {
+		t.Fatalf("strict mode: error = %v, want *PromotionRejectedError with 2 codes", err)
+	}
+	if !errors.Is(err, ErrPromotionNotFound) || !errors.Is(err, ErrPromotionInvalid) {
+		t.Errorf("strict mode error %v doesn't wrap the rejection reasons", err)
+	}
+}
+
+func TestPriceCartTieredPromotion(t *testing.T) {
+	ctx := context.Background()
+	off10 := usd(1000)
+	tiered := activePromotion("TIERS", DiscountTypeTiered, 0)
+	tiered.Tiers = []DiscountTier{
+		{Threshold: usd(5000), AmountOff: &off10},
+		{Threshold: usd(10000), PercentOff: 0.15},
+	}
+	s := NewPricingService(newPromotionRepo(tiered), nil, nil)
+
+	tests := []struct {
+		name         string
+		prices       []int64
+		wantDiscount []int64
+	}{
+		{"below every tier", []int64{4000}, []int64{0}},
+		{"fixed tier spread by subtotal", []int64{4500, 1500}, []int64{750, 250}},
+		{"percentage tier", []int64{8000, 4000}, []int64{1200, 600}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			items := make([]cart.CartItem, len(tt.prices))
+			for i, price := range tt.prices {
+				items[i] = cart.CartItem{SKU: string(rune('A' + i)), Price: usd(price), Quantity: 1}
+			}
+			result, err := s.PriceCart(ctx, PriceCartRequest{Cart: testCart(items...), PromotionCodes: []string{"TIERS"}})
+			if err != nil {
+				t.Fatal(err)
+			}
+			for i, want := range tt.wantDiscount {
+				if got := result.LineItemPrices[i].DiscountAmount.Amount; got != want {
+					t.Errorf("line %d discount = %d, want %d", i, got, want)
+				}
+			}
+		})
+	}
+
+	// AmountOff is ignored on percentage promotions' tiers.
+	percentage := activePromotion("PCT", DiscountTypePercentage, 0)
+	percentage.Tiers = []DiscountTier{{Threshold: usd(5000), PercentOff: 0.05, AmountOff: &off10}}
+	s = NewPricingService(newPromotionRepo(percentage), nil, nil)
+	result, err := s.PriceCart(ctx, PriceCartRequest{
+		Cart:           testCart(cart.CartItem{SKU: "A", Price: usd(6000), Quantity: 1}),
+		PromotionCodes: []string{"PCT"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.DiscountTotal.Amount != 300 {
+		t.Errorf("percentage tier with AmountOff: DiscountTotal = %s, want USD 3.00", result.DiscountTotal)
+	}
+}
+
+func TestPriceCartRejectsInvalidCart(t *testing.T) {
+	s := NewPricingService(newPromotionRepo(), nil, nil)
+	_, err := s.PriceCart(context.Background(), PriceCartRequest{
+		Cart: testCart(cart.CartItem{SKU: "A", Price: usd(1000), Quantity: 0}),
+	})
+	if !errors.Is(err, cart.ErrInvalidCart) {
+		t.Errorf("error = %v, want %v", err, cart.ErrInvalidCart)
+	}
+}
+
+// shippingMethods is a shipping.Repository holding methods by ID.
+type shippingMethods struct {
+	shipping.Repository
+	methods map[string]*shipping.ShippingMethod
+}
+
+func (r shippingMethods) FindMethod(ctx context.Context, id string) (*shipping.ShippingMethod, error) {
+	m, ok := r.methods[id]
+	if !ok {
+		return nil, shipping.ErrMethodUnavailable
+	}
+	return m, nil
+}
+
+func TestPriceCartRatesShippingByWeight(t *testing.T) {
+	ctx := context.Background()
+	flat, perKg := usd(500), usd(200)
+	calc := shipping.NewRuleBasedCalculator(shippingMethods{methods: map[string]*shipping.ShippingMethod{
+		"ground": {ID: "ground", IsActive: true, FlatRate: &flat, RatePerWeightKg: &perKg},
+	}})
+	s := NewPricingService(newPromotionRepo(), nil, calc, WithDefaultShippingMethod("ground"))
+
+	tests := []struct {
+		name         string
+		weightGrams  int
+		quantity     int
+		wantShipping int64
+	}{
+		{"light", 500, 1, 600},
+		{"heavier unit", 2500, 1, 1000},
+		{"more units", 2500, 4, 2500},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := s.PriceCart(ctx, PriceCartRequest{
+				Cart: testCart(cart.CartItem{SKU: "A", Price: usd(2000), Quantity: tt.quantity, WeightGrams: tt.weightGrams}),
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if result.ShippingTotal.Amount != tt.wantShipping {
+				t.Errorf("ShippingTotal = %s, want %d", result.ShippingTotal, tt.wantShipping)
+			}
+		})
+	}
+}
@@ -12,6 +12,7 @@ import (
 
 	"github.com/devchuckcamp/gocommerce/cart"
 	"github.com/devchuckcamp/gocommerce/catalog"
+	"github.com/devchuckcamp/gocommerce/inventory"
 	"github.com/devchuckcamp/gocommerce/orders"
 	"github.com/devchuckcamp/gocommerce/pricing"
 
@@ -28,6 +29,7 @@ func main() {
 	var variantRepo catalog.VariantRepository
 	var orderRepo orders.Repository
 	var promotionRepo pricing.PromotionRepository
+	var inventoryService inventory.Service // Stock isn't tracked in Postgres mode
 
 	if usePostgres {
 		db, err := postgres.Open()
@@ -61,6 +63,10 @@ func main() {
 		variantRepo = &store.variantRepo
 		orderRepo = &store.orderRepo
 		promotionRepo = &store.promotionRepo
+
+		inventoryRepo := inventory.NewMemoryRepository()
+		seedInventory(inventoryRepo)
+		inventoryService = inventory.NewMemoryService(inventoryRepo)
 	}
 
 	// Create domain services
@@ -68,20 +74,20 @@ func main() {
 		cartRepo,
 		productRepo,
 		variantRepo,
-		nil, // No inventory service for demo
+		inventoryService,
 		generateID,
 	)
 
 	pricingService := pricing.NewPricingService(
 		promotionRepo,
 		NewSimpleTaxCalculator(0.0875), // 8.75% tax
-		nil, // No shipping calculator for demo
+		NewFlatRateShippingCalculator(),
 	)
 
 	orderService := orders.NewOrderService(
 		orderRepo,
 		pricingService,
-		nil, // No inventory service
+		inventoryService,
 		nil, // No payment gateway
 		generateOrderNumber,
 		generateID,
@@ -305,6 +311,7 @@ func (api *API) handleCheckoutPreview(w http.ResponseWriter, r *http.Request) {
 			City       string `json:"city"`
 			PostalCode string `json:"postal_code"`
 		} `json:"shipping_address"`
+		ShippingMethodID string `json:"shipping_method_id"`
 	}
 	
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
@@ -323,8 +330,16 @@ func (api *API) handleCheckoutPreview(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 	
+	// Without a chosen method, preview the cheapest rate as an estimate.
+	var shippingMethodID *string
+	if req.ShippingMethodID != "" {
+		shippingMethodID = &req.ShippingMethodID
+	}
+
 	result, err := api.pricingService.PriceCart(r.Context(), pricing.PriceCartRequest{
-		Cart: shoppingCart,
+		Cart:             shoppingCart,
+		ShippingMethodID: shippingMethodID,
+		EstimateShipping: true,
 		ShippingAddress: &pricing.Address{
 			Country:    req.ShippingAddress.Country,
 			State:      req.ShippingAddress.State,
@@ -338,7 +353,11 @@ func (api *API) handleCheckoutPreview(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 	
-	respondJSON(w, result)
+	// Include the cart's weight so clients can show it next to the estimate.
+	respondJSON(w, struct {
+		*pricing.PricingResult
+		TotalWeightGrams int
+	}{result, shoppingCart.TotalWeightGrams()})
 }
 
 // Order handlers
@@ -367,7 +386,8 @@ func (api *API) handleOrders(w http.ResponseWriter, r *http.Request) {
 			Country      string `json:"country"`
 			Phone        string `json:"phone"`
 		} `json:"shipping_address"`
-		PaymentMethodID string `json:"payment_method_id"`
+		PaymentMethodID string            `json:"payment_method_id"`
+		Metadata        map[string]string `json:"metadata"`
 	}
 	
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
@@ -404,6 +424,7 @@ func (api *API) handleOrders(w http.ResponseWriter, r *http.Request) {
 		PaymentMethodID: req.PaymentMethodID,
 		IPAddress:       r.RemoteAddr,
 		UserAgent:       r.UserAgent(),
+		Metadata:        req.Metadata,
 	})
 	if err != nil {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
@@ -7,14 +7,19 @@ import (
 	"time"
 
 	"github.com/devchuckcamp/gocommerce/cart"
+	"github.com/lib/pq"
 )
 
+// uniqueCartUserIndex enforces one cart per user (migration 018).
+const uniqueCartUserIndex = "idx_carts_user_id_unique"
+
 type CartRepository struct {
-	db *sql.DB
+	db   *sql.DB
+	opts options
 }
 
-func NewCartRepository(db *sql.DB) *CartRepository {
-	return &CartRepository{db: db}
+func NewCartRepository(db *sql.DB, opts ...Option) *CartRepository {
+	return &CartRepository{db: db, opts: newOptions(opts)}
 }
 
 func (r *CartRepository) FindByID(ctx context.Context, id string) (*cart.Cart, error) {
@@ -34,11 +39,12 @@ func (r *CartRepository) FindByID(ctx context.Context, id string) (*cart.Cart, e
 	}
 	c.ExpiresAt = scanNullTime(expiresAt)
 
-	items, err := r.findItems(ctx, c.ID)
+	items, saved, err := r.findItems(ctx, c.ID)
 	if err != nil {
 		return nil, err
 	}
 	c.Items = items
+	c.SavedItems = saved
 	return &c, nil
 }
 
@@ -86,6 +92,10 @@ func (r *CartRepository) Save(ctx context.Context, c *cart.Cart) error {
 			updated_at = CURRENT_TIMESTAMP,
 			expires_at = EXCLUDED.expires_at
 	`, c.ID, c.UserID, c.SessionID, nullTime(c.CreatedAt), c.ExpiresAt)
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) && pqErr.Code == "23505" && pqErr.Constraint == uniqueCartUserIndex {
+		return cart.ErrDuplicateCart
+	}
 	if err != nil {
 		return err
 	}
@@ -95,7 +105,8 @@ func (r *CartRepository) Save(ctx context.Context, c *cart.Cart) error {
 		return err
 	}
 
-	for _, item := range c.Items {
+	for i, item := range append(append([]cart.CartItem{}, c.Items...), c.SavedItems...) {
+		savedForLater := i >= len(c.Items)
 		attrs, err := toJSONB(item.Attributes)
 		if err != nil {
 			return err
@@ -104,9 +115,10 @@ func (r *CartRepository) Save(ctx context.Context, c *cart.Cart) error {
 		_, err = tx.ExecContext(ctx, `
 			INSERT INTO cart_items (
 				id, cart_id, product_id, variant_id, sku, name,
-				price_amount, price_currency, quantity, added_at, attributes
+				price_amount, price_currency, quantity, added_at, attributes, tax_code,
+				weight_grams, length_cm, width_cm, height_cm, category_id, allow_backorder, saved_for_later
 			) VALUES (
-				$1,$2,$3,$4,$5,$6,$7,$8,$9,$10,$11
+				$1,$2,$3,$4,$5,$6,$7,$8,$9,$10,$11,NULLIF($12,''),$13,$14,$15,$16,NULLIF($17,''),$18,$19
 			)
 		`,
 			item.ID,
@@ -120,6 +132,14 @@ func (r *CartRepository) Save(ctx context.Context, c *cart.Cart) error {
 			item.Quantity,
 			nullTime(item.AddedAt),
 			attrs,
+			item.TaxCode,
+			item.WeightGrams,
+			item.LengthCm,
+			item.WidthCm,
+			item.HeightCm,
+			item.CategoryID,
+			item.AllowBackorder,
+			savedForLater,
 		)
 		if err != nil {
 			return err
@@ -137,21 +157,50 @@ func (r *CartRepository) Delete(ctx context.Context, id string) error {
 	return err
 }
 
-func (r *CartRepository) findItems(ctx context.Context, cartID string) ([]cart.CartItem, error) {
+// FindExpired relies on the expires_at index (migration 032) to pick each
+// batch without scanning the table. Deleting a cart cascades to its items.
+func (r *CartRepository) FindExpired(ctx context.Context, before time.Time, limit int) ([]string, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT id FROM carts
+		WHERE expires_at < $1
+		ORDER BY expires_at ASC
+		LIMIT $2
+	`, before, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	ids := make([]string, 0)
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+	return ids, rows.Err()
+}
+
+// findItems returns a cart's items and its saved-for-later items.
+func (r *CartRepository) findItems(ctx context.Context, cartID string) ([]cart.CartItem, []cart.CartItem, error) {
 	rows, err := r.db.QueryContext(ctx, `
-		SELECT id, product_id, variant_id, sku, name, price_amount, price_currency, quantity, added_at, COALESCE(attributes,'{}')
+		SELECT id, product_id, variant_id, sku, name, price_amount, price_currency, quantity, added_at, COALESCE(attributes,'{}'),
+			COALESCE(tax_code,''), weight_grams, length_cm, width_cm, height_cm, COALESCE(category_id,''), allow_backorder, saved_for_later
 		FROM cart_items
 		WHERE cart_id = $1
 		ORDER BY added_at ASC
 	`, cartID)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 	defer rows.Close()
 
 	items := make([]cart.CartItem, 0)
+	var saved []cart.CartItem
 	for rows.Next() {
 		var item cart.CartItem
+		var savedForLater bool
 		var variantID sql.NullString
 		var amount int64
 		var currency string
@@ -169,8 +218,16 @@ func (r *CartRepository) findItems(ctx context.Context, cartID string) ([]cart.C
 			&item.Quantity,
 			&addedAt,
 			&attrsRaw,
+			&item.TaxCode,
+			&item.WeightGrams,
+			&item.LengthCm,
+			&item.WidthCm,
+			&item.HeightCm,
+			&item.CategoryID,
+			&item.AllowBackorder,
+			&savedForLater,
 		); err != nil {
-			return nil, err
+			return nil, nil, err
 		}
 		if variantID.Valid {
 			v := variantID.String
@@ -178,15 +235,21 @@ func (r *CartRepository) findItems(ctx context.Context, cartID string) ([]cart.C
 		}
 		m, err := moneyFrom(amount, currency)
 		if err != nil {
-			return nil, err
+			return nil, nil, err
 		}
 		item.Price = m
 		item.AddedAt = addedAt
-		_ = fromJSONB(attrsRaw, &item.Attributes)
-		items = append(items, item)
+		if err := attributesFromJSONB(attrsRaw, &item.Attributes, r.opts.lenientAttributes); err != nil {
+			return nil, nil, err
+		}
+		if savedForLater {
+			saved = append(saved, item)
+		} else {
+			items = append(items, item)
+		}
 	}
 	if err := rows.Err(); err != nil {
-		return nil, err
+		return nil, nil, err
 	}
-	return items, nil
+	return items, saved, nil
 }
@@ -3,6 +3,7 @@ package postgres
 import (
 	"database/sql"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"time"
 
@@ -52,6 +53,59 @@ func fromJSONB[T any](b []byte, out *T) error {
 	return json.Unmarshal(b, out)
 }
 
+// Option configures optional repository behavior.
+type Option func(*options)
+
+type options struct {
+	lenientAttributes bool
+}
+
+// WithLenientAttributes makes attribute decoding tolerate legacy rows whose
+// values are not strings (numbers, booleans, nested objects). Such values are
+// kept as their JSON text instead of failing the read.
+func WithLenientAttributes() Option {
+	return func(o *options) {
+		o.lenientAttributes = true
+	}
+}
+
+func newOptions(opts []Option) options {
+	var o options
+	for _, opt := range opts {
+		opt(&o)
+	}
+	return o
+}
+
+// attributesFromJSONB decodes a JSONB attributes column. Non-string values are
+// an error unless lenient is set (see WithLenientAttributes).
+func attributesFromJSONB(b []byte, out *map[string]string, lenient bool) error {
+	err := fromJSONB(b, out)
+	if err == nil {
+		return nil
+	}
+	var typeErr *json.UnmarshalTypeError
+	if !lenient || !errors.As(err, &typeErr) {
+		return fmt.Errorf("decode attributes: %w", err)
+	}
+
+	var raw map[string]json.RawMessage
+	if err := json.Unmarshal(b, &raw); err != nil {
+		return fmt.Errorf("decode attributes: %w", err)
+	}
+	attrs := make(map[string]string, len(raw))
+	for k, v := range raw {
+		var s string
+		if json.Unmarshal(v, &s) == nil {
+			attrs[k] = s
+		} else {
+			attrs[k] = string(v)
+		}
+	}
+	*out = attrs
+	return nil
+}
+
 func moneyFrom(amount int64, currency string) (money.Money, error) {
 	return money.New(amount, currency)
 }
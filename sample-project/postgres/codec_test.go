@@ -0,0 +1,28 @@
+package postgres
+
+import "testing"
+
+func TestAttributesFromJSONB(t *testing.T) {
+	legacy := []byte(`{"color":"red","size":42,"gift":true}`)
+
+	var strict map[string]string
+	if err := attributesFromJSONB(legacy, &strict, false); err == nil {
+		t.Error("strict decoding accepted non-string values")
+	}
+
+	var lenient map[string]string
+	if err := attributesFromJSONB(legacy, &lenient, newOptions([]Option{WithLenientAttributes()}).lenientAttributes); err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]string{"color": "red", "size": "42", "gift": "true"}
+	for k, v := range want {
+		if lenient[k] != v {
+			t.Errorf("%s = %q, want %q", k, lenient[k], v)
+		}
+	}
+
+	var plain map[string]string
+	if err := attributesFromJSONB([]byte(`{"color":"red"}`), &plain, false); err != nil || plain["color"] != "red" {
+		t.Errorf("string attributes: got %v, %v", plain, err)
+	}
+}
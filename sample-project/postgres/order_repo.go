@@ -7,15 +7,17 @@ import (
 	"fmt"
 	"strings"
 
+	"github.com/devchuckcamp/gocommerce/money"
 	"github.com/devchuckcamp/gocommerce/orders"
 )
 
 type OrderRepository struct {
-	db *sql.DB
+	db   *sql.DB
+	opts options
 }
 
-func NewOrderRepository(db *sql.DB) *OrderRepository {
-	return &OrderRepository{db: db}
+func NewOrderRepository(db *sql.DB, opts ...Option) *OrderRepository {
+	return &OrderRepository{db: db, opts: newOptions(opts)}
 }
 
 func (r *OrderRepository) FindByID(ctx context.Context, id string) (*orders.Order, error) {
@@ -32,16 +34,25 @@ func (r *OrderRepository) FindByID(ctx context.Context, id string) (*orders.Orde
 			COALESCE(user_agent,''),
 			COALESCE(shipping_address, '{}'::jsonb),
 			COALESCE(billing_address, '{}'::jsonb),
+			COALESCE(cancellation_reason,''),
+			COALESCE(cancellation_note,''),
+			COALESCE(metadata, 'null'::jsonb),
+			COALESCE(applied_promotions, 'null'::jsonb),
+			COALESCE(original_total_amount, 0), COALESCE(original_total_currency, ''),
+			COALESCE(exchange_rate, 0),
+			COALESCE(reservation_id, ''), inventory_commit_failed,
 			created_at, updated_at, completed_at, canceled_at
 		FROM orders
 		WHERE id = $1
 	`, id)
 
 	var o orders.Order
-	var status string
+	var status, cancellationReason string
 	var subtotalAmt, discountAmt, taxAmt, shippingAmt, totalAmt int64
 	var subtotalCur, discountCur, taxCur, shippingCur, totalCur string
-	var shippingAddr, billingAddr []byte
+	var originalTotalAmt int64
+	var originalTotalCur string
+	var shippingAddr, billingAddr, metadata, appliedPromotions []byte
 	var completedAt, canceledAt sql.NullTime
 
 	if err := row.Scan(
@@ -65,6 +76,15 @@ func (r *OrderRepository) FindByID(ctx context.Context, id string) (*orders.Orde
 		&o.UserAgent,
 		&shippingAddr,
 		&billingAddr,
+		&cancellationReason,
+		&o.CancellationNote,
+		&metadata,
+		&appliedPromotions,
+		&originalTotalAmt,
+		&originalTotalCur,
+		&o.ExchangeRate,
+		&o.ReservationID,
+		&o.InventoryCommitFailed,
 		&o.CreatedAt,
 		&o.UpdatedAt,
 		&completedAt,
@@ -76,16 +96,34 @@ func (r *OrderRepository) FindByID(ctx context.Context, id string) (*orders.Orde
 		return nil, err
 	}
 
-	o.Status = orders.OrderStatus(status)
+	parsedStatus, err := orders.ParseOrderStatus(status)
+	if err != nil {
+		return nil, err
+	}
+	o.Status = parsedStatus
+	o.CancellationReason = orders.CancellationReason(cancellationReason)
 	o.Subtotal, _ = moneyFrom(subtotalAmt, subtotalCur)
 	o.DiscountTotal, _ = moneyFrom(discountAmt, discountCur)
 	o.TaxTotal, _ = moneyFrom(taxAmt, taxCur)
 	o.ShippingTotal, _ = moneyFrom(shippingAmt, shippingCur)
 	o.Total, _ = moneyFrom(totalAmt, totalCur)
+	if originalTotalCur != "" {
+		o.OriginalTotal, _ = moneyFrom(originalTotalAmt, originalTotalCur)
+	}
 	o.CompletedAt = scanNullTime(completedAt)
 	o.CanceledAt = scanNullTime(canceledAt)
-	_ = fromJSONB(shippingAddr, &o.ShippingAddress)
-	_ = fromJSONB(billingAddr, &o.BillingAddress)
+	if err := fromJSONB(shippingAddr, &o.ShippingAddress); err != nil {
+		return nil, fmt.Errorf("decode shipping address: %w", err)
+	}
+	if err := fromJSONB(billingAddr, &o.BillingAddress); err != nil {
+		return nil, fmt.Errorf("decode billing address: %w", err)
+	}
+	if err := fromJSONB(metadata, &o.Metadata); err != nil {
+		return nil, fmt.Errorf("decode metadata: %w", err)
+	}
+	if err := fromJSONB(appliedPromotions, &o.AppliedPromotions); err != nil {
+		return nil, fmt.Errorf("decode applied promotions: %w", err)
+	}
 
 	items, err := r.findItems(ctx, o.ID)
 	if err != nil {
@@ -111,19 +149,16 @@ func (r *OrderRepository) FindByOrderNumber(ctx context.Context, orderNumber str
 func (r *OrderRepository) FindByUserID(ctx context.Context, userID string, filter orders.OrderFilter) ([]*orders.Order, error) {
 	q := `SELECT id FROM orders WHERE user_id = $1`
 	args := []any{userID}
+	return r.findOrders(ctx, q, args, filter)
+}
 
-	if filter.Status != nil {
-		args = append(args, string(*filter.Status))
-		q += fmt.Sprintf(" AND status = $%d", len(args))
-	}
-	if filter.DateFrom != nil {
-		args = append(args, *filter.DateFrom)
-		q += fmt.Sprintf(" AND created_at >= $%d", len(args))
-	}
-	if filter.DateTo != nil {
-		args = append(args, *filter.DateTo)
-		q += fmt.Sprintf(" AND created_at <= $%d", len(args))
-	}
+func (r *OrderRepository) FindAll(ctx context.Context, filter orders.OrderFilter) ([]*orders.Order, error) {
+	return r.findOrders(ctx, `SELECT id FROM orders WHERE 1=1`, nil, filter)
+}
+
+// findOrders runs an order ID query narrowed by filter and loads each order, newest first.
+func (r *OrderRepository) findOrders(ctx context.Context, q string, args []any, filter orders.OrderFilter) ([]*orders.Order, error) {
+	q, args = applyOrderFilter(q, args, filter)
 
 	q += " ORDER BY created_at DESC"
 	if filter.Limit > 0 {
@@ -164,6 +199,72 @@ func (r *OrderRepository) FindByUserID(ctx context.Context, userID string, filte
 	return out, nil
 }
 
+func (r *OrderRepository) SumTotals(ctx context.Context, filter orders.OrderFilter) (money.Money, error) {
+	sums, err := r.SumTotalsByCurrency(ctx, filter)
+	if err != nil {
+		return money.Money{}, err
+	}
+	if len(sums) > 1 {
+		return money.Money{}, money.ErrCurrencyMismatch
+	}
+	for _, sum := range sums {
+		return sum, nil
+	}
+	return money.Money{}, nil
+}
+
+func (r *OrderRepository) SumTotalsByCurrency(ctx context.Context, filter orders.OrderFilter) (map[string]money.Money, error) {
+	q := `SELECT COALESCE(total_currency, subtotal_currency), SUM(total_amount)::BIGINT FROM orders WHERE 1=1`
+	args := []any{}
+	q, args = applyOrderFilter(q, args, filter)
+	q += " GROUP BY 1"
+
+	rows, err := r.db.QueryContext(ctx, q, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	sums := make(map[string]money.Money)
+	for rows.Next() {
+		var currency string
+		var amount int64
+		if err := rows.Scan(&currency, &amount); err != nil {
+			return nil, err
+		}
+		m, err := moneyFrom(amount, currency)
+		if err != nil {
+			return nil, err
+		}
+		sums[currency] = m
+	}
+	return sums, rows.Err()
+}
+
+func (r *OrderRepository) CountByCancellationReason(ctx context.Context, filter orders.OrderFilter) (map[orders.CancellationReason]int, error) {
+	q := `SELECT COALESCE(cancellation_reason, $1), COUNT(*) FROM orders WHERE status = $2`
+	args := []any{string(orders.CancellationReasonOther), string(orders.OrderStatusCanceled)}
+	q, args = applyOrderFilter(q, args, orders.OrderFilter{DateFrom: filter.DateFrom, DateTo: filter.DateTo})
+	q += " GROUP BY 1"
+
+	rows, err := r.db.QueryContext(ctx, q, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	counts := make(map[orders.CancellationReason]int)
+	for rows.Next() {
+		var reason string
+		var count int
+		if err := rows.Scan(&reason, &count); err != nil {
+			return nil, err
+		}
+		counts[orders.CancellationReason(reason)] += count
+	}
+	return counts, rows.Err()
+}
+
 func (r *OrderRepository) Save(ctx context.Context, o *orders.Order) error {
 	if o == nil {
 		return errors.New("order is nil")
@@ -183,6 +284,14 @@ func (r *OrderRepository) Save(ctx context.Context, o *orders.Order) error {
 	if err != nil {
 		return err
 	}
+	metadata, err := toJSONB(o.Metadata)
+	if err != nil {
+		return err
+	}
+	appliedPromotions, err := toJSONB(o.AppliedPromotions)
+	if err != nil {
+		return err
+	}
 
 	tx, err := r.db.BeginTx(ctx, nil)
 	if err != nil {
@@ -198,6 +307,9 @@ func (r *OrderRepository) Save(ctx context.Context, o *orders.Order) error {
 			discount_currency, tax_currency, shipping_currency, total_currency,
 			payment_method_id, notes, ip_address, user_agent,
 			shipping_address, billing_address,
+			cancellation_reason, cancellation_note, metadata,
+			original_total_amount, original_total_currency, exchange_rate,
+			applied_promotions, reservation_id, inventory_commit_failed,
 			created_at, updated_at, completed_at, canceled_at
 		) VALUES (
 			$1,$2,$3,$4,
@@ -206,6 +318,9 @@ func (r *OrderRepository) Save(ctx context.Context, o *orders.Order) error {
 			$11,$12,$13,$14,
 			NULLIF($15,''),$16,NULLIF($17,''),$18,
 			$19,$20,
+			NULLIF($24,''),NULLIF($25,''),$26,
+			$27,NULLIF($28,''),NULLIF($29,0::numeric),
+			$30,NULLIF($31,''),$32,
 			COALESCE($21, CURRENT_TIMESTAMP), CURRENT_TIMESTAMP, $22, $23
 		)
 		ON CONFLICT (id) DO UPDATE SET
@@ -230,6 +345,15 @@ func (r *OrderRepository) Save(ctx context.Context, o *orders.Order) error {
 			billing_address = EXCLUDED.billing_address,
 			completed_at = EXCLUDED.completed_at,
 			canceled_at = EXCLUDED.canceled_at,
+			cancellation_reason = EXCLUDED.cancellation_reason,
+			cancellation_note = EXCLUDED.cancellation_note,
+			metadata = EXCLUDED.metadata,
+			original_total_amount = EXCLUDED.original_total_amount,
+			original_total_currency = EXCLUDED.original_total_currency,
+			exchange_rate = EXCLUDED.exchange_rate,
+			applied_promotions = EXCLUDED.applied_promotions,
+			reservation_id = EXCLUDED.reservation_id,
+			inventory_commit_failed = EXCLUDED.inventory_commit_failed,
 			updated_at = CURRENT_TIMESTAMP
 	`,
 		o.ID,
@@ -255,6 +379,15 @@ func (r *OrderRepository) Save(ctx context.Context, o *orders.Order) error {
 		nullTime(o.CreatedAt),
 		o.CompletedAt,
 		o.CanceledAt,
+		string(o.CancellationReason),
+		o.CancellationNote,
+		metadata,
+		o.OriginalTotal.Amount,
+		o.OriginalTotal.Currency,
+		o.ExchangeRate,
+		appliedPromotions,
+		o.ReservationID,
+		o.InventoryCommitFailed,
 	)
 	if err != nil {
 		return err
@@ -278,7 +411,7 @@ func (r *OrderRepository) Save(ctx context.Context, o *orders.Order) error {
 				discount_amount, discount_currency,
 				tax_amount, tax_currency,
 				total_amount, total_currency,
-				attributes
+				attributes, backordered
 			) VALUES (
 				$1,$2,$3,$4,$5,$6,
 				$7,$8,
@@ -286,7 +419,7 @@ func (r *OrderRepository) Save(ctx context.Context, o *orders.Order) error {
 				$10,$11,
 				$12,$13,
 				$14,$15,
-				$16
+				$16,$17
 			)
 		`,
 			item.ID,
@@ -305,6 +438,7 @@ func (r *OrderRepository) Save(ctx context.Context, o *orders.Order) error {
 			item.Total.Amount,
 			item.Total.Currency,
 			attrs,
+			item.Backordered,
 		)
 		if err != nil {
 			return err
@@ -327,7 +461,7 @@ func (r *OrderRepository) findItems(ctx context.Context, orderID string) ([]orde
 			discount_amount, discount_currency,
 			tax_amount, tax_currency,
 			total_amount, total_currency,
-			COALESCE(attributes, '{}'::jsonb)
+			COALESCE(attributes, '{}'::jsonb), backordered
 		FROM order_items
 		WHERE order_id = $1
 		ORDER BY created_at ASC
@@ -366,6 +500,7 @@ func (r *OrderRepository) findItems(ctx context.Context, orderID string) ([]orde
 			&totalAmt,
 			&totalCur,
 			&attrsRaw,
+			&it.Backordered,
 		); err != nil {
 			return nil, err
 		}
@@ -378,9 +513,29 @@ func (r *OrderRepository) findItems(ctx context.Context, orderID string) ([]orde
 		it.DiscountAmount, _ = moneyFrom(discAmt, discCur)
 		it.TaxAmount, _ = moneyFrom(taxAmt, taxCur)
 		it.Total, _ = moneyFrom(totalAmt, totalCur)
-		_ = fromJSONB(attrsRaw, &it.Attributes)
+		if err := attributesFromJSONB(attrsRaw, &it.Attributes, r.opts.lenientAttributes); err != nil {
+			return nil, err
+		}
 
 		items = append(items, it)
 	}
 	return items, rows.Err()
 }
+
+func applyOrderFilter(base string, args []any, filter orders.OrderFilter) (string, []any) {
+	q := base
+
+	if filter.Status != nil {
+		args = append(args, string(*filter.Status))
+		q += fmt.Sprintf(" AND status = $%d", len(args))
+	}
+	if filter.DateFrom != nil {
+		args = append(args, *filter.DateFrom)
+		q += fmt.Sprintf(" AND created_at >= $%d", len(args))
+	}
+	if filter.DateTo != nil {
+		args = append(args, *filter.DateTo)
+		q += fmt.Sprintf(" AND created_at <= $%d", len(args))
+	}
+	return q, args
+}
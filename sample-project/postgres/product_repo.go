@@ -9,21 +9,23 @@ import (
 	"time"
 
 	"github.com/devchuckcamp/gocommerce/catalog"
+	"github.com/lib/pq"
 )
 
 type ProductRepository struct {
-	db *sql.DB
+	db   *sql.DB
+	opts options
 }
 
-func NewProductRepository(db *sql.DB) *ProductRepository {
-	return &ProductRepository{db: db}
+func NewProductRepository(db *sql.DB, opts ...Option) *ProductRepository {
+	return &ProductRepository{db: db, opts: newOptions(opts)}
 }
 
 func (r *ProductRepository) FindByID(ctx context.Context, id string) (*catalog.Product, error) {
 	row := r.db.QueryRowContext(ctx, `
 		SELECT id, sku, name, COALESCE(description,''), COALESCE(brand_id,''), COALESCE(category_id,''),
 			base_price_amount, base_price_currency, status, COALESCE(images,'[]'), COALESCE(attributes,'{}'),
-			created_at, updated_at
+			COALESCE(tax_code,''), weight_grams, length_cm, width_cm, height_cm, allow_backorder, max_per_order, created_at, updated_at
 		FROM products
 		WHERE id = $1
 	`, id)
@@ -34,6 +36,7 @@ func (r *ProductRepository) FindByID(ctx context.Context, id string) (*catalog.P
 	var currency string
 	var status string
 	var imagesRaw, attrsRaw []byte
+	var maxPerOrder sql.NullInt64
 	var createdAt, updatedAt time.Time
 
 	if err := row.Scan(
@@ -48,6 +51,13 @@ func (r *ProductRepository) FindByID(ctx context.Context, id string) (*catalog.P
 		&status,
 		&imagesRaw,
 		&attrsRaw,
+		&p.TaxCode,
+		&p.WeightGrams,
+		&p.LengthCm,
+		&p.WidthCm,
+		&p.HeightCm,
+		&p.AllowBackorder,
+		&maxPerOrder,
 		&createdAt,
 		&updatedAt,
 	); err != nil {
@@ -59,14 +69,22 @@ func (r *ProductRepository) FindByID(ctx context.Context, id string) (*catalog.P
 
 	p.BrandID = scanNullString(brandID)
 	p.CategoryID = scanNullString(categoryID)
+	if maxPerOrder.Valid {
+		limit := int(maxPerOrder.Int64)
+		p.MaxPerOrder = &limit
+	}
 	m, err := moneyFrom(amount, currency)
 	if err != nil {
 		return nil, err
 	}
 	p.BasePrice = m
 	p.Status = catalog.ProductStatus(status)
-	_ = fromJSONB(imagesRaw, &p.Images)
-	_ = fromJSONB(attrsRaw, &p.Attributes)
+	if err := fromJSONB(imagesRaw, &p.Images); err != nil {
+		return nil, fmt.Errorf("decode images: %w", err)
+	}
+	if err := attributesFromJSONB(attrsRaw, &p.Attributes, r.opts.lenientAttributes); err != nil {
+		return nil, err
+	}
 	p.CreatedAt = createdAt
 	p.UpdatedAt = updatedAt
 	return &p, nil
@@ -98,10 +116,81 @@ func (r *ProductRepository) FindByBrand(ctx context.Context, brandID string, fil
 	return r.listByQuery(ctx, q, args...)
 }
 
-func (r *ProductRepository) Search(ctx context.Context, query string, filter catalog.ProductFilter) ([]*catalog.Product, error) {
-	q := `SELECT id FROM products WHERE (name ILIKE $1 OR sku ILIKE $1)`
-	args := []any{"%" + query + "%"}
-	q, args = applyProductFilter(q, args, filter)
+// productSearchDocument is the text matched by full-text search.
+const productSearchDocument = `to_tsvector('simple', name || ' ' || COALESCE(description,'') || ' ' || sku)`
+
+func (r *ProductRepository) Search(ctx context.Context, query string, filter catalog.ProductFilter) (*catalog.SearchResult, error) {
+	// $1 is the raw query, $2 the substring pattern.
+	where := ` FROM products WHERE (` + productSearchDocument + ` @@ plainto_tsquery('simple', $1)
+		OR name ILIKE $2 OR sku ILIKE $2)`
+	args := []any{query, "%" + query + "%"}
+	where, args = applyProductConditions(where, args, filter)
+
+	// A negative offset is treated as no offset.
+	if filter.Offset < 0 {
+		filter.Offset = 0
+	}
+	result := &catalog.SearchResult{
+		Hits:   make([]catalog.SearchHit, 0),
+		Limit:  filter.Limit,
+		Offset: filter.Offset,
+	}
+	if err := r.db.QueryRowContext(ctx, `SELECT COUNT(*)`+where, args...).Scan(&result.TotalCount); err != nil {
+		return nil, err
+	}
+
+	q := `SELECT id, ts_rank(` + productSearchDocument + `, plainto_tsquery('simple', $1))
+			+ CASE WHEN name ILIKE $2 THEN 0.5 ELSE 0 END
+			+ CASE WHEN LOWER(sku) = LOWER($1) THEN 1 ELSE 0 END AS score` + where + `
+		ORDER BY score DESC, id ASC`
+	if filter.Limit > 0 {
+		args = append(args, filter.Limit)
+		q += fmt.Sprintf(" LIMIT $%d", len(args))
+	}
+	if filter.Offset > 0 {
+		args = append(args, filter.Offset)
+		q += fmt.Sprintf(" OFFSET $%d", len(args))
+	}
+
+	rows, err := r.db.QueryContext(ctx, q, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	type scored struct {
+		id    string
+		score float64
+	}
+	matches := make([]scored, 0)
+	for rows.Next() {
+		var m scored
+		if err := rows.Scan(&m.id, &m.score); err != nil {
+			return nil, err
+		}
+		matches = append(matches, m)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	for _, m := range matches {
+		p, err := r.FindByID(ctx, m.id)
+		if err != nil {
+			return nil, err
+		}
+		result.Hits = append(result.Hits, catalog.SearchHit{Product: p, Score: m.score})
+	}
+	return result, nil
+}
+
+func (r *ProductRepository) FindUpdatedSince(ctx context.Context, since time.Time, limit int) ([]*catalog.Product, error) {
+	q := `SELECT id FROM products WHERE updated_at > $1 ORDER BY updated_at ASC, id ASC`
+	args := []any{since}
+	if limit > 0 {
+		args = append(args, limit)
+		q += fmt.Sprintf(" LIMIT $%d", len(args))
+	}
 	return r.listByQuery(ctx, q, args...)
 }
 
@@ -129,11 +218,11 @@ func (r *ProductRepository) Save(ctx context.Context, product *catalog.Product)
 		INSERT INTO products (
 			id, sku, name, description, brand_id, category_id,
 			base_price_amount, base_price_currency, status, images, attributes,
-			created_at, updated_at
+			tax_code, weight_grams, length_cm, width_cm, height_cm, allow_backorder, max_per_order, created_at, updated_at
 		) VALUES (
 			$1,$2,$3,$4,NULLIF($5,''),NULLIF($6,''),
 			$7,$8,$9,$10,$11,
-			COALESCE($12, CURRENT_TIMESTAMP), CURRENT_TIMESTAMP
+			NULLIF($13,''), $14, $15, $16, $17, $18, $19, COALESCE($12, CURRENT_TIMESTAMP), CURRENT_TIMESTAMP
 		)
 		ON CONFLICT (id) DO UPDATE SET
 			sku = EXCLUDED.sku,
@@ -146,6 +235,13 @@ func (r *ProductRepository) Save(ctx context.Context, product *catalog.Product)
 			status = EXCLUDED.status,
 			images = EXCLUDED.images,
 			attributes = EXCLUDED.attributes,
+			tax_code = EXCLUDED.tax_code,
+			weight_grams = EXCLUDED.weight_grams,
+			length_cm = EXCLUDED.length_cm,
+			width_cm = EXCLUDED.width_cm,
+			height_cm = EXCLUDED.height_cm,
+			allow_backorder = EXCLUDED.allow_backorder,
+			max_per_order = EXCLUDED.max_per_order,
 			updated_at = CURRENT_TIMESTAMP
 	`,
 		product.ID,
@@ -160,6 +256,13 @@ func (r *ProductRepository) Save(ctx context.Context, product *catalog.Product)
 		images,
 		attrs,
 		nullTime(product.CreatedAt),
+		product.TaxCode,
+		product.WeightGrams,
+		product.LengthCm,
+		product.WidthCm,
+		product.HeightCm,
+		product.AllowBackorder,
+		product.MaxPerOrder,
 	)
 	return err
 }
@@ -207,20 +310,7 @@ func (r *ProductRepository) listByQuery(ctx context.Context, q string, args ...a
 }
 
 func applyProductFilter(base string, args []any, filter catalog.ProductFilter) (string, []any) {
-	q := base
-
-	if filter.Status != nil {
-		args = append(args, string(*filter.Status))
-		q += fmt.Sprintf(" AND status = $%d", len(args))
-	}
-	if filter.MinPrice != nil {
-		args = append(args, *filter.MinPrice)
-		q += fmt.Sprintf(" AND base_price_amount >= $%d", len(args))
-	}
-	if filter.MaxPrice != nil {
-		args = append(args, *filter.MaxPrice)
-		q += fmt.Sprintf(" AND base_price_amount <= $%d", len(args))
-	}
+	q, args := applyProductConditions(base, args, filter)
 
 	// Sorting (keep it minimal and safe)
 	switch strings.ToLower(filter.SortBy) {
@@ -246,3 +336,35 @@ func applyProductFilter(base string, args []any, filter catalog.ProductFilter) (
 	}
 	return q, args
 }
+
+// applyProductConditions appends the WHERE conditions of filter, without sorting or paging.
+func applyProductConditions(base string, args []any, filter catalog.ProductFilter) (string, []any) {
+	q := base
+
+	if filter.Status != nil {
+		args = append(args, string(*filter.Status))
+		q += fmt.Sprintf(" AND status = $%d", len(args))
+	}
+	if filter.MinPrice != nil {
+		args = append(args, *filter.MinPrice)
+		q += fmt.Sprintf(" AND base_price_amount >= $%d", len(args))
+	}
+	if filter.MaxPrice != nil {
+		args = append(args, *filter.MaxPrice)
+		q += fmt.Sprintf(" AND base_price_amount <= $%d", len(args))
+	}
+	if len(filter.BrandIDs) > 0 {
+		args = append(args, pq.Array(filter.BrandIDs))
+		q += fmt.Sprintf(" AND brand_id = ANY($%d)", len(args))
+	}
+	if len(filter.CategoryIDs) > 0 {
+		args = append(args, pq.Array(filter.CategoryIDs))
+		q += fmt.Sprintf(" AND category_id = ANY($%d)", len(args))
+	}
+	if len(filter.Attributes) > 0 {
+		attrs, _ := toJSONB(filter.Attributes)
+		args = append(args, attrs)
+		q += fmt.Sprintf(" AND attributes @> $%d::jsonb", len(args))
+	}
+	return q, args
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"database/sql"
 	"errors"
+	"fmt"
 	"time"
 
 	"github.com/devchuckcamp/gocommerce/pricing"
@@ -23,10 +24,12 @@ func (r *PromotionRepository) FindByCode(ctx context.Context, code string) (*pri
 			min_purchase_amount, min_purchase_currency,
 			max_discount_amount, max_discount_currency,
 			COALESCE(valid_from, CURRENT_TIMESTAMP), COALESCE(valid_to, CURRENT_TIMESTAMP),
-			is_active, usage_limit, usage_count,
+			is_active, usage_limit, usage_count, priority,
 			COALESCE(applicable_product_ids, '[]'::jsonb),
 			COALESCE(applicable_category_ids, '[]'::jsonb),
-			COALESCE(excluded_product_ids, '[]'::jsonb)
+			COALESCE(excluded_product_ids, '[]'::jsonb),
+			COALESCE(tiers, '[]'::jsonb),
+			buy_quantity, get_quantity, get_percent_off, per_user_limit, exclusive
 		FROM promotions
 		WHERE code = $1
 	`, code)
@@ -36,7 +39,7 @@ func (r *PromotionRepository) FindByCode(ctx context.Context, code string) (*pri
 	var minAmount, maxAmount sql.NullInt64
 	var minCur, maxCur sql.NullString
 	var validFrom, validTo time.Time
-	var applicableProducts, applicableCategories, excludedProducts []byte
+	var applicableProducts, applicableCategories, excludedProducts, tiers []byte
 
 	if err := row.Scan(
 		&p.ID,
@@ -54,12 +57,19 @@ func (r *PromotionRepository) FindByCode(ctx context.Context, code string) (*pri
 		&p.IsActive,
 		&p.UsageLimit,
 		&p.UsageCount,
+		&p.Priority,
 		&applicableProducts,
 		&applicableCategories,
 		&excludedProducts,
+		&tiers,
+		&p.BuyQuantity,
+		&p.GetQuantity,
+		&p.GetPercentOff,
+		&p.PerUserLimit,
+		&p.Exclusive,
 	); err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
-			return nil, errors.New("promotion not found")
+			return nil, pricing.ErrPromotionNotFound
 		}
 		return nil, err
 	}
@@ -81,15 +91,24 @@ func (r *PromotionRepository) FindByCode(ctx context.Context, code string) (*pri
 		}
 	}
 
-	_ = fromJSONB(applicableProducts, &p.ApplicableProductIDs)
-	_ = fromJSONB(applicableCategories, &p.ApplicableCategoryIDs)
-	_ = fromJSONB(excludedProducts, &p.ExcludedProductIDs)
+	if err := fromJSONB(applicableProducts, &p.ApplicableProductIDs); err != nil {
+		return nil, fmt.Errorf("decode applicable product IDs: %w", err)
+	}
+	if err := fromJSONB(applicableCategories, &p.ApplicableCategoryIDs); err != nil {
+		return nil, fmt.Errorf("decode applicable category IDs: %w", err)
+	}
+	if err := fromJSONB(excludedProducts, &p.ExcludedProductIDs); err != nil {
+		return nil, fmt.Errorf("decode excluded product IDs: %w", err)
+	}
+	if err := fromJSONB(tiers, &p.Tiers); err != nil {
+		return nil, fmt.Errorf("decode tiers: %w", err)
+	}
 
 	return &p, nil
 }
 
 func (r *PromotionRepository) FindActive(ctx context.Context) ([]*pricing.Promotion, error) {
-	rows, err := r.db.QueryContext(ctx, `SELECT code FROM promotions WHERE is_active = true`)
+	rows, err := r.db.QueryContext(ctx, `SELECT code FROM promotions WHERE is_active = true ORDER BY priority ASC, code ASC`)
 	if err != nil {
 		return nil, err
 	}
@@ -127,6 +146,10 @@ func (r *PromotionRepository) Save(ctx context.Context, p *pricing.Promotion) er
 	if err != nil {
 		return err
 	}
+	tiers, err := toJSONB(p.Tiers)
+	if err != nil {
+		return err
+	}
 
 	var minAmt any
 	var minCur any
@@ -148,13 +171,15 @@ func (r *PromotionRepository) Save(ctx context.Context, p *pricing.Promotion) er
 			max_discount_amount, max_discount_currency,
 			valid_from, valid_to, is_active, usage_limit, usage_count,
 			applicable_product_ids, applicable_category_ids, excluded_product_ids,
-			created_at, updated_at
+			priority, tiers, buy_quantity, get_quantity, get_percent_off,
+			per_user_limit, exclusive, created_at, updated_at
 		) VALUES (
 			$1,$2,$3,$4,$5,$6,
 			$7,$8,$9,$10,
 			$11,$12,$13,$14,$15,
 			$16,$17,$18,
-			CURRENT_TIMESTAMP, CURRENT_TIMESTAMP
+			$19, $20, $21, $22, $23,
+			$24, $25, CURRENT_TIMESTAMP, CURRENT_TIMESTAMP
 		)
 		ON CONFLICT (id) DO UPDATE SET
 			code = EXCLUDED.code,
@@ -174,6 +199,13 @@ func (r *PromotionRepository) Save(ctx context.Context, p *pricing.Promotion) er
 			applicable_product_ids = EXCLUDED.applicable_product_ids,
 			applicable_category_ids = EXCLUDED.applicable_category_ids,
 			excluded_product_ids = EXCLUDED.excluded_product_ids,
+			priority = EXCLUDED.priority,
+			tiers = EXCLUDED.tiers,
+			buy_quantity = EXCLUDED.buy_quantity,
+			get_quantity = EXCLUDED.get_quantity,
+			get_percent_off = EXCLUDED.get_percent_off,
+			per_user_limit = EXCLUDED.per_user_limit,
+			exclusive = EXCLUDED.exclusive,
 			updated_at = CURRENT_TIMESTAMP
 	`,
 		p.ID,
@@ -194,6 +226,121 @@ func (r *PromotionRepository) Save(ctx context.Context, p *pricing.Promotion) er
 		applicableProducts,
 		applicableCategories,
 		excludedProducts,
+		p.Priority,
+		tiers,
+		p.BuyQuantity,
+		p.GetQuantity,
+		p.GetPercentOff,
+		p.PerUserLimit,
+		p.Exclusive,
 	)
 	return err
 }
+
+// IncrementUsage bumps usage_count in a single transaction that holds the
+// promotion's row lock, so two concurrent orders can't both take the last use
+// of a limited promotion, nor can one customer exceed its per_user_limit. The
+// redemption is recorded for userID in the same transaction.
+func (r *PromotionRepository) IncrementUsage(ctx context.Context, code, userID string) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	var usageLimit, usageCount, perUserLimit int
+	err = tx.QueryRowContext(ctx, `
+		SELECT usage_limit, usage_count, per_user_limit
+		FROM promotions
+		WHERE code = $1
+		FOR UPDATE
+	`, code).Scan(&usageLimit, &usageCount, &perUserLimit)
+	if errors.Is(err, sql.ErrNoRows) {
+		return pricing.ErrPromotionNotFound
+	}
+	if err != nil {
+		return err
+	}
+	if usageLimit > 0 && usageCount >= usageLimit {
+		return pricing.ErrPromotionUsageExceeded
+	}
+	if userID != "" && perUserLimit > 0 {
+		var used int
+		if err := tx.QueryRowContext(ctx, `
+			SELECT COUNT(*) FROM promotion_redemptions WHERE promotion_code = $1 AND user_id = $2
+		`, code, userID).Scan(&used); err != nil {
+			return err
+		}
+		if used >= perUserLimit {
+			return pricing.ErrPromotionAlreadyUsed
+		}
+	}
+
+	if _, err := tx.ExecContext(ctx, `
+		UPDATE promotions
+		SET usage_count = usage_count + 1, updated_at = CURRENT_TIMESTAMP
+		WHERE code = $1
+	`, code); err != nil {
+		return err
+	}
+
+	if userID != "" {
+		if _, err := tx.ExecContext(ctx, `
+			INSERT INTO promotion_redemptions (promotion_code, user_id) VALUES ($1, $2)
+		`, code, userID); err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+// DecrementUsage takes back one use of code and deletes userID's most recent
+// redemption of it, in one transaction.
+func (r *PromotionRepository) DecrementUsage(ctx context.Context, code, userID string) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	res, err := tx.ExecContext(ctx, `
+		UPDATE promotions
+		SET usage_count = GREATEST(usage_count - 1, 0), updated_at = CURRENT_TIMESTAMP
+		WHERE code = $1
+	`, code)
+	if err != nil {
+		return err
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return pricing.ErrPromotionNotFound
+	}
+
+	if userID != "" {
+		if _, err := tx.ExecContext(ctx, `
+			DELETE FROM promotion_redemptions
+			WHERE id = (
+				SELECT id FROM promotion_redemptions
+				WHERE promotion_code = $1 AND user_id = $2
+				ORDER BY id DESC
+				LIMIT 1
+			)
+		`, code, userID); err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+func (r *PromotionRepository) CountUsageByUser(ctx context.Context, code, userID string) (int, error) {
+	var count int
+	err := r.db.QueryRowContext(ctx, `
+		SELECT COUNT(*) FROM promotion_redemptions WHERE promotion_code = $1 AND user_id = $2
+	`, code, userID).Scan(&count)
+	return count, err
+}
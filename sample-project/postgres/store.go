@@ -8,6 +8,7 @@ import (
 	"github.com/devchuckcamp/gocommerce/catalog"
 	"github.com/devchuckcamp/gocommerce/orders"
 	"github.com/devchuckcamp/gocommerce/pricing"
+	"github.com/devchuckcamp/gocommerce/tax"
 )
 
 // Store bundles Postgres-backed repository implementations for the sample-project.
@@ -19,16 +20,19 @@ type Store struct {
 	Carts      *CartRepository
 	Orders     *OrderRepository
 	Promotions *PromotionRepository
+	Taxes      *TaxRepository
 }
 
-func NewStore(db *sql.DB) *Store {
+// NewStore creates the repositories over db; opts apply to each of them.
+func NewStore(db *sql.DB, opts ...Option) *Store {
 	return &Store{
 		DB:         db,
-		Products:   NewProductRepository(db),
-		Variants:   NewVariantRepository(db),
-		Carts:      NewCartRepository(db),
-		Orders:     NewOrderRepository(db),
+		Products:   NewProductRepository(db, opts...),
+		Variants:   NewVariantRepository(db, opts...),
+		Carts:      NewCartRepository(db, opts...),
+		Orders:     NewOrderRepository(db, opts...),
 		Promotions: NewPromotionRepository(db),
+		Taxes:      NewTaxRepository(db),
 	}
 }
 
@@ -40,10 +44,11 @@ func (s *Store) Close() error {
 }
 
 // Convenience accessors (helps satisfy sample-project wiring).
-func (s *Store) CartRepo() cart.Repository { return s.Carts }
-func (s *Store) ProductRepo() catalog.ProductRepository { return s.Products }
-func (s *Store) OrderRepo() orders.Repository { return s.Orders }
+func (s *Store) CartRepo() cart.Repository                  { return s.Carts }
+func (s *Store) ProductRepo() catalog.ProductRepository     { return s.Products }
+func (s *Store) OrderRepo() orders.Repository               { return s.Orders }
 func (s *Store) PromotionRepo() pricing.PromotionRepository { return s.Promotions }
+func (s *Store) TaxRepo() tax.Repository                    { return s.Taxes }
 
 // ProductStore-like helpers for the HTTP API.
 func (s *Store) ListProducts(ctx context.Context) ([]*catalog.Product, error) {
@@ -0,0 +1,168 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"github.com/devchuckcamp/gocommerce/tax"
+)
+
+type TaxRepository struct {
+	db *sql.DB
+}
+
+func NewTaxRepository(db *sql.DB) *TaxRepository {
+	return &TaxRepository{db: db}
+}
+
+const taxRateColumns = `id, name, rate, country, state, city, postal_code, tax_type, is_compound, priority, tax_code`
+
+// FindRatesByAddress returns every rate that applies to address, best match first:
+// postal-code rates precede city rates, which precede state and then country rates.
+// Rates at the same level are ordered by priority.
+func (r *TaxRepository) FindRatesByAddress(ctx context.Context, address tax.Address) ([]*tax.TaxRate, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT `+taxRateColumns+`
+		FROM tax_rates
+		WHERE (country = '' OR country = $1)
+			AND (state = '' OR state = $2)
+			AND (city = '' OR city = $3)
+			AND (postal_code = '' OR postal_code = $4)
+		ORDER BY
+			CASE
+				WHEN postal_code <> '' THEN 4
+				WHEN city <> '' THEN 3
+				WHEN state <> '' THEN 2
+				WHEN country <> '' THEN 1
+				ELSE 0
+			END DESC,
+			priority ASC,
+			id ASC
+	`, address.Country, address.State, address.City, address.PostalCode)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	rates := make([]*tax.TaxRate, 0)
+	for rows.Next() {
+		rate, err := scanTaxRate(rows)
+		if err != nil {
+			return nil, err
+		}
+		rates = append(rates, rate)
+	}
+	return rates, rows.Err()
+}
+
+// FindRatesByPostalCode returns the rates in country scoped to a postal code,
+// prefix, or range that matches postalCode, ordered by priority.
+func (r *TaxRepository) FindRatesByPostalCode(ctx context.Context, country, postalCode string) ([]*tax.TaxRate, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT `+taxRateColumns+`
+		FROM tax_rates
+		WHERE (country = '' OR country = $1)
+			AND postal_code <> ''
+		ORDER BY priority ASC, id ASC
+	`, country)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	rates := make([]*tax.TaxRate, 0)
+	for rows.Next() {
+		rate, err := scanTaxRate(rows)
+		if err != nil {
+			return nil, err
+		}
+		if tax.MatchPostalCode(rate.PostalCode, postalCode) {
+			rates = append(rates, rate)
+		}
+	}
+	return rates, rows.Err()
+}
+
+func (r *TaxRepository) FindRateByID(ctx context.Context, id string) (*tax.TaxRate, error) {
+	row := r.db.QueryRowContext(ctx, `SELECT `+taxRateColumns+` FROM tax_rates WHERE id = $1`, id)
+	rate, err := scanTaxRate(row)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, errors.New("tax rate not found")
+		}
+		return nil, err
+	}
+	return rate, nil
+}
+
+func (r *TaxRepository) SaveRate(ctx context.Context, rate *tax.TaxRate) error {
+	if rate == nil {
+		return errors.New("tax rate is nil")
+	}
+
+	_, err := r.db.ExecContext(ctx, `
+		INSERT INTO tax_rates (
+			id, name, rate, country, state, city, postal_code, tax_type, is_compound, priority,
+			tax_code, created_at, updated_at
+		) VALUES (
+			$1,$2,$3,$4,$5,$6,$7,$8,$9,$10,$11, CURRENT_TIMESTAMP, CURRENT_TIMESTAMP
+		)
+		ON CONFLICT (id) DO UPDATE SET
+			name = EXCLUDED.name,
+			rate = EXCLUDED.rate,
+			country = EXCLUDED.country,
+			state = EXCLUDED.state,
+			city = EXCLUDED.city,
+			postal_code = EXCLUDED.postal_code,
+			tax_type = EXCLUDED.tax_type,
+			is_compound = EXCLUDED.is_compound,
+			priority = EXCLUDED.priority,
+			tax_code = EXCLUDED.tax_code,
+			updated_at = CURRENT_TIMESTAMP
+	`,
+		rate.ID,
+		rate.Name,
+		rate.Rate,
+		rate.Country,
+		rate.State,
+		rate.City,
+		rate.PostalCode,
+		string(rate.TaxType),
+		rate.IsCompound,
+		rate.Priority,
+		rate.TaxCode,
+	)
+	return err
+}
+
+func (r *TaxRepository) DeleteRate(ctx context.Context, id string) error {
+	_, err := r.db.ExecContext(ctx, `DELETE FROM tax_rates WHERE id = $1`, id)
+	return err
+}
+
+type rowScanner interface {
+	Scan(dest ...any) error
+}
+
+func scanTaxRate(row rowScanner) (*tax.TaxRate, error) {
+	var rate tax.TaxRate
+	var taxType string
+	if err := row.Scan(
+		&rate.ID,
+		&rate.Name,
+		&rate.Rate,
+		&rate.Country,
+		&rate.State,
+		&rate.City,
+		&rate.PostalCode,
+		&taxType,
+		&rate.IsCompound,
+		&rate.Priority,
+		&rate.TaxCode,
+	); err != nil {
+		return nil, err
+	}
+	rate.TaxType = tax.TaxType(taxType)
+	return &rate, nil
+}
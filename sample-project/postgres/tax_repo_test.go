@@ -0,0 +1,51 @@
+package postgres
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/devchuckcamp/gocommerce/tax"
+)
+
+// fakeRow is a rowScanner returning fixed column values.
+type fakeRow struct {
+	values []any
+	err    error
+}
+
+func (r fakeRow) Scan(dest ...any) error {
+	if r.err != nil {
+		return r.err
+	}
+	for i, d := range dest {
+		switch d := d.(type) {
+		case *string:
+			*d = r.values[i].(string)
+		case *float64:
+			*d = r.values[i].(float64)
+		case *bool:
+			*d = r.values[i].(bool)
+		case *int:
+			*d = r.values[i].(int)
+		}
+	}
+	return nil
+}
+
+func TestScanTaxRate(t *testing.T) {
+	rate, err := scanTaxRate(fakeRow{values: []any{
+		"ca-qst", "QST", 0.09975, "CA", "QC", "", "", "gst", true, 2, "",
+	}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := tax.TaxRate{ID: "ca-qst", Name: "QST", Rate: 0.09975, Country: "CA", State: "QC", TaxType: tax.TaxTypeGST, IsCompound: true, Priority: 2}
+	if *rate != want {
+		t.Errorf("rate = %+v, want %+v", *rate, want)
+	}
+
+	scanErr := errors.New("bad row")
+	if _, err := scanTaxRate(fakeRow{err: scanErr}); !errors.Is(err, scanErr) {
+		t.Errorf("error = %v, want %v", err, scanErr)
+	}
+}
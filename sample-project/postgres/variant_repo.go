@@ -4,23 +4,25 @@ import (
 	"context"
 	"database/sql"
 	"errors"
+	"fmt"
 
 	"github.com/devchuckcamp/gocommerce/catalog"
 )
 
 type VariantRepository struct {
-	db *sql.DB
+	db   *sql.DB
+	opts options
 }
 
-func NewVariantRepository(db *sql.DB) *VariantRepository {
-	return &VariantRepository{db: db}
+func NewVariantRepository(db *sql.DB, opts ...Option) *VariantRepository {
+	return &VariantRepository{db: db, opts: newOptions(opts)}
 }
 
 func (r *VariantRepository) FindByID(ctx context.Context, id string) (*catalog.Variant, error) {
 	row := r.db.QueryRowContext(ctx, `
 		SELECT id, product_id, sku, name, price_amount, price_currency,
 			COALESCE(attributes, '{}'::jsonb), COALESCE(images, '[]'::jsonb),
-			is_available, created_at, updated_at
+			is_available, weight_grams, length_cm, width_cm, height_cm, created_at, updated_at
 		FROM variants
 		WHERE id = $1
 	`, id)
@@ -39,6 +41,10 @@ func (r *VariantRepository) FindByID(ctx context.Context, id string) (*catalog.V
 		&attrsRaw,
 		&imagesRaw,
 		&v.IsAvailable,
+		&v.WeightGrams,
+		&v.LengthCm,
+		&v.WidthCm,
+		&v.HeightCm,
 		&v.CreatedAt,
 		&v.UpdatedAt,
 	); err != nil {
@@ -53,8 +59,12 @@ func (r *VariantRepository) FindByID(ctx context.Context, id string) (*catalog.V
 		return nil, err
 	}
 	v.Price = m
-	_ = fromJSONB(attrsRaw, &v.Attributes)
-	_ = fromJSONB(imagesRaw, &v.Images)
+	if err := attributesFromJSONB(attrsRaw, &v.Attributes, r.opts.lenientAttributes); err != nil {
+		return nil, err
+	}
+	if err := fromJSONB(imagesRaw, &v.Images); err != nil {
+		return nil, fmt.Errorf("decode images: %w", err)
+	}
 	return &v, nil
 }
 
@@ -116,9 +126,10 @@ func (r *VariantRepository) Save(ctx context.Context, v *catalog.Variant) error
 	_, err = r.db.ExecContext(ctx, `
 		INSERT INTO variants (
 			id, product_id, sku, name, price_amount, price_currency,
-			attributes, images, is_available, created_at, updated_at
+			attributes, images, is_available, weight_grams, length_cm, width_cm, height_cm,
+			created_at, updated_at
 		) VALUES (
-			$1,$2,$3,$4,$5,$6,$7,$8,$9, COALESCE($10, CURRENT_TIMESTAMP), CURRENT_TIMESTAMP
+			$1,$2,$3,$4,$5,$6,$7,$8,$9,$11,$12,$13,$14, COALESCE($10, CURRENT_TIMESTAMP), CURRENT_TIMESTAMP
 		)
 		ON CONFLICT (id) DO UPDATE SET
 			product_id = EXCLUDED.product_id,
@@ -129,6 +140,10 @@ func (r *VariantRepository) Save(ctx context.Context, v *catalog.Variant) error
 			attributes = EXCLUDED.attributes,
 			images = EXCLUDED.images,
 			is_available = EXCLUDED.is_available,
+			weight_grams = EXCLUDED.weight_grams,
+			length_cm = EXCLUDED.length_cm,
+			width_cm = EXCLUDED.width_cm,
+			height_cm = EXCLUDED.height_cm,
 			updated_at = CURRENT_TIMESTAMP
 	`,
 		v.ID,
@@ -141,6 +156,10 @@ func (r *VariantRepository) Save(ctx context.Context, v *catalog.Variant) error
 		images,
 		v.IsAvailable,
 		nullTime(v.CreatedAt),
+		v.WeightGrams,
+		v.LengthCm,
+		v.WidthCm,
+		v.HeightCm,
 	)
 	return err
 }
@@ -0,0 +1,71 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"sort"
+
+	"github.com/devchuckcamp/gocommerce/money"
+	"github.com/devchuckcamp/gocommerce/shipping"
+)
+
+// FlatRateShippingCalculator implements a basic shipping calculator with fixed-price methods
+type FlatRateShippingCalculator struct {
+	rates   map[string]shipping.ShippingRate
+	methods map[string]shipping.ShippingMethod // Eligibility rules per method ID
+}
+
+func NewFlatRateShippingCalculator() *FlatRateShippingCalculator {
+	standard, _ := money.New(599, "USD")
+	express, _ := money.New(1499, "USD")
+	return &FlatRateShippingCalculator{
+		rates: map[string]shipping.ShippingRate{
+			"standard": {
+				MethodID:         "standard",
+				MethodName:       "Standard Shipping",
+				Cost:             standard,
+				EstimatedDaysMin: 3,
+				EstimatedDaysMax: 5,
+				ServiceLevel:     "ground",
+			},
+			"express": {
+				MethodID:         "express",
+				MethodName:       "Express Shipping",
+				Cost:             express,
+				EstimatedDaysMin: 1,
+				EstimatedDaysMax: 2,
+				ServiceLevel:     "express",
+			},
+		},
+		methods: map[string]shipping.ShippingMethod{
+			"standard": {ID: "standard", MaxWeightGrams: 30000},
+			"express":  {ID: "express", SupportsColdChain: true, MaxWeightGrams: 10000},
+		},
+	}
+}
+
+func (c *FlatRateShippingCalculator) GetRate(ctx context.Context, req shipping.RateRequest) (*shipping.ShippingRate, error) {
+	rate, ok := c.rates[req.ShippingMethodID]
+	if !ok {
+		return nil, errors.New("unknown shipping method")
+	}
+	if method := c.methods[req.ShippingMethodID]; !method.CanShip(req.Items) {
+		return nil, errors.New("shipping method cannot carry these items")
+	}
+	return &rate, nil
+}
+
+func (c *FlatRateShippingCalculator) GetAvailableRates(ctx context.Context, req shipping.RateRequest) ([]*shipping.ShippingRate, error) {
+	rates := make([]*shipping.ShippingRate, 0, len(c.rates))
+	for id, rate := range c.rates {
+		if method := c.methods[id]; !method.CanShip(req.Items) {
+			continue
+		}
+		rate := rate
+		rates = append(rates, &rate)
+	}
+	sort.Slice(rates, func(i, j int) bool {
+		return rates[i].MethodID < rates[j].MethodID
+	})
+	return rates, nil
+}
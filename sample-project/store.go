@@ -3,11 +3,14 @@ package main
 import (
 	"context"
 	"errors"
+	"sort"
+	"strings"
 	"sync"
 	"time"
 
 	"github.com/devchuckcamp/gocommerce/cart"
 	"github.com/devchuckcamp/gocommerce/catalog"
+	"github.com/devchuckcamp/gocommerce/inventory"
 	"github.com/devchuckcamp/gocommerce/money"
 	"github.com/devchuckcamp/gocommerce/orders"
 	"github.com/devchuckcamp/gocommerce/pricing"
@@ -15,12 +18,13 @@ import (
 
 // MemoryStore implements all repository interfaces using in-memory storage
 type MemoryStore struct {
-	products   map[string]*catalog.Product
-	variants   map[string]*catalog.Variant
-	carts      map[string]*cart.Cart
-	orders     map[string]*orders.Order
-	promotions map[string]*pricing.Promotion
-	mu         sync.RWMutex
+	products      map[string]*catalog.Product
+	variants      map[string]*catalog.Variant
+	carts         map[string]*cart.Cart
+	orders        map[string]*orders.Order
+	promotions    map[string]*pricing.Promotion
+	promotionUses map[string]map[string]int // code -> user ID -> uses
+	mu            sync.RWMutex
 	
 	// Separate repo instances to satisfy different interfaces
 	cartRepo      cartRepository
@@ -38,11 +42,12 @@ type ProductStore interface {
 
 func NewMemoryStore() *MemoryStore {
 	s := &MemoryStore{
-		products:   make(map[string]*catalog.Product),
-		variants:   make(map[string]*catalog.Variant),
-		carts:      make(map[string]*cart.Cart),
-		orders:     make(map[string]*orders.Order),
-		promotions: make(map[string]*pricing.Promotion),
+		products:      make(map[string]*catalog.Product),
+		variants:      make(map[string]*catalog.Variant),
+		carts:         make(map[string]*cart.Cart),
+		orders:        make(map[string]*orders.Order),
+		promotions:    make(map[string]*pricing.Promotion),
+		promotionUses: make(map[string]map[string]int),
 	}
 	s.cartRepo = cartRepository{store: s}
 	s.variantRepo = variantRepository{store: s}
@@ -94,8 +99,109 @@ func (s *MemoryStore) FindByBrand(ctx context.Context, brandID string, filter ca
 	return nil, errors.New("not implemented")
 }
 
-func (s *MemoryStore) Search(ctx context.Context, query string, filter catalog.ProductFilter) ([]*catalog.Product, error) {
-	return nil, errors.New("not implemented")
+func (s *MemoryStore) Search(ctx context.Context, query string, filter catalog.ProductFilter) (*catalog.SearchResult, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	needle := strings.ToLower(query)
+	hits := make([]catalog.SearchHit, 0)
+	for _, p := range s.products {
+		if !matchesProductFilter(p, filter) {
+			continue
+		}
+		score := 0.0
+		if strings.EqualFold(p.SKU, query) {
+			score += 1
+		}
+		if strings.Contains(strings.ToLower(p.Name), needle) {
+			score += 0.5
+		}
+		if strings.Contains(strings.ToLower(p.Description), needle) {
+			score += 0.1
+		}
+		if score > 0 || strings.Contains(strings.ToLower(p.SKU), needle) {
+			hits = append(hits, catalog.SearchHit{Product: p, Score: score})
+		}
+	}
+	sort.Slice(hits, func(i, j int) bool {
+		if hits[i].Score != hits[j].Score {
+			return hits[i].Score > hits[j].Score
+		}
+		return hits[i].Product.ID < hits[j].Product.ID
+	})
+
+	// A negative offset is treated as no offset.
+	offset := filter.Offset
+	if offset < 0 {
+		offset = 0
+	}
+	result := &catalog.SearchResult{TotalCount: len(hits), Limit: filter.Limit, Offset: offset}
+	if offset >= len(hits) {
+		hits = hits[:0]
+	} else {
+		hits = hits[offset:]
+	}
+	if filter.Limit > 0 && filter.Limit < len(hits) {
+		hits = hits[:filter.Limit]
+	}
+	result.Hits = hits
+	return result, nil
+}
+
+// matchesProductFilter reports whether p satisfies the filter's conditions.
+func matchesProductFilter(p *catalog.Product, filter catalog.ProductFilter) bool {
+	if filter.Status != nil && p.Status != *filter.Status {
+		return false
+	}
+	if filter.MinPrice != nil && p.BasePrice.Amount < *filter.MinPrice {
+		return false
+	}
+	if filter.MaxPrice != nil && p.BasePrice.Amount > *filter.MaxPrice {
+		return false
+	}
+	if len(filter.BrandIDs) > 0 && !containsString(filter.BrandIDs, p.BrandID) {
+		return false
+	}
+	if len(filter.CategoryIDs) > 0 && !containsString(filter.CategoryIDs, p.CategoryID) {
+		return false
+	}
+	for k, v := range filter.Attributes {
+		if p.Attributes[k] != v {
+			return false
+		}
+	}
+	return true
+}
+
+func containsString(values []string, s string) bool {
+	for _, v := range values {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
+
+func (s *MemoryStore) FindUpdatedSince(ctx context.Context, since time.Time, limit int) ([]*catalog.Product, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	products := make([]*catalog.Product, 0)
+	for _, p := range s.products {
+		if p.UpdatedAt.After(since) {
+			products = append(products, p)
+		}
+	}
+	sort.Slice(products, func(i, j int) bool {
+		if products[i].UpdatedAt.Equal(products[j].UpdatedAt) {
+			return products[i].ID < products[j].ID
+		}
+		return products[i].UpdatedAt.Before(products[j].UpdatedAt)
+	})
+	if limit > 0 && len(products) > limit {
+		products = products[:limit]
+	}
+	return products, nil
 }
 
 func (s *MemoryStore) Save(ctx context.Context, product *catalog.Product) error {
@@ -192,6 +298,14 @@ func (r *cartRepository) FindBySessionID(ctx context.Context, sessionID string)
 func (r *cartRepository) Save(ctx context.Context, c *cart.Cart) error {
 	r.store.mu.Lock()
 	defer r.store.mu.Unlock()
+
+	if c.UserID != "" {
+		for id, existing := range r.store.carts {
+			if id != c.ID && existing.UserID == c.UserID {
+				return cart.ErrDuplicateCart
+			}
+		}
+	}
 	
 	r.store.carts[c.ID] = c
 	return nil
@@ -205,6 +319,30 @@ func (r *cartRepository) Delete(ctx context.Context, id string) error {
 	return nil
 }
 
+func (r *cartRepository) FindExpired(ctx context.Context, before time.Time, limit int) ([]string, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	var expired []*cart.Cart
+	for _, c := range r.store.carts {
+		if c.ExpiresAt != nil && c.ExpiresAt.Before(before) {
+			expired = append(expired, c)
+		}
+	}
+	sort.Slice(expired, func(i, j int) bool {
+		return expired[i].ExpiresAt.Before(*expired[j].ExpiresAt)
+	})
+
+	ids := make([]string, 0, len(expired))
+	for _, c := range expired {
+		if limit > 0 && len(ids) == limit {
+			break
+		}
+		ids = append(ids, c.ID)
+	}
+	return ids, nil
+}
+
 // Order Repository implementation
 
 func (r *orderRepository) FindByID(ctx context.Context, id string) (*orders.Order, error) {
@@ -226,6 +364,86 @@ func (r *orderRepository) FindByUserID(ctx context.Context, userID string, filte
 	return nil, errors.New("not implemented")
 }
 
+func (r *orderRepository) FindAll(ctx context.Context, filter orders.OrderFilter) ([]*orders.Order, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	result := make([]*orders.Order, 0)
+	for _, order := range r.store.orders {
+		if matchesOrderFilter(order, filter) {
+			result = append(result, order)
+		}
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].CreatedAt.After(result[j].CreatedAt)
+	})
+
+	if filter.Offset > 0 {
+		if filter.Offset >= len(result) {
+			return []*orders.Order{}, nil
+		}
+		result = result[filter.Offset:]
+	}
+	if filter.Limit > 0 && filter.Limit < len(result) {
+		result = result[:filter.Limit]
+	}
+	return result, nil
+}
+
+func (r *orderRepository) SumTotals(ctx context.Context, filter orders.OrderFilter) (money.Money, error) {
+	sums, err := r.SumTotalsByCurrency(ctx, filter)
+	if err != nil {
+		return money.Money{}, err
+	}
+	if len(sums) > 1 {
+		return money.Money{}, money.ErrCurrencyMismatch
+	}
+	for _, sum := range sums {
+		return sum, nil
+	}
+	return money.Money{}, nil
+}
+
+func (r *orderRepository) SumTotalsByCurrency(ctx context.Context, filter orders.OrderFilter) (map[string]money.Money, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	sums := make(map[string]money.Money)
+	for _, order := range r.store.orders {
+		if !matchesOrderFilter(order, filter) {
+			continue
+		}
+		currency := order.Total.Currency
+		sum, ok := sums[currency]
+		if !ok {
+			sum = money.Zero(currency)
+		}
+		sums[currency], _ = sum.Add(order.Total)
+	}
+	return sums, nil
+}
+
+func (r *orderRepository) CountByCancellationReason(ctx context.Context, filter orders.OrderFilter) (map[orders.CancellationReason]int, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	canceled := orders.OrderStatusCanceled
+	filter = orders.OrderFilter{Status: &canceled, DateFrom: filter.DateFrom, DateTo: filter.DateTo}
+
+	counts := make(map[orders.CancellationReason]int)
+	for _, order := range r.store.orders {
+		if !matchesOrderFilter(order, filter) {
+			continue
+		}
+		reason := order.CancellationReason
+		if reason == "" {
+			reason = orders.CancellationReasonOther
+		}
+		counts[reason]++
+	}
+	return counts, nil
+}
+
 func (r *orderRepository) Save(ctx context.Context, order *orders.Order) error {
 	r.store.mu.Lock()
 	defer r.store.mu.Unlock()
@@ -242,6 +460,19 @@ func (r *orderRepository) Delete(ctx context.Context, id string) error {
 	return nil
 }
 
+func matchesOrderFilter(order *orders.Order, filter orders.OrderFilter) bool {
+	if filter.Status != nil && order.Status != *filter.Status {
+		return false
+	}
+	if filter.DateFrom != nil && order.CreatedAt.Before(*filter.DateFrom) {
+		return false
+	}
+	if filter.DateTo != nil && order.CreatedAt.After(*filter.DateTo) {
+		return false
+	}
+	return true
+}
+
 // Promotion Repository implementation
 
 func (r *promotionRepository) FindByCode(ctx context.Context, code string) (*pricing.Promotion, error) {
@@ -253,7 +484,7 @@ func (r *promotionRepository) FindByCode(ctx context.Context, code string) (*pri
 			return p, nil
 		}
 	}
-	return nil, errors.New("promotion not found")
+	return nil, pricing.ErrPromotionNotFound
 }
 
 func (r *promotionRepository) FindActive(ctx context.Context) ([]*pricing.Promotion, error) {
@@ -266,6 +497,12 @@ func (r *promotionRepository) FindActive(ctx context.Context) ([]*pricing.Promot
 			promotions = append(promotions, p)
 		}
 	}
+	sort.Slice(promotions, func(i, j int) bool {
+		if promotions[i].Priority != promotions[j].Priority {
+			return promotions[i].Priority < promotions[j].Priority
+		}
+		return promotions[i].Code < promotions[j].Code
+	})
 	return promotions, nil
 }
 
@@ -277,6 +514,56 @@ func (r *promotionRepository) Save(ctx context.Context, promotion *pricing.Promo
 	return nil
 }
 
+func (r *promotionRepository) IncrementUsage(ctx context.Context, code, userID string) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	for _, p := range r.store.promotions {
+		if p.Code == code {
+			if p.UsageLimit > 0 && p.UsageCount >= p.UsageLimit {
+				return pricing.ErrPromotionUsageExceeded
+			}
+			if userID != "" && p.PerUserLimit > 0 && r.store.promotionUses[code][userID] >= p.PerUserLimit {
+				return pricing.ErrPromotionAlreadyUsed
+			}
+			p.UsageCount++
+			if userID != "" {
+				if r.store.promotionUses[code] == nil {
+					r.store.promotionUses[code] = make(map[string]int)
+				}
+				r.store.promotionUses[code][userID]++
+			}
+			return nil
+		}
+	}
+	return pricing.ErrPromotionNotFound
+}
+
+func (r *promotionRepository) DecrementUsage(ctx context.Context, code, userID string) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	for _, p := range r.store.promotions {
+		if p.Code == code {
+			if p.UsageCount > 0 {
+				p.UsageCount--
+			}
+			if userID != "" && r.store.promotionUses[code][userID] > 0 {
+				r.store.promotionUses[code][userID]--
+			}
+			return nil
+		}
+	}
+	return pricing.ErrPromotionNotFound
+}
+
+func (r *promotionRepository) CountUsageByUser(ctx context.Context, code, userID string) (int, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	return r.store.promotionUses[code][userID], nil
+}
+
 // Seed sample products
 func seedProducts(store *MemoryStore) {
 	products := []*catalog.Product{
@@ -351,6 +638,24 @@ func seedProducts(store *MemoryStore) {
 	}
 }
 
+// Seed stock for the sample products
+func seedInventory(repo *inventory.MemoryRepository) {
+	stock := map[string]int{
+		"TSHIRT-BLU-M":    100,
+		"JEANS-BLK-32":    50,
+		"SNEAKERS-WHT-10": 25,
+		"HOODIE-GRY-L":    10,
+	}
+
+	for sku, quantity := range stock {
+		repo.UpdateStockLevel(context.Background(), &inventory.StockLevel{
+			SKU:               sku,
+			QuantityOnHand:    quantity,
+			QuantityAvailable: quantity,
+		})
+	}
+}
+
 func mustMoney(amount float64, currency string) money.Money {
 	m, _ := money.NewFromFloat(amount, currency)
 	return m
@@ -0,0 +1,204 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/devchuckcamp/gocommerce/catalog"
+	"github.com/devchuckcamp/gocommerce/money"
+	"github.com/devchuckcamp/gocommerce/orders"
+	"github.com/devchuckcamp/gocommerce/pricing"
+)
+
+func TestMemoryStoreSearchPaging(t *testing.T) {
+	ctx := context.Background()
+	s := NewMemoryStore()
+	for _, id := range []string{"p1", "p2", "p3"} {
+		if err := s.Save(ctx, &catalog.Product{ID: id, SKU: "MUG-" + id, Name: "Mug " + id}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tests := []struct {
+		name       string
+		filter     catalog.ProductFilter
+		wantIDs    []string
+		wantOffset int
+	}{
+		{"first page", catalog.ProductFilter{Limit: 2}, []string{"p1", "p2"}, 0},
+		{"second page", catalog.ProductFilter{Limit: 2, Offset: 2}, []string{"p3"}, 2},
+		{"past the end", catalog.ProductFilter{Offset: 5}, nil, 5},
+		{"negative offset", catalog.ProductFilter{Limit: 2, Offset: -1}, []string{"p1", "p2"}, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := s.Search(ctx, "mug", tt.filter)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if result.TotalCount != 3 {
+				t.Errorf("TotalCount = %d, want 3", result.TotalCount)
+			}
+			if result.Offset != tt.wantOffset {
+				t.Errorf("Offset = %d, want %d", result.Offset, tt.wantOffset)
+			}
+			if len(result.Hits) != len(tt.wantIDs) {
+				t.Fatalf("got %d hits, want %d", len(result.Hits), len(tt.wantIDs))
+			}
+			for i, hit := range result.Hits {
+				if hit.Product.ID != tt.wantIDs[i] {
+					t.Errorf("hit %d = %s, want %s", i, hit.Product.ID, tt.wantIDs[i])
+				}
+			}
+		})
+	}
+}
+
+func TestOrderRepositorySumTotals(t *testing.T) {
+	ctx := context.Background()
+	s := NewMemoryStore()
+	for _, o := range []*orders.Order{
+		{ID: "o1", Status: orders.OrderStatusPaid, Total: money.Money{Amount: 1000, Currency: "USD"}},
+		{ID: "o2", Status: orders.OrderStatusPaid, Total: money.Money{Amount: 2550, Currency: "USD"}},
+		{ID: "o3", Status: orders.OrderStatusPending, Total: money.Money{Amount: 700, Currency: "USD"}},
+		{ID: "o4", Status: orders.OrderStatusPending, Total: money.Money{Amount: 900, Currency: "EUR"}},
+	} {
+		if err := s.orderRepo.Save(ctx, o); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	paid := orders.OrderStatusPaid
+	sum, err := s.orderRepo.SumTotals(ctx, orders.OrderFilter{Status: &paid})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if sum.Amount != 3550 || sum.Currency != "USD" {
+		t.Errorf("paid total = %s, want USD 35.50", sum)
+	}
+
+	if _, err := s.orderRepo.SumTotals(ctx, orders.OrderFilter{}); !errors.Is(err, money.ErrCurrencyMismatch) {
+		t.Errorf("mixed currencies: error = %v, want %v", err, money.ErrCurrencyMismatch)
+	}
+	sums, err := s.orderRepo.SumTotalsByCurrency(ctx, orders.OrderFilter{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if sums["USD"].Amount != 4250 || sums["EUR"].Amount != 900 || len(sums) != 2 {
+		t.Errorf("sums = %v, want USD 42.50 and EUR 9.00", sums)
+	}
+
+	refunded := orders.OrderStatusRefunded
+	empty, err := s.orderRepo.SumTotals(ctx, orders.OrderFilter{Status: &refunded})
+	if err != nil || !empty.IsZero() || empty.Currency != "" {
+		t.Errorf("no matches = %+v, %v; want zero Money with no currency", empty, err)
+	}
+}
+
+func TestMemoryStoreFindUpdatedSince(t *testing.T) {
+	ctx := context.Background()
+	s := NewMemoryStore()
+	base := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	for _, p := range []*catalog.Product{
+		{ID: "old", UpdatedAt: base},
+		{ID: "p3", UpdatedAt: base.Add(3 * time.Hour)},
+		{ID: "p2", UpdatedAt: base.Add(time.Hour)},
+		{ID: "p1", UpdatedAt: base.Add(time.Hour)},
+	} {
+		if err := s.Save(ctx, p); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tests := []struct {
+		name  string
+		limit int
+		want  []string
+	}{
+		{"no limit", 0, []string{"p1", "p2", "p3"}},
+		{"limited", 2, []string{"p1", "p2"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			products, err := s.FindUpdatedSince(ctx, base, tt.limit)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(products) != len(tt.want) {
+				t.Fatalf("got %d products, want %d", len(products), len(tt.want))
+			}
+			for i, p := range products {
+				if p.ID != tt.want[i] {
+					t.Errorf("product %d = %s, want %s", i, p.ID, tt.want[i])
+				}
+			}
+		})
+	}
+}
+
+func TestOrderRepositoryCountByCancellationReason(t *testing.T) {
+	ctx := context.Background()
+	s := NewMemoryStore()
+	for _, o := range []*orders.Order{
+		{ID: "o1", Status: orders.OrderStatusCanceled, CancellationReason: orders.CancellationReasonFraud},
+		{ID: "o2", Status: orders.OrderStatusCanceled, CancellationReason: orders.CancellationReasonFraud},
+		{ID: "o3", Status: orders.OrderStatusCanceled, CancellationReason: orders.CancellationReasonOutOfStock},
+		{ID: "o4", Status: orders.OrderStatusPaid},
+	} {
+		if err := s.orderRepo.Save(ctx, o); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	paid := orders.OrderStatusPaid
+	counts, err := s.orderRepo.CountByCancellationReason(ctx, orders.OrderFilter{Status: &paid})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(counts) != 2 || counts[orders.CancellationReasonFraud] != 2 || counts[orders.CancellationReasonOutOfStock] != 1 {
+		t.Errorf("counts = %v, want fraud 2 and out_of_stock 1", counts)
+	}
+}
+
+func TestMemoryStoreSearchRanksByRelevance(t *testing.T) {
+	ctx := context.Background()
+	s := NewMemoryStore()
+	for _, p := range []*catalog.Product{
+		{ID: "desc", SKU: "BOTTLE-1", Name: "Water bottle", Description: "Fits any mug holder", BrandID: "acme"},
+		{ID: "name", SKU: "CUP-1", Name: "Travel mug", BrandID: "acme"},
+		{ID: "sku", SKU: "MUG", Name: "Classic mug", BrandID: "acme"},
+		{ID: "other-brand", SKU: "MUG-2", Name: "Enamel mug", BrandID: "globex"},
+		{ID: "miss", SKU: "PLATE-1", Name: "Dinner plate", BrandID: "acme"},
+	} {
+		if err := s.Save(ctx, p); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	result, err := s.Search(ctx, "mug", catalog.ProductFilter{BrandIDs: []string{"acme"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{"sku", "name", "desc"}
+	if result.TotalCount != len(want) || len(result.Hits) != len(want) {
+		t.Fatalf("got %d hits (TotalCount %d), want %d", len(result.Hits), result.TotalCount, len(want))
+	}
+	for i, hit := range result.Hits {
+		if hit.Product.ID != want[i] {
+			t.Errorf("hit %d = %s, want %s", i, hit.Product.ID, want[i])
+		}
+	}
+}
+
+func TestPromotionRepositoryNotFound(t *testing.T) {
+	ctx := context.Background()
+	s := NewMemoryStore()
+	if _, err := s.promotionRepo.FindByCode(ctx, "NO-SUCH-CODE"); !errors.Is(err, pricing.ErrPromotionNotFound) {
+		t.Errorf("FindByCode: error = %v, want %v", err, pricing.ErrPromotionNotFound)
+	}
+	if err := s.promotionRepo.IncrementUsage(ctx, "NO-SUCH-CODE", "user-1"); !errors.Is(err, pricing.ErrPromotionNotFound) {
+		t.Errorf("IncrementUsage: error = %v, want %v", err, pricing.ErrPromotionNotFound)
+	}
+}
@@ -23,26 +23,14 @@ func (c *SimpleTaxCalculator) Calculate(ctx context.Context, req tax.Calculation
 		currency = req.LineItems[0].Amount.Currency
 	}
 	
-	subtotal := money.Zero(currency)
-	for _, item := range req.LineItems {
-		if item.IsTaxable {
-			itemTotal := item.Amount.MultiplyInt(item.Quantity)
-			subtotal, _ = subtotal.Add(itemTotal)
-		}
-	}
-	
-	// Add shipping to taxable amount
-	taxableAmount, _ := subtotal.Add(req.ShippingCost)
-	
-	// Calculate tax
-	taxAmount := taxableAmount.Multiply(c.defaultRate)
-	
-	// Create line item taxes
+	// Tax each line and the shipping separately and report their sum, so the
+	// total always agrees with the line amounts.
+	taxAmount := money.Zero(currency)
 	lineItemTaxes := make([]tax.LineItemTax, len(req.LineItems))
 	for i, item := range req.LineItems {
 		if item.IsTaxable {
-			itemSubtotal := item.Amount.MultiplyInt(item.Quantity)
-			itemTax := itemSubtotal.Multiply(c.defaultRate)
+			itemTax := c.taxOn(item.Amount, req)
+			taxAmount, _ = taxAmount.Add(itemTax)
 			lineItemTaxes[i] = tax.LineItemTax{
 				LineItemID: item.ID,
 				TaxAmount:  itemTax,
@@ -64,6 +52,9 @@ func (c *SimpleTaxCalculator) Calculate(ctx context.Context, req tax.Calculation
 		}
 	}
 	
+	shippingTax := c.taxOn(req.ShippingCost, req)
+	taxAmount, _ = taxAmount.Add(shippingTax)
+
 	return &tax.CalculationResult{
 		TotalTax: taxAmount,
 		TaxRates: []tax.AppliedTaxRate{
@@ -76,10 +67,21 @@ func (c *SimpleTaxCalculator) Calculate(ctx context.Context, req tax.Calculation
 			},
 		},
 		LineItemTaxes: lineItemTaxes,
-		ShippingTax:   req.ShippingCost.Multiply(c.defaultRate),
+		ShippingTax:   shippingTax,
 	}, nil
 }
 
+// taxOn returns the tax on amount, or the tax already included in it when
+// req.TaxInclusive is set (amount - amount/(1+rate)).
+func (c *SimpleTaxCalculator) taxOn(amount money.Money, req tax.CalculationRequest) money.Money {
+	if !req.TaxInclusive {
+		return amount.MultiplyWithRounding(c.defaultRate, req.RoundingMode)
+	}
+	net := amount.MultiplyWithRounding(1/(1+c.defaultRate), req.RoundingMode)
+	embedded, _ := amount.Subtract(net)
+	return embedded
+}
+
 func (c *SimpleTaxCalculator) GetRatesForAddress(ctx context.Context, address tax.Address) ([]tax.TaxRate, error) {
 	return []tax.TaxRate{
 		{
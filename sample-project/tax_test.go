@@ -0,0 +1,32 @@
+package main
+
+import (
+	"context"
+	"testing"
+
+	"github.com/devchuckcamp/gocommerce/money"
+	"github.com/devchuckcamp/gocommerce/tax"
+)
+
+func TestSimpleTaxCalculatorTaxesLineAmounts(t *testing.T) {
+	c := NewSimpleTaxCalculator(0.10)
+	result, err := c.Calculate(context.Background(), tax.CalculationRequest{
+		LineItems: []tax.TaxableItem{
+			{ID: "mugs", Amount: money.Money{Amount: 3000, Currency: "USD"}, Quantity: 3, IsTaxable: true},
+			{ID: "bread", Amount: money.Money{Amount: 400, Currency: "USD"}, Quantity: 1, TaxCode: tax.TaxCodeExempt},
+		},
+		ShippingCost: money.Money{Amount: 500, Currency: "USD"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := result.LineItemTaxes[0].TaxAmount.Amount; got != 300 {
+		t.Errorf("mugs tax = %d, want 300 (10%% of the line amount, not of amount x quantity)", got)
+	}
+	if got := result.LineItemTaxes[1].TaxAmount.Amount; got != 0 {
+		t.Errorf("exempt bread tax = %d, want 0", got)
+	}
+	if result.TotalTax.Amount != 350 {
+		t.Errorf("TotalTax = %s, want USD 3.50", result.TotalTax)
+	}
+}
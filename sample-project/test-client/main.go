@@ -29,7 +29,7 @@ func main() {
 	for _, p := range products {
 		name := p["Name"].(string)
 		price := p["BasePrice"].(map[string]interface{})
-		amount := price["Amount"].(float64) / 100
+		amount := price["amount"].(float64) / 100
 		fmt.Printf("   - %s: $%.2f\n", name, amount)
 	}
 	fmt.Println()
@@ -54,7 +54,7 @@ func main() {
 		i := item.(map[string]interface{})
 		name := i["Name"].(string)
 		qty := int(i["Quantity"].(float64))
-		price := i["Price"].(map[string]interface{})["Amount"].(float64) / 100
+		price := i["Price"].(map[string]interface{})["amount"].(float64) / 100
 		lineTotal := price * float64(qty)
 		subtotal += lineTotal
 		fmt.Printf("   - %s x%d = $%.2f\n", name, qty, lineTotal)
@@ -65,10 +65,10 @@ func main() {
 	// Test 4: Preview checkout
 	fmt.Println("4️⃣  Previewing checkout totals...")
 	preview := previewCheckout()
-	subtotalPreview := preview["Subtotal"].(map[string]interface{})["Amount"].(float64) / 100
-	tax := preview["TaxTotal"].(map[string]interface{})["Amount"].(float64) / 100
-	shipping := preview["ShippingTotal"].(map[string]interface{})["Amount"].(float64) / 100
-	total := preview["Total"].(map[string]interface{})["Amount"].(float64) / 100
+	subtotalPreview := preview["Subtotal"].(map[string]interface{})["amount"].(float64) / 100
+	tax := preview["TaxTotal"].(map[string]interface{})["amount"].(float64) / 100
+	shipping := preview["ShippingTotal"].(map[string]interface{})["amount"].(float64) / 100
+	total := preview["Total"].(map[string]interface{})["amount"].(float64) / 100
 	fmt.Printf("   Subtotal: $%.2f\n", subtotalPreview)
 	fmt.Printf("   Tax:      $%.2f\n", tax)
 	fmt.Printf("   Shipping: $%.2f\n", shipping)
@@ -0,0 +1,135 @@
+package shipping
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// CachingRateCalculator decorates a RateCalculator, caching rates for a fixed TTL.
+// Entries are keyed by shipping method, destination address, the weight and
+// dimensions of each item, and order value, so repeated previews of the same cart and address
+// reuse the carrier lookup instead of recomputing it, while a cart that
+// crosses a free-shipping threshold is priced afresh.
+type CachingRateCalculator struct {
+	next    RateCalculator
+	ttl     time.Duration
+	now     func() time.Time
+	mu      sync.Mutex
+	rates   map[string]cachedRate
+	rateSet map[string]cachedRates
+}
+
+type cachedRate struct {
+	rate      *ShippingRate
+	expiresAt time.Time
+}
+
+type cachedRates struct {
+	rates     []*ShippingRate
+	expiresAt time.Time
+}
+
+// NewCachingRateCalculator wraps next with a rate cache whose entries live for ttl.
+func NewCachingRateCalculator(next RateCalculator, ttl time.Duration) *CachingRateCalculator {
+	return &CachingRateCalculator{
+		next:    next,
+		ttl:     ttl,
+		now:     time.Now,
+		rates:   make(map[string]cachedRate),
+		rateSet: make(map[string]cachedRates),
+	}
+}
+
+// GetRate returns the cached rate for the request, calling the underlying
+// calculator only on a miss or after the cached entry has expired.
+func (c *CachingRateCalculator) GetRate(ctx context.Context, req RateRequest) (*ShippingRate, error) {
+	key := rateCacheKey(req.ShippingMethodID, req)
+
+	c.mu.Lock()
+	entry, ok := c.rates[key]
+	c.mu.Unlock()
+	if ok && c.now().Before(entry.expiresAt) {
+		return entry.rate, nil
+	}
+
+	rate, err := c.next.GetRate(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	c.mu.Lock()
+	c.rates[key] = cachedRate{rate: rate, expiresAt: c.now().Add(c.ttl)}
+	c.mu.Unlock()
+
+	return rate, nil
+}
+
+// GetAvailableRates returns the cached rate list for the request's destination
+// and items, calling the underlying calculator on a miss or expiry.
+func (c *CachingRateCalculator) GetAvailableRates(ctx context.Context, req RateRequest) ([]*ShippingRate, error) {
+	key := rateCacheKey("", req)
+
+	c.mu.Lock()
+	entry, ok := c.rateSet[key]
+	c.mu.Unlock()
+	if ok && c.now().Before(entry.expiresAt) {
+		return entry.rates, nil
+	}
+
+	rates, err := c.next.GetAvailableRates(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	c.mu.Lock()
+	c.rateSet[key] = cachedRates{rates: rates, expiresAt: c.now().Add(c.ttl)}
+	c.mu.Unlock()
+
+	return rates, nil
+}
+
+// Purge removes expired entries. Expired entries are never served, so calling
+// this is only needed to bound memory use in long-running processes.
+func (c *CachingRateCalculator) Purge() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	now := c.now()
+	for key, entry := range c.rates {
+		if !now.Before(entry.expiresAt) {
+			delete(c.rates, key)
+		}
+	}
+	for key, entry := range c.rateSet {
+		if !now.Before(entry.expiresAt) {
+			delete(c.rateSet, key)
+		}
+	}
+}
+
+// rateCacheKey builds a cache key from the method, destination, items, order
+// value, and the item properties that affect method eligibility. Items are
+// keyed by quantity, weight, and dimensions rather than total weight, since
+// volumetric pricing bills each unit on the greater of its actual and
+// volumetric weight. Each component is quoted so values containing
+// separators can't make two different destinations produce the same key.
+func rateCacheKey(methodID string, req RateRequest) string {
+	parcels := make([]string, 0, len(req.Items))
+	hazmat, coldChain := false, false
+	for _, item := range req.Items {
+		parcels = append(parcels, fmt.Sprintf("%dx%dg:%dx%dx%d",
+			item.Quantity, item.WeightGrams, item.LengthCm, item.WidthCm, item.HeightCm))
+		hazmat = hazmat || item.IsHazmat
+		coldChain = coldChain || item.RequiresColdChain
+	}
+	sort.Strings(parcels)
+	dest := req.DestinationAddress
+	return fmt.Sprintf("%q|%q|%q|%q|%q|%s|%d|%q|%t|%t",
+		methodID, dest.Country, dest.State, dest.City, dest.PostalCode,
+		strings.Join(parcels, ","), req.OrderValue.Amount, req.OrderValue.Currency,
+		hazmat, coldChain)
+}
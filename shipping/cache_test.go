@@ -0,0 +1,212 @@
+package shipping
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/devchuckcamp/gocommerce/money"
+)
+
+// methodRepo is an in-memory Repository holding shipping methods.
+type methodRepo struct {
+	Repository
+	methods []*ShippingMethod
+}
+
+func (r *methodRepo) FindMethod(ctx context.Context, id string) (*ShippingMethod, error) {
+	for _, m := range r.methods {
+		if m.ID == id {
+			return m, nil
+		}
+	}
+	return nil, ErrMethodUnavailable
+}
+
+func (r *methodRepo) FindActiveMethods(ctx context.Context) ([]*ShippingMethod, error) {
+	var active []*ShippingMethod
+	for _, m := range r.methods {
+		if m.IsActive {
+			active = append(active, m)
+		}
+	}
+	return active, nil
+}
+
+// countingCalculator counts calls through to next.
+type countingCalculator struct {
+	next  RateCalculator
+	calls int
+}
+
+func (c *countingCalculator) GetRate(ctx context.Context, req RateRequest) (*ShippingRate, error) {
+	c.calls++
+	return c.next.GetRate(ctx, req)
+}
+
+func (c *countingCalculator) GetAvailableRates(ctx context.Context, req RateRequest) ([]*ShippingRate, error) {
+	c.calls++
+	return c.next.GetAvailableRates(ctx, req)
+}
+
+func usd(cents int64) money.Money {
+	return money.Money{Amount: cents, Currency: "USD"}
+}
+
+func usdPtr(cents int64) *money.Money {
+	m := usd(cents)
+	return &m
+}
+
+func TestCachingRateCalculatorReusesRate(t *testing.T) {
+	ctx := context.Background()
+	repo := &methodRepo{methods: []*ShippingMethod{
+		{ID: "ground", Name: "Ground", IsActive: true, FlatRate: usdPtr(599)},
+	}}
+	counter := &countingCalculator{next: NewRuleBasedCalculator(repo)}
+	cache := NewCachingRateCalculator(counter, time.Minute)
+
+	req := RateRequest{
+		Items:              []ShippableItem{{SKU: "A", Quantity: 1, WeightGrams: 500}},
+		DestinationAddress: Address{Country: "US", PostalCode: "12345"},
+		ShippingMethodID:   "ground",
+		OrderValue:         usd(2000),
+	}
+	for i := 0; i < 3; i++ {
+		if _, err := cache.GetRate(ctx, req); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if counter.calls != 1 {
+		t.Errorf("calls = %d, want 1", counter.calls)
+	}
+}
+
+func TestCachingRateCalculatorKeysOnOrderValue(t *testing.T) {
+	ctx := context.Background()
+	repo := &methodRepo{methods: []*ShippingMethod{
+		{ID: "ground", Name: "Ground", IsActive: true, FlatRate: usdPtr(599), FreeShippingMin: usdPtr(5000)},
+	}}
+	cache := NewCachingRateCalculator(NewRuleBasedCalculator(repo), time.Minute)
+
+	req := RateRequest{
+		Items:              []ShippableItem{{SKU: "A", Quantity: 1, WeightGrams: 500}},
+		DestinationAddress: Address{Country: "US", PostalCode: "12345"},
+		ShippingMethodID:   "ground",
+		OrderValue:         usd(4000),
+	}
+	below, err := cache.GetRate(ctx, req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if below.Cost.Amount != 599 {
+		t.Fatalf("cost below threshold = %s, want USD 5.99", below.Cost)
+	}
+
+	req.OrderValue = usd(6000)
+	above, err := cache.GetRate(ctx, req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !above.Cost.IsZero() {
+		t.Errorf("cost above threshold = %s, want free", above.Cost)
+	}
+}
+
+func TestCachingRateCalculatorKeysOnDimensions(t *testing.T) {
+	ctx := context.Background()
+	repo := &methodRepo{methods: []*ShippingMethod{
+		{ID: "ground", Name: "Ground", IsActive: true, RatePerWeightKg: usdPtr(100), VolumetricDivisor: 5000},
+	}}
+	cache := NewCachingRateCalculator(NewRuleBasedCalculator(repo), time.Minute)
+
+	// Same actual weight; the second box is bulky enough to bill at 6 kg.
+	req := RateRequest{
+		Items:              []ShippableItem{{SKU: "A", Quantity: 1, WeightGrams: 1000, LengthCm: 10, WidthCm: 10, HeightCm: 10}},
+		DestinationAddress: Address{Country: "US", PostalCode: "12345"},
+		ShippingMethodID:   "ground",
+		OrderValue:         usd(2000),
+	}
+	small, err := cache.GetRate(ctx, req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	req.Items = []ShippableItem{{SKU: "A", Quantity: 1, WeightGrams: 1000, LengthCm: 50, WidthCm: 30, HeightCm: 20}}
+	bulky, err := cache.GetRate(ctx, req)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if small.Cost.Amount != 100 {
+		t.Errorf("small box cost = %s, want USD 1.00", small.Cost)
+	}
+	if bulky.Cost.Amount != 600 {
+		t.Errorf("bulky box cost = %s, want USD 6.00", bulky.Cost)
+	}
+}
+
+func TestCachingRateCalculatorExpiresEntries(t *testing.T) {
+	ctx := context.Background()
+	repo := &methodRepo{methods: []*ShippingMethod{
+		{ID: "ground", Name: "Ground", IsActive: true, FlatRate: usdPtr(599)},
+	}}
+	counter := &countingCalculator{next: NewRuleBasedCalculator(repo)}
+	cache := NewCachingRateCalculator(counter, time.Minute)
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	cache.now = func() time.Time { return now }
+
+	req := RateRequest{
+		Items:              []ShippableItem{{SKU: "A", Quantity: 1, WeightGrams: 500}},
+		DestinationAddress: Address{Country: "US", PostalCode: "12345"},
+		OrderValue:         usd(2000),
+	}
+	for i := 0; i < 2; i++ {
+		if _, err := cache.GetAvailableRates(ctx, req); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if counter.calls != 1 {
+		t.Fatalf("calls within ttl = %d, want 1", counter.calls)
+	}
+
+	now = now.Add(time.Minute)
+	if _, err := cache.GetAvailableRates(ctx, req); err != nil {
+		t.Fatal(err)
+	}
+	if counter.calls != 2 {
+		t.Errorf("calls after expiry = %d, want 2", counter.calls)
+	}
+
+	now = now.Add(time.Minute)
+	cache.Purge()
+	if len(cache.rateSet) != 0 {
+		t.Errorf("%d entries left after Purge, want 0", len(cache.rateSet))
+	}
+
+	req.ShippingMethodID = "missing"
+	for i := 0; i < 2; i++ {
+		if _, err := cache.GetRate(ctx, req); err == nil {
+			t.Fatal("rate for an unknown method")
+		}
+	}
+	if counter.calls != 4 {
+		t.Errorf("calls after two failed lookups = %d, want 4", counter.calls)
+	}
+}
+
+func TestCachingRateCalculatorKeysOnEligibility(t *testing.T) {
+	ctx := context.Background()
+	repo := &methodRepo{methods: []*ShippingMethod{
+		{ID: "ground", IsActive: true, FlatRate: usdPtr(599)},
+	}}
+	cache := NewCachingRateCalculator(NewRuleBasedCalculator(repo), time.Minute)
+
+	req := RateRequest{Items: []ShippableItem{{SKU: "A", Quantity: 1, WeightGrams: 500}}, ShippingMethodID: "ground"}
+	if _, err := cache.GetRate(ctx, req); err != nil {
+		t.Fatal(err)
+	}
+	req.Items = []ShippableItem{{SKU: "A", Quantity: 1, WeightGrams: 500, IsHazmat: true}}
+	if _, err := cache.GetRate(ctx, req); err == nil {
+		t.Error("hazmat shipment reused the cached ordinary rate")
+	}
+}
@@ -0,0 +1,171 @@
+package shipping
+
+import (
+	"context"
+	"errors"
+
+	"github.com/devchuckcamp/gocommerce/money"
+)
+
+var (
+	ErrMethodUnavailable = errors.New("shipping method is not active")
+	ErrCannotShip        = errors.New("shipping method cannot carry these items")
+	ErrNoFreeShipping    = errors.New("shipping method has no free-shipping threshold")
+)
+
+// RuleBasedCalculator is a RateCalculator that prices shipments from the
+// rules stored on each ShippingMethod: a flat rate plus a per-kilogram rate
+// on the total shipment weight, waived once the order value reaches
+// FreeShippingMin.
+type RuleBasedCalculator struct {
+	repo              Repository
+	volumetricDivisor int
+}
+
+// RuleBasedOption configures optional RuleBasedCalculator behavior.
+type RuleBasedOption func(*RuleBasedCalculator)
+
+// WithVolumetricDivisor sets the volumetric divisor used for methods that
+// don't set their own VolumetricDivisor.
+func WithVolumetricDivisor(divisor int) RuleBasedOption {
+	return func(c *RuleBasedCalculator) {
+		c.volumetricDivisor = divisor
+	}
+}
+
+// NewRuleBasedCalculator creates a calculator that reads methods from repo.
+func NewRuleBasedCalculator(repo Repository, opts ...RuleBasedOption) *RuleBasedCalculator {
+	c := &RuleBasedCalculator{repo: repo}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
+}
+
+// GetRate returns the rate for req.ShippingMethodID.
+func (c *RuleBasedCalculator) GetRate(ctx context.Context, req RateRequest) (*ShippingRate, error) {
+	method, err := c.repo.FindMethod(ctx, req.ShippingMethodID)
+	if err != nil {
+		return nil, err
+	}
+	if !method.IsActive {
+		return nil, ErrMethodUnavailable
+	}
+	if !method.CanShip(req.Items) {
+		return nil, ErrCannotShip
+	}
+	return c.withDefaults(method).Rate(req)
+}
+
+// GetAvailableRates returns a rate for every active method that can ship
+// the items, in the order the repository returns them.
+func (c *RuleBasedCalculator) GetAvailableRates(ctx context.Context, req RateRequest) ([]*ShippingRate, error) {
+	methods, err := c.repo.FindActiveMethods(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	rates := make([]*ShippingRate, 0, len(methods))
+	for _, method := range EligibleMethods(methods, req.Items) {
+		rate, err := c.withDefaults(method).Rate(req)
+		if err != nil {
+			continue
+		}
+		rates = append(rates, rate)
+	}
+	return rates, nil
+}
+
+// FreeShippingProgress reports how close req.OrderValue is to the
+// FreeShippingMin of req.ShippingMethodID (e.g., for "add $12 more for free
+// shipping" banners). remaining is zero once the order qualifies. It returns
+// ErrNoFreeShipping if the method has no threshold, and
+// money.ErrCurrencyMismatch if the threshold is in another currency.
+func (c *RuleBasedCalculator) FreeShippingProgress(ctx context.Context, req RateRequest) (qualified bool, remaining money.Money, threshold money.Money, err error) {
+	method, err := c.repo.FindMethod(ctx, req.ShippingMethodID)
+	if err != nil {
+		return false, money.Money{}, money.Money{}, err
+	}
+	if method.FreeShippingMin == nil {
+		return false, money.Money{}, money.Money{}, ErrNoFreeShipping
+	}
+
+	threshold = *method.FreeShippingMin
+	gap, err := threshold.Subtract(req.OrderValue)
+	if err != nil {
+		return false, money.Money{}, money.Money{}, err
+	}
+	if !gap.IsPositive() {
+		return true, money.Zero(req.OrderValue.Currency), threshold, nil
+	}
+	return false, gap, threshold, nil
+}
+
+// withDefaults returns method with the calculator's volumetric divisor
+// filled in when the method has none, leaving the original untouched.
+func (c *RuleBasedCalculator) withDefaults(method *ShippingMethod) *ShippingMethod {
+	if method.VolumetricDivisor > 0 || c.volumetricDivisor <= 0 {
+		return method
+	}
+	configured := *method
+	configured.VolumetricDivisor = c.volumetricDivisor
+	return &configured
+}
+
+// BillableWeightGrams returns the total weight carriers bill for items: each
+// unit counts as the greater of its actual weight and its volumetric weight
+// (LengthCm*WidthCm*HeightCm / divisor kg). A divisor of 0 or less uses
+// actual weight only.
+func BillableWeightGrams(items []ShippableItem, divisor int) int {
+	total := 0
+	for _, item := range items {
+		weight := item.WeightGrams
+		if divisor > 0 {
+			volumetric := item.LengthCm * item.WidthCm * item.HeightCm * 1000 / divisor
+			if volumetric > weight {
+				weight = volumetric
+			}
+		}
+		total += weight * item.Quantity
+	}
+	return total
+}
+
+// Rate prices req with the method's rules. The cost is FlatRate plus
+// RatePerWeightKg for each kilogram of billable weight (pro rata; see
+// BillableWeightGrams), or zero when FreeShippingMin is set and
+// req.OrderValue reaches it. A method with no rates ships free in the
+// order's currency.
+func (m *ShippingMethod) Rate(req RateRequest) (*ShippingRate, error) {
+	cost := money.Zero(req.OrderValue.Currency)
+	if m.FlatRate != nil {
+		cost = *m.FlatRate
+	}
+
+	if m.RatePerWeightKg != nil {
+		totalGrams := BillableWeightGrams(req.Items, m.VolumetricDivisor)
+		weightCost := m.RatePerWeightKg.MultiplyWithRounding(float64(totalGrams)/1000, money.DefaultRoundingMode)
+		if m.FlatRate == nil {
+			cost = weightCost
+		} else {
+			var err error
+			if cost, err = cost.Add(weightCost); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	if m.FreeShippingMin != nil {
+		if below, err := req.OrderValue.LessThan(*m.FreeShippingMin); err == nil && !below {
+			cost = money.Zero(cost.Currency)
+		}
+	}
+
+	return &ShippingRate{
+		MethodID:     m.ID,
+		MethodName:   m.Name,
+		Cost:         cost,
+		Carrier:      m.Carrier,
+		ServiceLevel: m.ServiceLevel,
+	}, nil
+}
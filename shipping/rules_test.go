@@ -0,0 +1,91 @@
+package shipping
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/devchuckcamp/gocommerce/money"
+)
+
+func TestRuleBasedCalculatorEligibility(t *testing.T) {
+	ctx := context.Background()
+	repo := &methodRepo{methods: []*ShippingMethod{
+		{ID: "ground", IsActive: true, FlatRate: usdPtr(599), MaxWeightGrams: 30000},
+		{ID: "hazmat", IsActive: true, FlatRate: usdPtr(1999), AllowsHazmat: true},
+		{ID: "cold", IsActive: true, FlatRate: usdPtr(2499), SupportsColdChain: true, MaxWeightGrams: 5000},
+	}}
+	c := NewRuleBasedCalculator(repo)
+
+	tests := []struct {
+		name string
+		item ShippableItem
+		want []string
+	}{
+		{"ordinary", ShippableItem{SKU: "A", Quantity: 1, WeightGrams: 1000}, []string{"ground", "hazmat", "cold"}},
+		{"hazmat", ShippableItem{SKU: "A", Quantity: 1, WeightGrams: 1000, IsHazmat: true}, []string{"hazmat"}},
+		{"cold chain", ShippableItem{SKU: "A", Quantity: 1, WeightGrams: 1000, RequiresColdChain: true}, []string{"cold"}},
+		{"over unit weight", ShippableItem{SKU: "A", Quantity: 1, WeightGrams: 6000}, []string{"ground", "hazmat"}},
+		{"under unit weight", ShippableItem{SKU: "A", Quantity: 10, WeightGrams: 4000}, []string{"ground", "hazmat", "cold"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := RateRequest{Items: []ShippableItem{tt.item}, OrderValue: usd(1000)}
+			rates, err := c.GetAvailableRates(ctx, req)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(rates) != len(tt.want) {
+				t.Fatalf("got %d rates, want %v", len(rates), tt.want)
+			}
+			for i, rate := range rates {
+				if rate.MethodID != tt.want[i] {
+					t.Errorf("rate %d = %s, want %s", i, rate.MethodID, tt.want[i])
+				}
+			}
+		})
+	}
+
+	req := RateRequest{Items: []ShippableItem{{SKU: "A", Quantity: 1, IsHazmat: true}}, ShippingMethodID: "ground"}
+	if _, err := c.GetRate(ctx, req); !errors.Is(err, ErrCannotShip) {
+		t.Errorf("hazmat by ground: error = %v, want %v", err, ErrCannotShip)
+	}
+}
+
+func TestFreeShippingProgress(t *testing.T) {
+	ctx := context.Background()
+	c := NewRuleBasedCalculator(&methodRepo{methods: []*ShippingMethod{
+		{ID: "ground", IsActive: true, FlatRate: usdPtr(599), FreeShippingMin: usdPtr(5000)},
+		{ID: "express", IsActive: true, FlatRate: usdPtr(1500)},
+	}})
+
+	tests := []struct {
+		name          string
+		value         int64
+		wantQualified bool
+		wantRemaining int64
+	}{
+		{"short", 3800, false, 1200},
+		{"exactly at threshold", 5000, true, 0},
+		{"over threshold", 7500, true, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			qualified, remaining, threshold, err := c.FreeShippingProgress(ctx, RateRequest{ShippingMethodID: "ground", OrderValue: usd(tt.value)})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if qualified != tt.wantQualified || remaining != usd(tt.wantRemaining) || threshold != usd(5000) {
+				t.Errorf("got %t, remaining %s, threshold %s; want %t, %d", qualified, remaining, threshold, tt.wantQualified, tt.wantRemaining)
+			}
+		})
+	}
+
+	if _, _, _, err := c.FreeShippingProgress(ctx, RateRequest{ShippingMethodID: "express", OrderValue: usd(100)}); !errors.Is(err, ErrNoFreeShipping) {
+		t.Errorf("no threshold: error = %v, want %v", err, ErrNoFreeShipping)
+	}
+	eur := RateRequest{ShippingMethodID: "ground", OrderValue: money.Money{Amount: 100, Currency: "EUR"}}
+	if _, _, _, err := c.FreeShippingProgress(ctx, eur); !errors.Is(err, money.ErrCurrencyMismatch) {
+		t.Errorf("other currency: error = %v, want %v", err, money.ErrCurrencyMismatch)
+	}
+}
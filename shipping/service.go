@@ -0,0 +1,175 @@
+package shipping
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+var (
+	ErrShipmentNotFound    = errors.New("shipment not found")
+	ErrInvalidShipment     = errors.New("invalid shipment")
+	ErrInvalidStatusChange = errors.New("invalid shipment status transition")
+)
+
+// ShipmentRepository defines methods for shipment persistence.
+type ShipmentRepository interface {
+	FindByID(ctx context.Context, id string) (*Shipment, error)
+	FindByOrder(ctx context.Context, orderID string) ([]*Shipment, error)
+	Save(ctx context.Context, shipment *Shipment) error
+}
+
+// Service manages the lifecycle of shipments.
+type Service interface {
+	CreateShipment(ctx context.Context, req CreateShipmentRequest) (*Shipment, error)
+	MarkInTransit(ctx context.Context, shipmentID, trackingNumber, trackingURL string) (*Shipment, error)
+	MarkDelivered(ctx context.Context, shipmentID string, proof DeliveryProof) (*Shipment, error)
+	MarkFailed(ctx context.Context, shipmentID string) (*Shipment, error)
+	MarkReturned(ctx context.Context, shipmentID string) (*Shipment, error)
+	GetByOrder(ctx context.Context, orderID string) ([]*Shipment, error)
+}
+
+// CreateShipmentRequest contains data needed to create a shipment.
+type CreateShipmentRequest struct {
+	OrderID           string
+	Carrier           string
+	ServiceLevel      string
+	TrackingNumber    string // Optional; may also be set by MarkInTransit
+	TrackingURL       string
+	LabelURL          string
+	EstimatedDelivery int64 // Unix timestamp
+}
+
+// DeliveryHandler is called after a shipment is saved as delivered.
+type DeliveryHandler func(ctx context.Context, shipment *Shipment) error
+
+// ShipmentService implements the Service interface.
+type ShipmentService struct {
+	repo        ShipmentRepository
+	idGenerator func() string
+	onDelivered DeliveryHandler
+}
+
+// ServiceOption configures optional ShipmentService behavior.
+type ServiceOption func(*ShipmentService)
+
+// WithDeliveryHandler installs a handler run when a shipment is delivered,
+// e.g., to mark the order delivered:
+//
+//	shipping.WithDeliveryHandler(func(ctx context.Context, s *shipping.Shipment) error {
+//		_, err := orderService.UpdateStatus(ctx, s.OrderID, orders.OrderStatusDelivered)
+//		return err
+//	})
+func WithDeliveryHandler(handler DeliveryHandler) ServiceOption {
+	return func(s *ShipmentService) {
+		s.onDelivered = handler
+	}
+}
+
+// NewShipmentService creates a new shipment service.
+func NewShipmentService(repo ShipmentRepository, idGenerator func() string, opts ...ServiceOption) *ShipmentService {
+	s := &ShipmentService{
+		repo:        repo,
+		idGenerator: idGenerator,
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
+}
+
+// CreateShipment creates a pending shipment for an order.
+func (s *ShipmentService) CreateShipment(ctx context.Context, req CreateShipmentRequest) (*Shipment, error) {
+	if req.OrderID == "" {
+		return nil, ErrInvalidShipment
+	}
+
+	shipment := &Shipment{
+		ID:                s.idGenerator(),
+		OrderID:           req.OrderID,
+		Carrier:           req.Carrier,
+		ServiceLevel:      req.ServiceLevel,
+		TrackingNumber:    req.TrackingNumber,
+		TrackingURL:       req.TrackingURL,
+		LabelURL:          req.LabelURL,
+		Status:            ShipmentStatusPending,
+		EstimatedDelivery: req.EstimatedDelivery,
+	}
+	if err := s.repo.Save(ctx, shipment); err != nil {
+		return nil, err
+	}
+	return shipment, nil
+}
+
+// MarkInTransit records that the carrier has the shipment, setting
+// ShippedAt and, when given, the tracking number and URL. A failed
+// shipment can be put back in transit for another delivery attempt.
+func (s *ShipmentService) MarkInTransit(ctx context.Context, shipmentID, trackingNumber, trackingURL string) (*Shipment, error) {
+	return s.transition(ctx, shipmentID, ShipmentStatusInTransit, func(shipment *Shipment) {
+		if trackingNumber != "" {
+			shipment.TrackingNumber = trackingNumber
+		}
+		if trackingURL != "" {
+			shipment.TrackingURL = trackingURL
+		}
+		if shipment.ShippedAt == 0 {
+			shipment.ShippedAt = time.Now().Unix()
+		}
+	})
+}
+
+// MarkDelivered marks the shipment delivered with proof of delivery, then
+// runs the delivery handler, if any. A handler error is returned along with
+// the shipment, which stays delivered.
+func (s *ShipmentService) MarkDelivered(ctx context.Context, shipmentID string, proof DeliveryProof) (*Shipment, error) {
+	shipment, err := s.transition(ctx, shipmentID, ShipmentStatusDelivered, func(shipment *Shipment) {
+		shipment.MarkDelivered(proof)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if s.onDelivered != nil {
+		if err := s.onDelivered(ctx, shipment); err != nil {
+			return shipment, err
+		}
+	}
+	return shipment, nil
+}
+
+// MarkFailed records a failed delivery attempt.
+func (s *ShipmentService) MarkFailed(ctx context.Context, shipmentID string) (*Shipment, error) {
+	return s.transition(ctx, shipmentID, ShipmentStatusFailed, nil)
+}
+
+// MarkReturned records that the shipment came back to the sender.
+func (s *ShipmentService) MarkReturned(ctx context.Context, shipmentID string) (*Shipment, error) {
+	return s.transition(ctx, shipmentID, ShipmentStatusReturned, nil)
+}
+
+// GetByOrder returns an order's shipments.
+func (s *ShipmentService) GetByOrder(ctx context.Context, orderID string) ([]*Shipment, error) {
+	return s.repo.FindByOrder(ctx, orderID)
+}
+
+// transition loads a shipment, checks it may move to status, applies update
+// (which may set status itself), and saves it.
+func (s *ShipmentService) transition(ctx context.Context, shipmentID string, status ShipmentStatus, update func(*Shipment)) (*Shipment, error) {
+	shipment, err := s.repo.FindByID(ctx, shipmentID)
+	if err != nil {
+		return nil, err
+	}
+	if !shipment.CanTransitionTo(status) {
+		return nil, ErrInvalidStatusChange
+	}
+
+	if update != nil {
+		update(shipment)
+	}
+	shipment.Status = status
+
+	if err := s.repo.Save(ctx, shipment); err != nil {
+		return nil, err
+	}
+	return shipment, nil
+}
@@ -0,0 +1,95 @@
+package shipping
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+// shipmentRepo is an in-memory ShipmentRepository.
+type shipmentRepo struct {
+	shipments map[string]*Shipment
+}
+
+func (r *shipmentRepo) FindByID(ctx context.Context, id string) (*Shipment, error) {
+	s, ok := r.shipments[id]
+	if !ok {
+		return nil, ErrShipmentNotFound
+	}
+	copied := *s
+	return &copied, nil
+}
+
+func (r *shipmentRepo) FindByOrder(ctx context.Context, orderID string) ([]*Shipment, error) {
+	var result []*Shipment
+	for _, s := range r.shipments {
+		if s.OrderID == orderID {
+			result = append(result, s)
+		}
+	}
+	return result, nil
+}
+
+func (r *shipmentRepo) Save(ctx context.Context, s *Shipment) error {
+	copied := *s
+	r.shipments[s.ID] = &copied
+	return nil
+}
+
+func TestShipmentMustShipBeforeDelivery(t *testing.T) {
+	ctx := context.Background()
+	delivered := 0
+	s := NewShipmentService(&shipmentRepo{shipments: make(map[string]*Shipment)},
+		func() string { return "ship-1" },
+		WithDeliveryHandler(func(ctx context.Context, shipment *Shipment) error {
+			delivered++
+			return nil
+		}))
+
+	shipment, err := s.CreateShipment(ctx, CreateShipmentRequest{OrderID: "order-1", Carrier: "UPS"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.MarkDelivered(ctx, shipment.ID, DeliveryProof{}); !errors.Is(err, ErrInvalidStatusChange) {
+		t.Fatalf("deliver pending shipment: error = %v, want %v", err, ErrInvalidStatusChange)
+	}
+
+	shipment, err = s.MarkInTransit(ctx, shipment.ID, "1Z999", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if shipment.ShippedAt == 0 || shipment.TrackingNumber != "1Z999" {
+		t.Errorf("in transit: ShippedAt = %d, TrackingNumber = %q", shipment.ShippedAt, shipment.TrackingNumber)
+	}
+
+	shipment, err = s.MarkDelivered(ctx, shipment.ID, DeliveryProof{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if shipment.Status != ShipmentStatusDelivered || shipment.DeliveredAt == nil || shipment.ShippedAt == 0 {
+		t.Errorf("delivered: status %s, DeliveredAt %v, ShippedAt %d", shipment.Status, shipment.DeliveredAt, shipment.ShippedAt)
+	}
+	if delivered != 1 {
+		t.Errorf("delivery handler ran %d times, want 1", delivered)
+	}
+}
+
+func TestShipmentCanTransitionTo(t *testing.T) {
+	tests := []struct {
+		from, to ShipmentStatus
+		want     bool
+	}{
+		{ShipmentStatusPending, ShipmentStatusInTransit, true},
+		{ShipmentStatusPending, ShipmentStatusDelivered, false},
+		{ShipmentStatusInTransit, ShipmentStatusDelivered, true},
+		{ShipmentStatusFailed, ShipmentStatusInTransit, true},
+		{ShipmentStatusDelivered, ShipmentStatusReturned, true},
+		{ShipmentStatusReturned, ShipmentStatusInTransit, false},
+	}
+	for _, tt := range tests {
+		s := &Shipment{Status: tt.from}
+		if got := s.CanTransitionTo(tt.to); got != tt.want {
+			t.Errorf("%s → %s = %t, want %t", tt.from, tt.to, got, tt.want)
+		}
+	}
+}
@@ -2,6 +2,7 @@ package shipping
 
 import (
 	"context"
+	"time"
 
 	"github.com/devchuckcamp/gocommerce/money"
 )
@@ -18,18 +19,20 @@ type RateRequest struct {
 	SourceAddress      Address
 	DestinationAddress Address
 	ShippingMethodID   string
+	OrderValue         money.Money // Merchandise value after discounts, for free-shipping thresholds
 }
 
 // ShippableItem represents an item that can be shipped.
 type ShippableItem struct {
-	SKU            string
-	Quantity       int
-	WeightGrams    int
-	LengthCm       int
-	WidthCm        int
-	HeightCm       int
-	IsFragile      bool
+	SKU               string
+	Quantity          int
+	WeightGrams       int
+	LengthCm          int
+	WidthCm           int
+	HeightCm          int
+	IsFragile         bool
 	RequiresColdChain bool
+	IsHazmat          bool
 }
 
 // Address represents a shipping address.
@@ -55,16 +58,54 @@ type ShippingRate struct {
 
 // ShippingMethod represents a shipping method/carrier.
 type ShippingMethod struct {
-	ID          string
-	Name        string
-	Description string
-	Carrier     string
+	ID           string
+	Name         string
+	Description  string
+	Carrier      string
 	ServiceLevel string
-	IsActive    bool
+	IsActive     bool
 	// Rate calculation rules
-	FlatRate         *money.Money
-	RatePerWeightKg  *money.Money
-	FreeShippingMin  *money.Money
+	FlatRate        *money.Money
+	RatePerWeightKg *money.Money
+	FreeShippingMin *money.Money
+	// VolumetricDivisor converts item volume to weight: cm³ per kg (e.g., 5000).
+	// When set, each unit is rated on the greater of its actual and
+	// volumetric weight. 0 rates on actual weight only.
+	VolumetricDivisor int
+	// Eligibility rules; a method that fails them for any item in the
+	// shipment is not offered (see CanShip).
+	AllowsHazmat      bool
+	SupportsColdChain bool
+	MaxWeightGrams    int // Per unit; 0 means no limit
+}
+
+// CanShip reports whether the method can carry every item: hazmat and
+// cold-chain items need a method that allows them, and no unit may weigh
+// more than MaxWeightGrams.
+func (m *ShippingMethod) CanShip(items []ShippableItem) bool {
+	for _, item := range items {
+		if item.IsHazmat && !m.AllowsHazmat {
+			return false
+		}
+		if item.RequiresColdChain && !m.SupportsColdChain {
+			return false
+		}
+		if m.MaxWeightGrams > 0 && item.WeightGrams > m.MaxWeightGrams {
+			return false
+		}
+	}
+	return true
+}
+
+// EligibleMethods returns the methods that can ship items, in their original order.
+func EligibleMethods(methods []*ShippingMethod, items []ShippableItem) []*ShippingMethod {
+	eligible := make([]*ShippingMethod, 0, len(methods))
+	for _, method := range methods {
+		if method.CanShip(items) {
+			eligible = append(eligible, method)
+		}
+	}
+	return eligible
 }
 
 // Repository defines methods for shipping data persistence.
@@ -72,21 +113,34 @@ type Repository interface {
 	FindMethod(ctx context.Context, id string) (*ShippingMethod, error)
 	FindActiveMethods(ctx context.Context) ([]*ShippingMethod, error)
 	SaveMethod(ctx context.Context, method *ShippingMethod) error
+	// FindZones returns every shipping zone.
+	FindZones(ctx context.Context) ([]*Zone, error)
+	// FindZoneRates returns the zones a method serves and its rates in each.
+	FindZoneRates(ctx context.Context, methodID string) ([]*ZoneRate, error)
 }
 
 // Shipment represents a package shipment.
 type Shipment struct {
-	ID              string
-	OrderID         string
-	Carrier         string
-	ServiceLevel    string
-	TrackingNumber  string
-	TrackingURL     string
-	LabelURL        string
-	Status          ShipmentStatus
-	ShippedAt       int64
-	DeliveredAt     *int64
+	ID                string
+	OrderID           string
+	Carrier           string
+	ServiceLevel      string
+	TrackingNumber    string
+	TrackingURL       string
+	LabelURL          string
+	Status            ShipmentStatus
+	ShippedAt         int64
+	DeliveredAt       *int64
 	EstimatedDelivery int64
+	DeliveryProof     *DeliveryProof // Set when the shipment is delivered
+}
+
+// DeliveryProof records evidence that a shipment reached the customer.
+type DeliveryProof struct {
+	SignedBy    string
+	PhotoURL    string
+	DeliveredAt int64  // Unix timestamp
+	Location    string // e.g., "front door", "mailroom", or GPS coordinates
 }
 
 // ShipmentStatus represents the state of a shipment.
@@ -99,3 +153,55 @@ const (
 	ShipmentStatusFailed     ShipmentStatus = "failed"
 	ShipmentStatusReturned   ShipmentStatus = "returned"
 )
+
+// CanTransitionTo checks if a shipment can move to a new status. Shipments
+// go pending → in_transit → delivered, so a delivered shipment has always
+// been shipped; a failed delivery can be retried or returned, and a
+// delivered shipment can still be returned.
+func (s *Shipment) CanTransitionTo(newStatus ShipmentStatus) bool {
+	transitions := map[ShipmentStatus][]ShipmentStatus{
+		ShipmentStatusPending: {
+			ShipmentStatusInTransit,
+			ShipmentStatusFailed,
+		},
+		ShipmentStatusInTransit: {
+			ShipmentStatusDelivered,
+			ShipmentStatusFailed,
+			ShipmentStatusReturned,
+		},
+		ShipmentStatusFailed: {
+			ShipmentStatusInTransit,
+			ShipmentStatusReturned,
+		},
+		ShipmentStatusDelivered: {
+			ShipmentStatusReturned,
+		},
+	}
+
+	for _, allowed := range transitions[s.Status] {
+		if allowed == newStatus {
+			return true
+		}
+	}
+	return false
+}
+
+// MarkDelivered transitions the shipment to delivered and records proof of delivery.
+// If proof.DeliveredAt is zero, the current time is used.
+// Returns false if the shipment can't move to delivered from its current status.
+func (s *Shipment) MarkDelivered(proof DeliveryProof) bool {
+	if !s.CanTransitionTo(ShipmentStatusDelivered) {
+		return false
+	}
+
+	if proof.DeliveredAt == 0 {
+		proof.DeliveredAt = time.Now().Unix()
+	}
+
+	deliveredAt := proof.DeliveredAt
+	s.Status = ShipmentStatusDelivered
+	s.DeliveredAt = &deliveredAt
+	s.DeliveryProof = &proof
+
+	return true
+}
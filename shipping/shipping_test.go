@@ -0,0 +1,63 @@
+package shipping
+
+import "testing"
+
+func TestShipmentMarkDeliveredRecordsProof(t *testing.T) {
+	s := &Shipment{Status: ShipmentStatusInTransit}
+	proof := DeliveryProof{SignedBy: "J. Doe", Location: "front door", DeliveredAt: 1700000000}
+
+	if !s.MarkDelivered(proof) {
+		t.Fatal("MarkDelivered refused an in-transit shipment")
+	}
+	if s.DeliveryProof == nil || s.DeliveryProof.SignedBy != "J. Doe" || s.DeliveryProof.Location != "front door" {
+		t.Errorf("DeliveryProof = %+v", s.DeliveryProof)
+	}
+	if s.DeliveredAt == nil || *s.DeliveredAt != 1700000000 {
+		t.Errorf("DeliveredAt = %v, want 1700000000", s.DeliveredAt)
+	}
+	if s.MarkDelivered(DeliveryProof{SignedBy: "someone else"}) {
+		t.Error("MarkDelivered accepted an already delivered shipment")
+	}
+	if s.DeliveryProof.SignedBy != "J. Doe" {
+		t.Errorf("proof overwritten by %q", s.DeliveryProof.SignedBy)
+	}
+
+	stamped := &Shipment{Status: ShipmentStatusInTransit}
+	stamped.MarkDelivered(DeliveryProof{})
+	if stamped.DeliveryProof.DeliveredAt == 0 || *stamped.DeliveredAt != stamped.DeliveryProof.DeliveredAt {
+		t.Errorf("zero DeliveredAt not stamped: proof %d, shipment %v", stamped.DeliveryProof.DeliveredAt, stamped.DeliveredAt)
+	}
+}
+
+func TestEligibleMethods(t *testing.T) {
+	methods := []*ShippingMethod{
+		{ID: "ground", MaxWeightGrams: 30000},
+		{ID: "hazmat", AllowsHazmat: true},
+		{ID: "cold", SupportsColdChain: true, MaxWeightGrams: 5000},
+	}
+
+	tests := []struct {
+		name string
+		item ShippableItem
+		want []string
+	}{
+		{"ordinary", ShippableItem{SKU: "A", Quantity: 1, WeightGrams: 1000}, []string{"ground", "hazmat", "cold"}},
+		{"hazmat", ShippableItem{SKU: "A", Quantity: 1, WeightGrams: 1000, IsHazmat: true}, []string{"hazmat"}},
+		{"cold chain", ShippableItem{SKU: "A", Quantity: 1, WeightGrams: 1000, RequiresColdChain: true}, []string{"cold"}},
+		{"over unit weight", ShippableItem{SKU: "A", Quantity: 1, WeightGrams: 6000}, []string{"ground", "hazmat"}},
+		{"under unit weight", ShippableItem{SKU: "A", Quantity: 10, WeightGrams: 4000}, []string{"ground", "hazmat", "cold"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			eligible := EligibleMethods(methods, []ShippableItem{tt.item})
+			if len(eligible) != len(tt.want) {
+				t.Fatalf("got %d methods, want %v", len(eligible), tt.want)
+			}
+			for i, m := range eligible {
+				if m.ID != tt.want[i] {
+					t.Errorf("method %d = %s, want %s", i, m.ID, tt.want[i])
+				}
+			}
+		})
+	}
+}
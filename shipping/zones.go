@@ -0,0 +1,172 @@
+package shipping
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"github.com/devchuckcamp/gocommerce/money"
+)
+
+var ErrDestinationNotServed = errors.New("shipping method does not serve destination")
+
+// Zone is a named group of destinations that share shipping prices
+// (e.g., "Domestic", "EU", "Rest of world").
+type Zone struct {
+	ID        string
+	Name      string
+	Countries []string // ISO country codes; empty matches every country
+	States    []string // Optional; restricts the zone to these states/regions of Countries
+}
+
+// ZoneRate is a method's rate table for one zone. Its rules work like the
+// matching ShippingMethod fields.
+type ZoneRate struct {
+	MethodID         string
+	ZoneID           string
+	FlatRate         *money.Money
+	RatePerWeightKg  *money.Money
+	FreeShippingMin  *money.Money
+	EstimatedDaysMin int
+	EstimatedDaysMax int
+}
+
+// Zone match levels, from least to most specific.
+const (
+	zoneNoMatch = iota
+	zoneMatchAny
+	zoneMatchCountry
+	zoneMatchState
+)
+
+// Matches reports whether addr falls inside the zone.
+func (z *Zone) Matches(addr Address) bool {
+	return z.matchLevel(addr) != zoneNoMatch
+}
+
+// matchLevel reports how specifically the zone matches addr, so a
+// state-level zone wins over a country-level one, which wins over a
+// catch-all.
+func (z *Zone) matchLevel(addr Address) int {
+	if len(z.Countries) == 0 {
+		return zoneMatchAny
+	}
+	if !containsFold(z.Countries, addr.Country) {
+		return zoneNoMatch
+	}
+	if len(z.States) == 0 {
+		return zoneMatchCountry
+	}
+	if containsFold(z.States, addr.State) {
+		return zoneMatchState
+	}
+	return zoneNoMatch
+}
+
+func containsFold(values []string, target string) bool {
+	for _, v := range values {
+		if strings.EqualFold(v, target) {
+			return true
+		}
+	}
+	return false
+}
+
+// ZoneRateCalculator is a RateCalculator that prices each method from the
+// rate table of the most specific zone it serves containing the
+// destination. Ties go to the zone listed first by the repository.
+type ZoneRateCalculator struct {
+	repo Repository
+}
+
+// NewZoneRateCalculator creates a calculator that reads methods, zones, and
+// zone rates from repo.
+func NewZoneRateCalculator(repo Repository) *ZoneRateCalculator {
+	return &ZoneRateCalculator{repo: repo}
+}
+
+// GetRate returns the rate for req.ShippingMethodID, or
+// ErrDestinationNotServed if the method has no zone covering the destination.
+func (c *ZoneRateCalculator) GetRate(ctx context.Context, req RateRequest) (*ShippingRate, error) {
+	method, err := c.repo.FindMethod(ctx, req.ShippingMethodID)
+	if err != nil {
+		return nil, err
+	}
+	if !method.IsActive {
+		return nil, ErrMethodUnavailable
+	}
+	if !method.CanShip(req.Items) {
+		return nil, ErrCannotShip
+	}
+
+	zones, err := c.repo.FindZones(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return c.rate(ctx, method, zones, req)
+}
+
+// GetAvailableRates returns a rate for every active method that can ship the
+// items and serves the destination, in the order the repository returns them.
+func (c *ZoneRateCalculator) GetAvailableRates(ctx context.Context, req RateRequest) ([]*ShippingRate, error) {
+	methods, err := c.repo.FindActiveMethods(ctx)
+	if err != nil {
+		return nil, err
+	}
+	zones, err := c.repo.FindZones(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	rates := make([]*ShippingRate, 0, len(methods))
+	for _, method := range EligibleMethods(methods, req.Items) {
+		rate, err := c.rate(ctx, method, zones, req)
+		if errors.Is(err, ErrDestinationNotServed) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		rates = append(rates, rate)
+	}
+	return rates, nil
+}
+
+// rate prices req for method using its best-matching zone rate.
+func (c *ZoneRateCalculator) rate(ctx context.Context, method *ShippingMethod, zones []*Zone, req RateRequest) (*ShippingRate, error) {
+	zoneRates, err := c.repo.FindZoneRates(ctx, method.ID)
+	if err != nil {
+		return nil, err
+	}
+	byZone := make(map[string]*ZoneRate, len(zoneRates))
+	for _, zoneRate := range zoneRates {
+		byZone[zoneRate.ZoneID] = zoneRate
+	}
+
+	var best *ZoneRate
+	bestLevel := zoneNoMatch
+	for _, zone := range zones {
+		zoneRate, ok := byZone[zone.ID]
+		if !ok {
+			continue
+		}
+		if level := zone.matchLevel(req.DestinationAddress); level > bestLevel {
+			best, bestLevel = zoneRate, level
+		}
+	}
+	if best == nil {
+		return nil, ErrDestinationNotServed
+	}
+
+	priced := *method
+	priced.FlatRate = best.FlatRate
+	priced.RatePerWeightKg = best.RatePerWeightKg
+	priced.FreeShippingMin = best.FreeShippingMin
+	rate, err := priced.Rate(req)
+	if err != nil {
+		return nil, err
+	}
+	rate.EstimatedDaysMin = best.EstimatedDaysMin
+	rate.EstimatedDaysMax = best.EstimatedDaysMax
+	return rate, nil
+}
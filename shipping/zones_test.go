@@ -0,0 +1,91 @@
+package shipping
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+// zoneRepo adds zones and zone rates to methodRepo.
+type zoneRepo struct {
+	methodRepo
+	zones     []*Zone
+	zoneRates []*ZoneRate
+}
+
+func (r *zoneRepo) FindZones(ctx context.Context) ([]*Zone, error) {
+	return r.zones, nil
+}
+
+func (r *zoneRepo) FindZoneRates(ctx context.Context, methodID string) ([]*ZoneRate, error) {
+	var result []*ZoneRate
+	for _, zr := range r.zoneRates {
+		if zr.MethodID == methodID {
+			result = append(result, zr)
+		}
+	}
+	return result, nil
+}
+
+func TestZoneRateCalculator(t *testing.T) {
+	ctx := context.Background()
+	repo := &zoneRepo{
+		methodRepo: methodRepo{methods: []*ShippingMethod{
+			{ID: "ground", IsActive: true},
+			{ID: "express", IsActive: true},
+		}},
+		zones: []*Zone{
+			{ID: "world"},
+			{ID: "us", Countries: []string{"US"}},
+			{ID: "remote", Countries: []string{"US"}, States: []string{"AK", "HI"}},
+		},
+		zoneRates: []*ZoneRate{
+			{MethodID: "ground", ZoneID: "world", FlatRate: usdPtr(2500), EstimatedDaysMin: 7, EstimatedDaysMax: 14},
+			{MethodID: "ground", ZoneID: "us", FlatRate: usdPtr(599), FreeShippingMin: usdPtr(5000), EstimatedDaysMin: 3, EstimatedDaysMax: 5},
+			{MethodID: "ground", ZoneID: "remote", FlatRate: usdPtr(1299)},
+			{MethodID: "express", ZoneID: "us", FlatRate: usdPtr(1500)},
+		},
+	}
+	c := NewZoneRateCalculator(repo)
+
+	tests := []struct {
+		name     string
+		dest     Address
+		value    int64
+		wantCost int64
+		wantDays int
+	}{
+		{"country zone", Address{Country: "US", State: "CA"}, 2000, 599, 3},
+		{"state zone wins", Address{Country: "us", State: "hi"}, 2000, 1299, 0},
+		{"catch-all", Address{Country: "FR"}, 2000, 2500, 7},
+		{"zone free shipping", Address{Country: "US", State: "CA"}, 5000, 0, 3},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rate, err := c.GetRate(ctx, RateRequest{
+				Items:              []ShippableItem{{SKU: "A", Quantity: 1, WeightGrams: 500}},
+				DestinationAddress: tt.dest,
+				ShippingMethodID:   "ground",
+				OrderValue:         usd(tt.value),
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if rate.Cost.Amount != tt.wantCost || rate.EstimatedDaysMin != tt.wantDays {
+				t.Errorf("rate = %s, %d days; want %d, %d days", rate.Cost, rate.EstimatedDaysMin, tt.wantCost, tt.wantDays)
+			}
+		})
+	}
+
+	req := RateRequest{DestinationAddress: Address{Country: "FR"}, ShippingMethodID: "express", OrderValue: usd(2000)}
+	if _, err := c.GetRate(ctx, req); !errors.Is(err, ErrDestinationNotServed) {
+		t.Errorf("express to FR: error = %v, want %v", err, ErrDestinationNotServed)
+	}
+	rates, err := c.GetAvailableRates(ctx, req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(rates) != 1 || rates[0].MethodID != "ground" {
+		t.Errorf("available rates to FR = %+v, want ground only", rates)
+	}
+}
@@ -0,0 +1,387 @@
+package tax
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/devchuckcamp/gocommerce/money"
+)
+
+// StandardCalculator implements Calculator using the rates stored in a
+// Repository. Rates apply in Priority order: a regular rate is charged on the
+// line amount, while a compound rate is charged on the line amount plus every
+// tax applied before it (e.g., Quebec QST on top of GST). Each item is taxed
+// with the rates for its tax code (see RateResolver).
+type StandardCalculator struct {
+	resolver      RateResolver
+	sellerCountry string          // Set by WithReverseCharge
+	vatCountries  map[string]bool // Countries where reverse charge applies
+}
+
+// CalculatorOption configures optional StandardCalculator behavior.
+type CalculatorOption func(*StandardCalculator)
+
+// WithRateResolver replaces the default RepositoryRateResolver.
+func WithRateResolver(resolver RateResolver) CalculatorOption {
+	return func(c *StandardCalculator) {
+		c.resolver = resolver
+	}
+}
+
+// NewStandardCalculator creates a calculator that reads rates from repo.
+func NewStandardCalculator(repo Repository, opts ...CalculatorOption) *StandardCalculator {
+	c := &StandardCalculator{resolver: NewRepositoryRateResolver(repo)}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
+}
+
+// Calculate taxes each taxable line with the rates for its tax code at the
+// address. Items that aren't taxable, or carry TaxCodeExempt, get no tax.
+// Shipping is apportioned across the items by amount and each share is taxed
+// like its item, so exempt items' share of shipping is untaxed too; with no
+// items, shipping gets the standard rates.
+//
+// Each rate's tax is computed once on the combined base of everything it
+// applies to and rounded once, then allocated across lines and shipping in
+// proportion to their bases. Per-line rounding never drifts from the total:
+// LineItemTaxes plus ShippingTax sum exactly to TotalTax, as do TaxRates.
+//
+// Cross-border B2B sales that qualify for reverse charge (see
+// WithReverseCharge) are zero-rated and flagged instead.
+//
+// With req.TaxInclusive, amounts already include tax (e.g., EU VAT): the
+// embedded tax, amount - amount/(1+rate), is backed out of each amount
+// instead of added, so an amount's net value plus its tax always equals the
+// amount.
+func (c *StandardCalculator) Calculate(ctx context.Context, req CalculationRequest) (*CalculationResult, error) {
+	currency := req.ShippingCost.Currency
+	if len(req.LineItems) > 0 {
+		currency = req.LineItems[0].Amount.Currency
+	}
+
+	if c.isReverseCharge(req) {
+		return reverseChargeResult(req, currency), nil
+	}
+
+	ratesByCode := make(map[string][]*TaxRate)
+	portion := func(amount money.Money, taxCode string) (*taxPortion, error) {
+		rates, ok := ratesByCode[taxCode]
+		if !ok {
+			var err error
+			if rates, err = c.sortedRates(ctx, taxCode, req.Address); err != nil {
+				return nil, err
+			}
+			ratesByCode[taxCode] = rates
+		}
+		return &taxPortion{amount: amount, rates: rates}, nil
+	}
+
+	var portions []*taxPortion
+	linePortions := make([]*taxPortion, len(req.LineItems))
+	for i, item := range req.LineItems {
+		if !isTaxable(item) {
+			continue
+		}
+		p, err := portion(item.Amount, item.TaxCode)
+		if err != nil {
+			return nil, err
+		}
+		linePortions[i] = p
+		portions = append(portions, p)
+	}
+
+	var shippingPortions []*taxPortion
+	if !req.ShippingCost.IsZero() {
+		if len(req.LineItems) == 0 {
+			p, err := portion(req.ShippingCost, "")
+			if err != nil {
+				return nil, err
+			}
+			shippingPortions = append(shippingPortions, p)
+		} else {
+			ratios := make([]int64, len(req.LineItems))
+			for i, item := range req.LineItems {
+				if item.Amount.Amount > 0 {
+					ratios[i] = item.Amount.Amount
+				}
+			}
+			for i, share := range req.ShippingCost.AllocateByRatios(ratios) {
+				item := req.LineItems[i]
+				if !isTaxable(item) || share.IsZero() {
+					continue
+				}
+				p, err := portion(share, item.TaxCode)
+				if err != nil {
+					return nil, err
+				}
+				shippingPortions = append(shippingPortions, p)
+			}
+		}
+		portions = append(portions, shippingPortions...)
+	}
+
+	if req.TaxInclusive {
+		for _, p := range portions {
+			p.applied, p.tax = backOutRates(p.amount, p.rates, req.RoundingMode)
+		}
+	} else if err := applyRates(portions, currency, req.RoundingMode); err != nil {
+		return nil, err
+	}
+
+	totals := &rateTotals{currency: currency}
+	totalTax := money.Zero(currency)
+	for _, p := range portions {
+		if err := totals.add(p.rates, p.applied); err != nil {
+			return nil, err
+		}
+		var err error
+		if totalTax, err = totalTax.Add(p.tax); err != nil {
+			return nil, err
+		}
+	}
+
+	lineItemTaxes := make([]LineItemTax, len(req.LineItems))
+	for i, item := range req.LineItems {
+		lineItemTaxes[i] = LineItemTax{
+			LineItemID: item.ID,
+			TaxAmount:  money.Zero(currency),
+		}
+		if p := linePortions[i]; p != nil {
+			lineItemTaxes[i].TaxAmount = p.tax
+			lineItemTaxes[i].TaxRates = p.applied
+		}
+	}
+
+	shippingTax := money.Zero(currency)
+	for _, p := range shippingPortions {
+		var err error
+		if shippingTax, err = shippingTax.Add(p.tax); err != nil {
+			return nil, err
+		}
+	}
+
+	return &CalculationResult{
+		TotalTax:      totalTax,
+		TaxRates:      totals.applied,
+		LineItemTaxes: lineItemTaxes,
+		ShippingTax:   shippingTax,
+	}, nil
+}
+
+// GetRatesForAddress returns the standard rates that apply to address in the
+// order they are applied.
+func (c *StandardCalculator) GetRatesForAddress(ctx context.Context, address Address) ([]TaxRate, error) {
+	rates, err := c.sortedRates(ctx, "", address)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]TaxRate, len(rates))
+	for i, rate := range rates {
+		result[i] = *rate
+	}
+	return result, nil
+}
+
+// sortedRates resolves the rates for taxCode at address ordered by Priority.
+// Rates with equal priority keep the resolver's order.
+func (c *StandardCalculator) sortedRates(ctx context.Context, taxCode string, address Address) ([]*TaxRate, error) {
+	found, err := c.resolver.ResolveRates(ctx, taxCode, address)
+	if err != nil {
+		return nil, err
+	}
+
+	rates := make([]*TaxRate, 0, len(found))
+	for _, rate := range found {
+		if rate != nil {
+			rates = append(rates, rate)
+		}
+	}
+	sort.SliceStable(rates, func(i, j int) bool {
+		return rates[i].Priority < rates[j].Priority
+	})
+	return rates, nil
+}
+
+func isTaxable(item TaxableItem) bool {
+	return item.IsTaxable && item.TaxCode != TaxCodeExempt
+}
+
+// rateTotals sums the amount charged per rate across items, listing rates
+// in the order they were first applied.
+type rateTotals struct {
+	currency string
+	applied  []AppliedTaxRate
+	index    map[string]int
+}
+
+func (t *rateTotals) add(rates []*TaxRate, applied []AppliedTaxRate) error {
+	if t.index == nil {
+		t.index = make(map[string]int)
+	}
+	for i, rate := range rates {
+		key := rateKey(rate)
+		j, ok := t.index[key]
+		if !ok {
+			j = len(t.applied)
+			t.index[key] = j
+			t.applied = append(t.applied, appliedRate(rate, money.Zero(t.currency)))
+		}
+		sum, err := t.applied[j].Amount.Add(applied[i].Amount)
+		if err != nil {
+			return err
+		}
+		t.applied[j].Amount = sum
+	}
+	return nil
+}
+
+// backOutRates splits the tax embedded in gross among rates. Working on one
+// unit of net price, each rate's share is its rate times its base (the net
+// price, plus earlier taxes for compound rates), so gross is net times one
+// plus the sum of shares. The embedded total is gross less the rounded net,
+// and it is divided among the rates by share so the parts sum exactly.
+func backOutRates(gross money.Money, rates []*TaxRate, mode money.RoundingMode) ([]AppliedTaxRate, money.Money) {
+	shares := make([]float64, len(rates))
+	combined := 0.0
+	for i, rate := range rates {
+		base := 1.0
+		if rate.IsCompound {
+			base += combined
+		}
+		shares[i] = base * rate.Rate
+		combined += shares[i]
+	}
+
+	applied := make([]AppliedTaxRate, len(rates))
+	if combined <= 0 {
+		for i, rate := range rates {
+			applied[i] = appliedRate(rate, money.Zero(gross.Currency))
+		}
+		return applied, money.Zero(gross.Currency)
+	}
+
+	net := gross.MultiplyWithRounding(1/(1+combined), mode)
+	total := money.Money{Amount: gross.Amount - net.Amount, Currency: gross.Currency}
+
+	ratios := make([]int64, len(shares))
+	for i, share := range shares {
+		ratios[i] = int64(share * 1e9)
+	}
+	for i, part := range total.AllocateByRatios(ratios) {
+		applied[i] = appliedRate(rates[i], part)
+	}
+	return applied, total
+}
+
+// taxPortion is an amount taxed with one set of rates: a line, or a line's
+// share of shipping.
+type taxPortion struct {
+	amount  money.Money
+	rates   []*TaxRate       // In the order they apply
+	applied []AppliedTaxRate // One per rate
+	tax     money.Money      // Sum of applied
+}
+
+// applyRates taxes portions on top of their amounts. Rates are applied in
+// Priority order; for each, the bases of every portion it covers (the
+// amount, plus taxes so far for a compound rate) are summed and taxed once,
+// and the rounded tax is allocated back by base.
+func applyRates(portions []*taxPortion, currency string, mode money.RoundingMode) error {
+	type member struct {
+		portion *taxPortion
+		index   int // Position of the rate in portion.rates
+	}
+	type rateGroup struct {
+		rate    *TaxRate
+		members []member
+	}
+
+	var groups []*rateGroup
+	byKey := make(map[string]*rateGroup)
+	for _, p := range portions {
+		p.applied = make([]AppliedTaxRate, len(p.rates))
+		p.tax = money.Zero(p.amount.Currency)
+		for i, rate := range p.rates {
+			key := rateKey(rate)
+			group, ok := byKey[key]
+			if !ok {
+				group = &rateGroup{rate: rate}
+				byKey[key] = group
+				groups = append(groups, group)
+			}
+			group.members = append(group.members, member{portion: p, index: i})
+		}
+	}
+	sort.SliceStable(groups, func(i, j int) bool {
+		return groups[i].rate.Priority < groups[j].rate.Priority
+	})
+
+	for _, group := range groups {
+		base := money.Zero(currency)
+		ratios := make([]int64, len(group.members))
+		for i, m := range group.members {
+			memberBase := m.portion.amount
+			if group.rate.IsCompound {
+				var err error
+				if memberBase, err = memberBase.Add(m.portion.tax); err != nil {
+					return err
+				}
+			}
+			if memberBase.Amount > 0 {
+				ratios[i] = memberBase.Amount
+			}
+			var err error
+			if base, err = base.Add(memberBase); err != nil {
+				return err
+			}
+		}
+
+		total := base.MultiplyWithRounding(group.rate.Rate, mode)
+		for i, part := range total.AllocateByRatios(ratios) {
+			m := group.members[i]
+			m.portion.applied[m.index] = appliedRate(m.portion.rates[m.index], part)
+			sum, err := m.portion.tax.Add(part)
+			if err != nil {
+				return err
+			}
+			m.portion.tax = sum
+		}
+	}
+	return nil
+}
+
+// rateKey identifies a rate across resolutions, by ID when it has one.
+func rateKey(rate *TaxRate) string {
+	if rate.ID != "" {
+		return rate.ID
+	}
+	return fmt.Sprintf("%s|%s|%g", rate.Name, jurisdiction(rate), rate.Rate)
+}
+
+func appliedRate(rate *TaxRate, amount money.Money) AppliedTaxRate {
+	return AppliedTaxRate{
+		Name:         rate.Name,
+		Rate:         rate.Rate,
+		Amount:       amount,
+		Jurisdiction: jurisdiction(rate),
+		TaxType:      rate.TaxType,
+	}
+}
+
+// jurisdiction names the most specific area a rate is scoped to.
+func jurisdiction(rate *TaxRate) string {
+	switch {
+	case rate.PostalCode != "":
+		return rate.PostalCode
+	case rate.City != "":
+		return rate.City
+	case rate.State != "":
+		return rate.State
+	default:
+		return rate.Country
+	}
+}
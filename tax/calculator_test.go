@@ -0,0 +1,213 @@
+package tax
+
+import (
+	"context"
+	"testing"
+
+	"github.com/devchuckcamp/gocommerce/money"
+)
+
+// rateRepo is a Repository returning the same rates for every address; the
+// resolver keeps those that apply.
+type rateRepo struct {
+	Repository
+	rates []*TaxRate
+}
+
+func (r rateRepo) FindRatesByAddress(ctx context.Context, address Address) ([]*TaxRate, error) {
+	return r.rates, nil
+}
+
+func (r rateRepo) FindRatesByPostalCode(ctx context.Context, country, postalCode string) ([]*TaxRate, error) {
+	return nil, nil
+}
+
+func usd(cents int64) money.Money {
+	return money.Money{Amount: cents, Currency: "USD"}
+}
+
+func TestCalculateCompoundRate(t *testing.T) {
+	c := NewStandardCalculator(rateRepo{rates: []*TaxRate{
+		{ID: "qst", Name: "QST", Rate: 0.09975, Country: "CA", State: "QC", TaxType: TaxTypeSales, IsCompound: true, Priority: 2},
+		{ID: "gst", Name: "GST", Rate: 0.05, Country: "CA", TaxType: TaxTypeGST, Priority: 1},
+	}})
+
+	result, err := c.Calculate(context.Background(), CalculationRequest{
+		LineItems: []TaxableItem{{ID: "line-1", Amount: usd(10000), Quantity: 1, IsTaxable: true}},
+		Address:   Address{Country: "CA", State: "QC"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// GST is 5% of $100; QST is 9.975% of $105.
+	if result.TotalTax.Amount != 1547 {
+		t.Errorf("TotalTax = %s, want USD 15.47", result.TotalTax)
+	}
+	want := []struct {
+		name   string
+		amount int64
+	}{{"GST", 500}, {"QST", 1047}}
+	if len(result.TaxRates) != len(want) {
+		t.Fatalf("applied %d rates, want %d", len(result.TaxRates), len(want))
+	}
+	for i, w := range want {
+		if got := result.TaxRates[i]; got.Name != w.name || got.Amount.Amount != w.amount {
+			t.Errorf("rate %d = %s %d, want %s %d", i, got.Name, got.Amount.Amount, w.name, w.amount)
+		}
+	}
+}
+
+func TestCalculateTaxInclusive(t *testing.T) {
+	c := NewStandardCalculator(rateRepo{rates: []*TaxRate{
+		{ID: "vat", Name: "VAT", Rate: 0.20, Country: "FR", TaxType: TaxTypeVAT},
+	}})
+
+	tests := []struct {
+		name      string
+		amount    int64
+		inclusive bool
+		wantTax   int64
+	}{
+		{"exclusive", 10000, false, 2000},
+		{"inclusive", 12000, true, 2000},
+		{"inclusive, uneven", 999, true, 167},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := c.Calculate(context.Background(), CalculationRequest{
+				LineItems:    []TaxableItem{{ID: "line-1", Amount: money.Money{Amount: tt.amount, Currency: "EUR"}, Quantity: 1, IsTaxable: true}},
+				Address:      Address{Country: "FR"},
+				TaxInclusive: tt.inclusive,
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if result.TotalTax.Amount != tt.wantTax || result.LineItemTaxes[0].TaxAmount.Amount != tt.wantTax {
+				t.Errorf("tax = %s (line %s), want %d", result.TotalTax, result.LineItemTaxes[0].TaxAmount, tt.wantTax)
+			}
+		})
+	}
+}
+
+func TestCalculateByTaxCodeWithShipping(t *testing.T) {
+	c := NewStandardCalculator(rateRepo{rates: []*TaxRate{
+		{ID: "std", Name: "VAT", Rate: 0.20, Country: "GB", TaxType: TaxTypeVAT},
+		{ID: "food", Name: "VAT (food)", Rate: 0.05, Country: "GB", TaxType: TaxTypeVAT, TaxCode: "food"},
+	}})
+
+	result, err := c.Calculate(context.Background(), CalculationRequest{
+		LineItems: []TaxableItem{
+			{ID: "mug", Amount: usd(10000), Quantity: 1, IsTaxable: true},
+			{ID: "tea", Amount: usd(10000), Quantity: 1, IsTaxable: true, TaxCode: "food"},
+			{ID: "card", Amount: usd(10000), Quantity: 1, IsTaxable: true, TaxCode: TaxCodeExempt},
+		},
+		ShippingCost: usd(1500),
+		Address:      Address{Country: "GB"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Each line carries $5 of shipping: the mug's is taxed at 20%, the
+	// tea's at 5%, and the card's not at all.
+	for i, want := range []int64{2000, 500, 0} {
+		if got := result.LineItemTaxes[i].TaxAmount.Amount; got != want {
+			t.Errorf("line %d tax = %d, want %d", i, got, want)
+		}
+	}
+	if result.ShippingTax.Amount != 125 {
+		t.Errorf("ShippingTax = %s, want USD 1.25", result.ShippingTax)
+	}
+	if result.TotalTax.Amount != 2625 {
+		t.Errorf("TotalTax = %s, want USD 26.25", result.TotalTax)
+	}
+}
+
+func TestCalculateRoundsOncePerRate(t *testing.T) {
+	c := NewStandardCalculator(rateRepo{rates: []*TaxRate{
+		{ID: "tx", Name: "Sales tax", Rate: 0.0825, Country: "US", State: "TX", TaxType: TaxTypeSales},
+	}})
+
+	// 8.25% of $12.05 is 99.4125 cents; rounding each line would charge
+	// 297 cents, but 8.25% of the $36.15 total is 298.2375.
+	result, err := c.Calculate(context.Background(), CalculationRequest{
+		LineItems: []TaxableItem{
+			{ID: "a", Amount: usd(1205), Quantity: 1, IsTaxable: true},
+			{ID: "b", Amount: usd(1205), Quantity: 1, IsTaxable: true},
+			{ID: "c", Amount: usd(1205), Quantity: 1, IsTaxable: true},
+		},
+		Address: Address{Country: "US", State: "TX"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.TotalTax.Amount != 298 || result.TaxRates[0].Amount.Amount != 298 {
+		t.Errorf("TotalTax = %s, rate total = %s; want USD 2.98", result.TotalTax, result.TaxRates[0].Amount)
+	}
+	var lines int64
+	for _, line := range result.LineItemTaxes {
+		if line.TaxAmount.Amount != 99 && line.TaxAmount.Amount != 100 {
+			t.Errorf("line %s tax = %s, want 99 or 100 cents", line.LineItemID, line.TaxAmount)
+		}
+		lines += line.TaxAmount.Amount
+	}
+	if lines != 298 {
+		t.Errorf("line taxes sum to %d, want 298", lines)
+	}
+}
+
+func TestCalculateMostSpecificRate(t *testing.T) {
+	c := NewStandardCalculator(rateRepo{rates: []*TaxRate{
+		{ID: "ca", Name: "CA sales tax", Rate: 0.0725, Country: "US", State: "CA", TaxType: TaxTypeSales},
+		{ID: "la", Name: "Los Angeles sales tax", Rate: 0.095, Country: "US", State: "CA", City: "Los Angeles", TaxType: TaxTypeSales},
+		{ID: "sf", Name: "San Francisco sales tax", Rate: 0.08625, Country: "US", State: "CA", PostalCode: "94100..94199", TaxType: TaxTypeSales},
+	}})
+
+	tests := []struct {
+		name     string
+		address  Address
+		wantRate string
+		wantTax  int64
+	}{
+		{"state", Address{Country: "US", State: "CA", City: "Fresno", PostalCode: "93701"}, "ca", 725},
+		{"city overrides state", Address{Country: "US", State: "CA", City: "Los Angeles", PostalCode: "90012"}, "la", 950},
+		{"postal range overrides state", Address{Country: "US", State: "CA", City: "San Francisco", PostalCode: "94102-1234"}, "sf", 862},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := c.Calculate(context.Background(), CalculationRequest{
+				LineItems: []TaxableItem{{ID: "line-1", Amount: usd(10000), Quantity: 1, IsTaxable: true}},
+				Address:   tt.address,
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(result.TaxRates) != 1 || result.TotalTax.Amount != tt.wantTax {
+				t.Errorf("rates %+v, tax %s; want only %s at %d", result.TaxRates, result.TotalTax, tt.wantRate, tt.wantTax)
+			}
+		})
+	}
+}
+
+func TestMatchPostalCode(t *testing.T) {
+	tests := []struct {
+		pattern, code string
+		want          bool
+	}{
+		{"94102", "94102", true},
+		{"94102", "94103", false},
+		{"941*", "94103", true},
+		{"941*", "95103", false},
+		{"sw1*", "SW1A 1AA", true},
+		{"94100..94199", "94102-1234", true},
+		{"94100..94199", "94200", false},
+		{"94100..94199", "941", false},
+		{"94100..9419", "94102", false},
+		{"94102", "", false},
+	}
+	for _, tt := range tests {
+		if got := MatchPostalCode(tt.pattern, tt.code); got != tt.want {
+			t.Errorf("MatchPostalCode(%q, %q) = %t, want %t", tt.pattern, tt.code, got, tt.want)
+		}
+	}
+}
@@ -2,6 +2,7 @@ package tax
 
 import (
 	"context"
+	"strings"
 
 	"github.com/devchuckcamp/gocommerce/money"
 )
@@ -17,24 +18,34 @@ type CalculationRequest struct {
 	LineItems    []TaxableItem
 	ShippingCost money.Money
 	Address      Address
-	TaxInclusive bool // Whether prices already include tax
+	TaxInclusive bool               // Whether prices already include tax
+	RoundingMode money.RoundingMode // How computed tax amounts are rounded to minor units
+	// B2B buyer details, used for VAT reverse charge (see WithReverseCharge)
+	IsBusiness        bool
+	CustomerVATNumber string // e.g., "DE123456789"
 }
 
 // TaxableItem represents an item subject to tax.
 type TaxableItem struct {
-	ID         string
-	Amount     money.Money
-	Quantity   int
-	TaxCode    string // Optional product tax code
-	IsTaxable  bool
+	ID        string
+	Amount    money.Money // Line amount (unit price x quantity)
+	Quantity  int
+	TaxCode   string // Optional product tax code
+	IsTaxable bool
 }
 
+// TaxCodeExempt marks a product as exempt from tax.
+const TaxCodeExempt = "exempt"
+
 // CalculationResult contains the tax calculation results.
 type CalculationResult struct {
-	TotalTax       money.Money
-	TaxRates       []AppliedTaxRate
-	LineItemTaxes  []LineItemTax
-	ShippingTax    money.Money
+	TotalTax      money.Money
+	TaxRates      []AppliedTaxRate
+	LineItemTaxes []LineItemTax
+	ShippingTax   money.Money
+	// ReverseCharge is set when no VAT was charged because the business
+	// buyer accounts for it; invoices should carry ReverseChargeNote.
+	ReverseCharge bool
 }
 
 // AppliedTaxRate represents a tax rate that was applied.
@@ -74,16 +85,25 @@ type Address struct {
 
 // TaxRate represents a tax rate configuration.
 type TaxRate struct {
-	ID           string
-	Name         string
-	Rate         float64
-	Country      string
-	State        string
-	City         string
-	PostalCode   string
-	TaxType      TaxType
-	IsCompound   bool // Compound tax calculated on subtotal + other taxes
-	Priority     int  // Order in which to apply (for compound taxes)
+	ID         string
+	Name       string
+	Rate       float64
+	Country    string
+	State      string
+	City       string
+	PostalCode string // Exact code, a prefix ending in "*" ("941*"), or an inclusive range ("94100..94199")
+	TaxType    TaxType
+	IsCompound bool // Compound tax calculated on subtotal + other taxes
+	Priority   int  // Order in which to apply (for compound taxes)
+	// TaxCode limits the rate to products with this tax code (e.g., "food"
+	// at a reduced or zero rate). Empty marks the standard rate.
+	TaxCode string
+}
+
+// sameJurisdiction reports whether two rates are scoped to the same area.
+func (tr *TaxRate) sameJurisdiction(other *TaxRate) bool {
+	return tr.Country == other.Country && tr.State == other.State &&
+		tr.City == other.City && tr.PostalCode == other.PostalCode
 }
 
 // AppliesTo checks if a tax rate applies to an address.
@@ -97,15 +117,179 @@ func (tr *TaxRate) AppliesTo(addr Address) bool {
 	if tr.City != "" && tr.City != addr.City {
 		return false
 	}
-	if tr.PostalCode != "" && tr.PostalCode != addr.PostalCode {
+	if tr.PostalCode != "" && !MatchPostalCode(tr.PostalCode, addr.PostalCode) {
 		return false
 	}
 	return true
 }
 
+// Rate specificity levels, from the broadest scope to the narrowest.
+const (
+	specificityGlobal = iota
+	specificityCountry
+	specificityState
+	specificityCity
+	specificityPostalPattern
+	specificityPostalCode
+)
+
+// specificity reports how narrowly the rate is scoped: a full postal code
+// beats a postal prefix or range, which beats a city, state, or country.
+func (tr *TaxRate) specificity() int {
+	switch {
+	case tr.PostalCode != "" && isPostalPattern(tr.PostalCode):
+		return specificityPostalPattern
+	case tr.PostalCode != "":
+		return specificityPostalCode
+	case tr.City != "":
+		return specificityCity
+	case tr.State != "":
+		return specificityState
+	case tr.Country != "":
+		return specificityCountry
+	default:
+		return specificityGlobal
+	}
+}
+
+// MatchPostalCode reports whether code matches pattern, which is an exact
+// postal code, a prefix ending in "*", or an inclusive range "LOW..HIGH".
+// Ranges compare the code's leading characters against bounds of equal
+// length, so "94100..94199" matches "94102" and "94102-1234". Matching
+// ignores case.
+func MatchPostalCode(pattern, code string) bool {
+	pattern = strings.ToUpper(strings.TrimSpace(pattern))
+	code = strings.ToUpper(strings.TrimSpace(code))
+	if code == "" {
+		return false
+	}
+
+	if prefix, ok := strings.CutSuffix(pattern, "*"); ok {
+		return strings.HasPrefix(code, prefix)
+	}
+	if low, high, ok := strings.Cut(pattern, ".."); ok {
+		if len(low) != len(high) || len(code) < len(low) {
+			return false
+		}
+		head := code[:len(low)]
+		return head >= low && head <= high
+	}
+	return pattern == code
+}
+
+func isPostalPattern(pattern string) bool {
+	return strings.HasSuffix(pattern, "*") || strings.Contains(pattern, "..")
+}
+
+// RateResolver picks the rates that apply to products with a tax code at an
+// address, so zero-rated and reduced-rate goods are taxed correctly.
+type RateResolver interface {
+	ResolveRates(ctx context.Context, taxCode string, address Address) ([]*TaxRate, error)
+}
+
+// RepositoryRateResolver resolves rates from a Repository. In each
+// jurisdiction, rates for the product's tax code replace the standard rates;
+// jurisdictions without code-specific rates keep their standard rates. So a
+// state can zero-rate "food" while a postal-code rate still applies.
+//
+// Among the remaining non-compound rates of each TaxType, only the most
+// specific apply (full postal code > postal prefix or range > city > state >
+// country), so a city sales tax overrides the state's for addresses in the
+// city. Compound rates always apply on top.
+type RepositoryRateResolver struct {
+	repo Repository
+}
+
+// NewRepositoryRateResolver creates a resolver that reads rates from repo.
+func NewRepositoryRateResolver(repo Repository) *RepositoryRateResolver {
+	return &RepositoryRateResolver{repo: repo}
+}
+
+// ResolveRates returns the rates for taxCode at address, in repository order.
+// Rates scoped to postal prefixes or ranges come from FindRatesByPostalCode.
+func (r *RepositoryRateResolver) ResolveRates(ctx context.Context, taxCode string, address Address) ([]*TaxRate, error) {
+	found, err := r.repo.FindRatesByAddress(ctx, address)
+	if err != nil {
+		return nil, err
+	}
+	if address.PostalCode != "" {
+		byPostalCode, err := r.repo.FindRatesByPostalCode(ctx, address.Country, address.PostalCode)
+		if err != nil {
+			return nil, err
+		}
+		seen := make(map[string]bool, len(found))
+		for _, rate := range found {
+			if rate != nil {
+				seen[rateKey(rate)] = true
+			}
+		}
+		for _, rate := range byPostalCode {
+			if rate != nil && !seen[rateKey(rate)] {
+				found = append(found, rate)
+			}
+		}
+	}
+
+	var coded []*TaxRate
+	if taxCode != "" {
+		for _, rate := range found {
+			if rate != nil && rate.TaxCode == taxCode && rate.AppliesTo(address) {
+				coded = append(coded, rate)
+			}
+		}
+	}
+
+	rates := make([]*TaxRate, 0, len(found))
+	for _, rate := range found {
+		if rate == nil || !rate.AppliesTo(address) {
+			continue
+		}
+		if rate.TaxCode == taxCode && taxCode != "" {
+			rates = append(rates, rate)
+			continue
+		}
+		if rate.TaxCode != "" {
+			continue
+		}
+		replaced := false
+		for _, c := range coded {
+			if c.sameJurisdiction(rate) {
+				replaced = true
+				break
+			}
+		}
+		if !replaced {
+			rates = append(rates, rate)
+		}
+	}
+	return mostSpecific(rates), nil
+}
+
+// mostSpecific drops each non-compound rate that a more specific rate of the
+// same TaxType overrides, keeping the original order.
+func mostSpecific(rates []*TaxRate) []*TaxRate {
+	best := make(map[TaxType]int)
+	for _, rate := range rates {
+		if level, ok := best[rate.TaxType]; !rate.IsCompound && (!ok || rate.specificity() > level) {
+			best[rate.TaxType] = rate.specificity()
+		}
+	}
+
+	kept := make([]*TaxRate, 0, len(rates))
+	for _, rate := range rates {
+		if rate.IsCompound || rate.specificity() == best[rate.TaxType] {
+			kept = append(kept, rate)
+		}
+	}
+	return kept
+}
+
 // Repository defines methods for tax data persistence.
 type Repository interface {
 	FindRatesByAddress(ctx context.Context, address Address) ([]*TaxRate, error)
+	// FindRatesByPostalCode returns the rates in country whose PostalCode
+	// matches postalCode, including prefix and range patterns.
+	FindRatesByPostalCode(ctx context.Context, country, postalCode string) ([]*TaxRate, error)
 	FindRateByID(ctx context.Context, id string) (*TaxRate, error)
 	SaveRate(ctx context.Context, rate *TaxRate) error
 	DeleteRate(ctx context.Context, id string) error
@@ -0,0 +1,122 @@
+package tax
+
+import (
+	"errors"
+	"regexp"
+	"strings"
+
+	"github.com/devchuckcamp/gocommerce/money"
+)
+
+var ErrInvalidVATNumber = errors.New("invalid VAT number")
+
+// ReverseChargeNote is the statement invoices must carry when VAT is
+// reverse charged.
+const ReverseChargeNote = "Reverse charge: VAT to be accounted for by the recipient (Article 196, Directive 2006/112/EC)"
+
+// EUCountries lists the ISO country codes of the EU member states.
+var EUCountries = []string{
+	"AT", "BE", "BG", "CY", "CZ", "DE", "DK", "EE", "ES", "FI", "FR", "GR", "HR", "HU",
+	"IE", "IT", "LT", "LU", "LV", "MT", "NL", "PL", "PT", "RO", "SE", "SI", "SK",
+}
+
+// vatNumberFormats holds the structure of each member state's VAT number
+// after its two-letter prefix. Greece uses the prefix EL.
+var vatNumberFormats = map[string]*regexp.Regexp{
+	"AT": regexp.MustCompile(`^U\d{8}$`),
+	"BE": regexp.MustCompile(`^[01]\d{9}$`),
+	"BG": regexp.MustCompile(`^\d{9,10}$`),
+	"CY": regexp.MustCompile(`^\d{8}[A-Z]$`),
+	"CZ": regexp.MustCompile(`^\d{8,10}$`),
+	"DE": regexp.MustCompile(`^\d{9}$`),
+	"DK": regexp.MustCompile(`^\d{8}$`),
+	"EE": regexp.MustCompile(`^\d{9}$`),
+	"EL": regexp.MustCompile(`^\d{9}$`),
+	"ES": regexp.MustCompile(`^[A-Z0-9]\d{7}[A-Z0-9]$`),
+	"FI": regexp.MustCompile(`^\d{8}$`),
+	"FR": regexp.MustCompile(`^[A-Z0-9]{2}\d{9}$`),
+	"HR": regexp.MustCompile(`^\d{11}$`),
+	"HU": regexp.MustCompile(`^\d{8}$`),
+	"IE": regexp.MustCompile(`^(\d{7}[A-W][A-I]?|\d[A-Z+*]\d{5}[A-W])$`),
+	"IT": regexp.MustCompile(`^\d{11}$`),
+	"LT": regexp.MustCompile(`^(\d{9}|\d{12})$`),
+	"LU": regexp.MustCompile(`^\d{8}$`),
+	"LV": regexp.MustCompile(`^\d{11}$`),
+	"MT": regexp.MustCompile(`^\d{8}$`),
+	"NL": regexp.MustCompile(`^\d{9}B\d{2}$`),
+	"PL": regexp.MustCompile(`^\d{10}$`),
+	"PT": regexp.MustCompile(`^\d{9}$`),
+	"RO": regexp.MustCompile(`^\d{2,10}$`),
+	"SE": regexp.MustCompile(`^\d{10}01$`),
+	"SI": regexp.MustCompile(`^\d{8}$`),
+	"SK": regexp.MustCompile(`^\d{10}$`),
+}
+
+// ValidateVATNumber checks that vatNumber is structurally valid for an EU
+// member state and returns its country code (GR for an EL prefix). Spaces,
+// dots, and dashes are ignored. It doesn't confirm the number is registered
+// (e.g., with VIES).
+func ValidateVATNumber(vatNumber string) (string, error) {
+	normalized := strings.ToUpper(strings.NewReplacer(" ", "", ".", "", "-", "").Replace(vatNumber))
+	if len(normalized) < 3 {
+		return "", ErrInvalidVATNumber
+	}
+
+	prefix, number := normalized[:2], normalized[2:]
+	format, ok := vatNumberFormats[prefix]
+	if !ok || !format.MatchString(number) {
+		return "", ErrInvalidVATNumber
+	}
+	if prefix == "EL" {
+		return "GR", nil
+	}
+	return prefix, nil
+}
+
+// WithReverseCharge enables VAT reverse charge for a seller in sellerCountry:
+// a business buyer with a structurally valid VAT number from another of
+// countries (e.g., EUCountries) pays no VAT. The VAT number must belong to
+// the destination country.
+func WithReverseCharge(sellerCountry string, countries []string) CalculatorOption {
+	return func(c *StandardCalculator) {
+		c.sellerCountry = strings.ToUpper(sellerCountry)
+		c.vatCountries = make(map[string]bool, len(countries))
+		for _, country := range countries {
+			c.vatCountries[strings.ToUpper(country)] = true
+		}
+	}
+}
+
+// isReverseCharge reports whether req is a cross-border B2B sale within the
+// configured countries. Malformed VAT numbers don't qualify, so the sale is
+// taxed normally.
+func (c *StandardCalculator) isReverseCharge(req CalculationRequest) bool {
+	if c.vatCountries == nil || !req.IsBusiness || req.CustomerVATNumber == "" {
+		return false
+	}
+
+	buyerCountry := strings.ToUpper(req.Address.Country)
+	if buyerCountry == c.sellerCountry || !c.vatCountries[buyerCountry] || !c.vatCountries[c.sellerCountry] {
+		return false
+	}
+
+	vatCountry, err := ValidateVATNumber(req.CustomerVATNumber)
+	return err == nil && vatCountry == buyerCountry
+}
+
+// reverseChargeResult is a zero-tax result flagged as reverse charged.
+func reverseChargeResult(req CalculationRequest, currency string) *CalculationResult {
+	lineItemTaxes := make([]LineItemTax, len(req.LineItems))
+	for i, item := range req.LineItems {
+		lineItemTaxes[i] = LineItemTax{
+			LineItemID: item.ID,
+			TaxAmount:  money.Zero(currency),
+		}
+	}
+	return &CalculationResult{
+		TotalTax:      money.Zero(currency),
+		LineItemTaxes: lineItemTaxes,
+		ShippingTax:   money.Zero(currency),
+		ReverseCharge: true,
+	}
+}
@@ -0,0 +1,70 @@
+package tax
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/devchuckcamp/gocommerce/money"
+)
+
+func TestValidateVATNumber(t *testing.T) {
+	valid := map[string]string{
+		"DE123456789":     "DE",
+		"fr 12 345678901": "FR",
+		"EL123456789":     "GR",
+		"NL123456789B01":  "NL",
+	}
+	for number, want := range valid {
+		if got, err := ValidateVATNumber(number); err != nil || got != want {
+			t.Errorf("ValidateVATNumber(%q) = %q, %v; want %q", number, got, err, want)
+		}
+	}
+	for _, number := range []string{"", "DE", "DE12345678", "GR123456789", "US123456789"} {
+		if _, err := ValidateVATNumber(number); !errors.Is(err, ErrInvalidVATNumber) {
+			t.Errorf("ValidateVATNumber(%q): error = %v, want %v", number, err, ErrInvalidVATNumber)
+		}
+	}
+}
+
+func TestCalculateReverseCharge(t *testing.T) {
+	c := NewStandardCalculator(rateRepo{rates: []*TaxRate{
+		{ID: "de", Name: "MwSt", Rate: 0.19, Country: "DE", TaxType: TaxTypeVAT},
+		{ID: "fr", Name: "TVA", Rate: 0.20, Country: "FR", TaxType: TaxTypeVAT},
+	}}, WithReverseCharge("DE", EUCountries))
+
+	tests := []struct {
+		name        string
+		country     string
+		business    bool
+		vatNumber   string
+		wantReverse bool
+		wantTax     int64
+	}{
+		{"cross-border B2B", "FR", true, "FR12345678901", true, 0},
+		{"domestic B2B", "DE", true, "DE123456789", false, 1900},
+		{"cross-border consumer", "FR", false, "", false, 2000},
+		{"VAT number from another country", "FR", true, "DE123456789", false, 2000},
+		{"malformed VAT number", "FR", true, "FR123", false, 2000},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := c.Calculate(context.Background(), CalculationRequest{
+				LineItems:         []TaxableItem{{ID: "line-1", Amount: money.Money{Amount: 10000, Currency: "EUR"}, Quantity: 1, IsTaxable: true}},
+				ShippingCost:      money.Money{Amount: 0, Currency: "EUR"},
+				Address:           Address{Country: tt.country},
+				IsBusiness:        tt.business,
+				CustomerVATNumber: tt.vatNumber,
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if result.ReverseCharge != tt.wantReverse || result.TotalTax.Amount != tt.wantTax {
+				t.Errorf("ReverseCharge = %t, tax %s; want %t, %d", result.ReverseCharge, result.TotalTax, tt.wantReverse, tt.wantTax)
+			}
+			if len(result.LineItemTaxes) != 1 {
+				t.Errorf("got %d line taxes, want 1", len(result.LineItemTaxes))
+			}
+		})
+	}
+}